@@ -0,0 +1,211 @@
+// Package arrow 以 Apache Arrow 记录批次读取 GORM 查询结果，独立为子模块以免根模块引入 Arrow 依赖
+package arrow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+)
+
+// ErrResultChecksUnsupported QueryArrow 按列直接写入 Arrow 数组，不解码结果结构体，
+// 无法执行 WithResultValidator、WithAfterAll 与 WithDedupBy
+var ErrResultChecksUnsupported = errors.New("arrow query does not support result validators, enrichers or dedup")
+
+// arrowBatchRows QueryArrow 每个记录批次的最大行数
+const arrowBatchRows = 1024
+
+// arrowColumn 将单列的扫描结果追加到对应的 Arrow 数组构建器
+type arrowColumn interface {
+	target() any
+	appendValue() error
+}
+
+// nullArrowColumn 以 sql.Null[T] 接收列值，NULL 追加为 Arrow 空值
+type nullArrowColumn[T any] struct {
+	value   sql.Null[T]
+	builder array.Builder
+	append  func(T) error
+}
+
+// target 返回传给 rows.Scan 的扫描目标
+func (c *nullArrowColumn[T]) target() any {
+	return &c.value
+}
+
+// appendValue 将本行扫描到的值追加到数组构建器
+func (c *nullArrowColumn[T]) appendValue() error {
+	if !c.value.Valid {
+		c.builder.AppendNull()
+		return nil
+	}
+	return c.append(c.value.V)
+}
+
+// newNullArrowColumn 创建直接追加值的列
+func newNullArrowColumn[T any](b array.Builder, appendFn func(T)) *nullArrowColumn[T] {
+	return &nullArrowColumn[T]{builder: b, append: func(v T) error {
+		appendFn(v)
+		return nil
+	}}
+}
+
+// newArrowColumn 按字段的 Arrow 类型选择扫描目标，支持布尔、整数、浮点、字符串、二进制、时间戳与日期类型
+func newArrowColumn(field arrow.Field, b array.Builder) (arrowColumn, error) {
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Int8Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Int16Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Int32Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Int64Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Uint8Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Uint16Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Uint32Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Uint64Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Float32Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Float64Builder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.StringBuilder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.LargeStringBuilder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.BinaryBuilder:
+		return newNullArrowColumn(b, b.Append), nil
+	case *array.Date32Builder:
+		return newNullArrowColumn(b, func(t time.Time) {
+			b.Append(arrow.Date32FromTime(t))
+		}), nil
+	case *array.TimestampBuilder:
+		unit := field.Type.(*arrow.TimestampType).Unit
+		return &nullArrowColumn[time.Time]{builder: b, append: func(t time.Time) error {
+			ts, err := arrow.TimestampFromTime(t, unit)
+			if err != nil {
+				return fmt.Errorf("arrow field %q: %w", field.Name, err)
+			}
+			b.Append(ts)
+			return nil
+		}}, nil
+	default:
+		return nil, fmt.Errorf("arrow field %q: unsupported type %s", field.Name, field.Type)
+	}
+}
+
+// QueryArrow 执行 GORM 查询并以 Apache Arrow 记录批次返回结果，逐行扫描后按列直接写入 Arrow 数组，
+// 省去先解码为结果结构体再转换为列式数据的开销，适合对接列式分析管道；查询由 List.BuildGormQuery 构建。
+// schema 字段按名称对应查询结果列，未通过 WithFields 指定投影时按 schema 的字段名投影；
+// 每个批次最多 1024 行，调用方使用完毕后需调用 Release；与 BuildGormQuery 相同，不经过中间件链与查询钩子，
+// 非 GORM 数据源返回 builder.ErrGormQueryUnsupported，配置结果校验、批量处理或去重时返回 ErrResultChecksUnsupported
+func QueryArrow[R any](
+	ctx context.Context,
+	l *builder.List[R],
+	schema *arrow.Schema,
+	opts ...builder.QueryOption,
+) iter.Seq2[arrow.RecordBatch, error] {
+	fail := func(err error) iter.Seq2[arrow.RecordBatch, error] {
+		return func(yield func(arrow.RecordBatch, error) bool) {
+			yield(nil, err)
+		}
+	}
+
+	options := builder.LoadQueryOptions(opts...)
+	if options.HasResultChecks() {
+		return fail(ErrResultChecksUnsupported)
+	}
+	if len(options.GetFields()) == 0 {
+		names := make([]string, schema.NumFields())
+		for i, field := range schema.Fields() {
+			names[i] = field.Name
+		}
+		opts = append(slices.Clip(opts), builder.WithFields(names...))
+	}
+	query, err := l.BuildGormQuery(ctx, opts...)
+	if err != nil {
+		return fail(err)
+	}
+
+	return func(yield func(arrow.RecordBatch, error) bool) {
+		rows, err := query.Rows()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+		if err := scanArrow(rows, schema, yield); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// scanArrow 逐行扫描 rows 并按列写入 Arrow 数组，每满 arrowBatchRows 行产出一个批次
+// yield 返回 false 时停止扫描并返回 nil
+func scanArrow(rows *sql.Rows, schema *arrow.Schema, yield func(arrow.RecordBatch, error) bool) error {
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+
+	// 不在 schema 中的结果列扫描后丢弃
+	targets := make([]any, len(names))
+	for i := range targets {
+		targets[i] = new(any)
+	}
+	columns := make([]arrowColumn, schema.NumFields())
+	for i, field := range schema.Fields() {
+		index := slices.Index(names, field.Name)
+		if index < 0 {
+			return fmt.Errorf("arrow field %q not found in query result columns", field.Name)
+		}
+		if columns[i], err = newArrowColumn(field, rb.Field(i)); err != nil {
+			return err
+		}
+		targets[index] = columns[i].target()
+	}
+
+	pending := 0
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		for _, column := range columns {
+			if err := column.appendValue(); err != nil {
+				return err
+			}
+		}
+		pending++
+		if pending == arrowBatchRows {
+			if !yield(rb.NewRecordBatch(), nil) {
+				return nil
+			}
+			pending = 0
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if pending > 0 {
+		yield(rb.NewRecordBatch(), nil)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package arrow
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// testEntity 测试用 GORM 结果结构体
+type testEntity struct {
+	ID   uint32 `gorm:"column:id"`
+	Name string `gorm:"column:name"`
+}
+
+// rowsConn 对任意查询返回固定结果集的 database/sql 连接，并记录收到的 SQL
+type rowsConn struct {
+	columns []string
+	values  [][]driver.Value
+	query   *string
+}
+
+func (c *rowsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *rowsConn) Close() error {
+	return nil
+}
+
+func (c *rowsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *rowsConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	*c.query = query
+	return &fixedRows{columns: c.columns, values: c.values}, nil
+}
+
+// fixedRows 逐行返回预设值的 driver.Rows
+type fixedRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fixedRows) Columns() []string { return r.columns }
+
+func (r *fixedRows) Close() error { return nil }
+
+func (r *fixedRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// rowsConnector 将 rowsConn 适配为 driver.Connector
+type rowsConnector struct {
+	conn *rowsConn
+}
+
+func (c *rowsConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c *rowsConnector) Driver() driver.Driver {
+	return c
+}
+
+func (c *rowsConnector) Open(string) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+// TestQueryArrow 测试按 schema 投影并逐列写入 Arrow 数组，NULL 写为空值，多余的结果列被忽略
+func TestQueryArrow(t *testing.T) {
+	var query string
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	conn := &rowsConn{
+		columns: []string{"id", "name", "extra", "created_at"},
+		values: [][]driver.Value{
+			{int64(1), "alice", "x", created},
+			{int64(2), nil, "y", nil},
+		},
+		query: &query,
+	}
+	sqlDB := sql.OpenDB(&rowsConnector{conn: conn})
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open gorm failed: %v", err)
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "created_at", Type: &arrow.TimestampType{Unit: arrow.Millisecond}, Nullable: true},
+	}, nil)
+	list := builder.NewList[testEntity]()
+	list.SetDataSource(builder.Gorm)
+	data := builder.WithData(builder.NewDBProxy(db, nil, nil))
+
+	var records int
+	for record, err := range QueryArrow(context.Background(), list, schema, data) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records++
+		if record.NumRows() != 2 {
+			t.Fatalf("expected 2 rows, got %d", record.NumRows())
+		}
+		ids := record.Column(0).(*array.Uint32)
+		names := record.Column(1).(*array.String)
+		times := record.Column(2).(*array.Timestamp)
+		if ids.Value(0) != 1 || ids.Value(1) != 2 {
+			t.Errorf("unexpected ids %v", ids)
+		}
+		if names.Value(0) != "alice" || !names.IsNull(1) {
+			t.Errorf("expected [alice null], got %v", names)
+		}
+		if times.Value(0) != arrow.Timestamp(created.UnixMilli()) || !times.IsNull(1) {
+			t.Errorf("unexpected timestamps %v", times)
+		}
+		record.Release()
+	}
+	if records != 1 {
+		t.Errorf("expected a single record batch, got %d", records)
+	}
+	if !strings.Contains(query, "SELECT `id`,`name`,created_at FROM") {
+		t.Errorf("expected projection from schema fields, got %s", query)
+	}
+
+	unsupported := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64)}}, nil)
+	for _, err := range QueryArrow(context.Background(), list, unsupported, data) {
+		if err == nil || !strings.Contains(err.Error(), "unsupported type") {
+			t.Errorf("expected unsupported type error, got %v", err)
+		}
+	}
+
+	missing := arrow.NewSchema([]arrow.Field{{Name: "email", Type: arrow.BinaryTypes.String}}, nil)
+	for _, err := range QueryArrow(context.Background(), list, missing, data, builder.WithFields("id")) {
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("expected missing column error, got %v", err)
+		}
+	}
+}
+
+// TestQueryArrow_Unsupported 测试非 GORM 数据源与配置结果校验时返回对应的哨兵错误
+func TestQueryArrow_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	schema := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Uint32}}, nil)
+
+	list := builder.NewList[testEntity]()
+	list.SetDataSource(builder.MongoDB)
+	for _, err := range QueryArrow(ctx, list, schema) {
+		if !errors.Is(err, builder.ErrGormQueryUnsupported) {
+			t.Errorf("expected ErrGormQueryUnsupported, got %v", err)
+		}
+	}
+
+	list = builder.NewList[testEntity]()
+	list.SetDataSource(builder.Gorm)
+	validator := builder.WithResultValidator(func(context.Context, *testEntity) error { return nil })
+	for _, err := range QueryArrow(ctx, list, schema, validator) {
+		if !errors.Is(err, ErrResultChecksUnsupported) {
+			t.Errorf("expected ErrResultChecksUnsupported, got %v", err)
+		}
+	}
+}
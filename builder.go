@@ -2,13 +2,17 @@ package builder
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"iter"
+	"sync"
 	"time"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"github.com/fantasticbin/QueryBuilder/v2/util"
 	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"gorm.io/gorm"
 )
@@ -32,10 +36,18 @@ var (
 	ErrDataSourceInvalid = errors.New("data source invalid")
 	// ErrLimitExceeded limit 超出允许的最大值
 	ErrLimitExceeded = errors.New("limit exceeds maximum allowed value (5000)")
+	// ErrStartOverflow 分页起始位置超出 uint32 范围，或疑似由无符号页码运算下溢（如 (page-1)*size 且 page 为 0）回绕得到
+	ErrStartOverflow = errors.New("start offset overflow")
 	// ErrCursorMismatch cursorValues 与 cursorFields 长度不匹配
 	ErrCursorMismatch = errors.New("cursorValues length does not match cursorFields length")
 	// ErrPITCursorWithoutPITID ElasticSearch 单批次分页查询模式下未提供 PIT ID 的错误
 	ErrPITCursorWithoutPITID = errors.New("PIT ID is required when cursor values are provided")
+	// ErrInvalidBatchSize MongoDB 游标批次大小非法（必须为正数）
+	ErrInvalidBatchSize = errors.New("batch size must be positive")
+	// ErrMixedProjection MongoDB 投影同时包含 SetFields 的包含字段与 SetProjectExclude 的排除字段（_id 除外）
+	ErrMixedProjection = errors.New("cannot mix inclusion and exclusion in mongo projection")
+	// ErrConnTagNotFound DBProxy.GormConns 中不存在指定标签的 GORM 连接
+	ErrConnTagNotFound = errors.New("gorm connection tag not found")
 )
 
 // DBProxy 数据实例结构
@@ -43,6 +55,10 @@ type DBProxy struct {
 	DB            *gorm.DB
 	Mongodb       *mongo.Collection // 需提前指定.Database("db_name").Collection("collection_name")
 	ElasticSearch *elastic.Client
+	// GormShards 水平分片的 GORM 实例（如按哈希拆分的 db0..dbN），配置后列表查询在各分片并行执行并合并结果
+	GormShards []*gorm.DB
+	// GormConns 按标签命名的 GORM 连接（如 "reporting"、"oltp"），查询时通过 SetConnTag 选择，未指定标签时使用 DB
+	GormConns map[string]*gorm.DB
 	// redis...
 }
 
@@ -55,6 +71,32 @@ func NewDBProxy(db *gorm.DB, mongodb *mongo.Collection, elasticsearch *elastic.C
 	}
 }
 
+// NewShardedDBProxy 创建水平分片的 GORM 数据实例
+// 首个分片同时作为 DB，用于 Explain 等仅需单库的场景
+func NewShardedDBProxy(shards ...*gorm.DB) *DBProxy {
+	p := &DBProxy{GormShards: shards}
+	if len(shards) > 0 {
+		p.DB = shards[0]
+	}
+	return p
+}
+
+// Conn 返回使用指定标签连接作为 DB 的数据实例副本，tag 为空时返回自身
+// 标签连接为单库连接，返回的副本不包含 GormShards；GormConns 中不存在该标签时返回 ErrConnTagNotFound
+func (p *DBProxy) Conn(tag string) (*DBProxy, error) {
+	if tag == "" {
+		return p, nil
+	}
+	db, ok := p.GormConns[tag]
+	if !ok || db == nil {
+		return nil, fmt.Errorf("%w: %q", ErrConnTagNotFound, tag)
+	}
+	conn := *p
+	conn.DB = db
+	conn.GormShards = nil
+	return &conn, nil
+}
+
 // CheckConfigured 检查指定数据源是否已正确配置
 func (p *DBProxy) CheckConfigured(ds DataSource) error {
 	switch ds {
@@ -77,6 +119,111 @@ func (p *DBProxy) CheckConfigured(ds DataSource) error {
 	return nil
 }
 
+// warmupConcurrency 连接池预热时同时建立连接的最大并发数
+const warmupConcurrency = 4
+
+// Warmup 预热连接池，提前建立 n 个连接，避免冷启动后的首批查询承担建连开销
+// 适用于 Serverless、弹性扩容等频繁冷启动的部署场景，对 GORM 底层 sql.DB 与 MongoDB 客户端同时生效：
+// GORM 以小并发度同时持有 n 个连接并逐个 Ping 后归还连接池（n 不超过 MaxOpenConns，
+// 空闲连接数受 SetMaxIdleConns 限制，需保证其不小于 n 才能全部保留）；
+// MongoDB 并发执行 n 次 ping 命令，由驱动按需扩充连接池（也可直接配置 minPoolSize）
+func (p *DBProxy) Warmup(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	var warmers []func() error
+	if p.DB != nil {
+		warmers = append(warmers, func() error {
+			return warmupSQL(ctx, p.DB, n)
+		})
+	}
+	if p.Mongodb != nil {
+		warmers = append(warmers, func() error {
+			return warmupMongo(ctx, p.Mongodb, n)
+		})
+	}
+	if len(warmers) == 0 {
+		return ErrDataNotConfigured
+	}
+	return util.WaitAndGo(warmers...)
+}
+
+// warmupSQL 同时持有 n 个连接以确保建立的是不同的物理连接，结束后统一归还连接池
+func warmupSQL(ctx context.Context, db *gorm.DB, n int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if maxOpen := sqlDB.Stats().MaxOpenConnections; maxOpen > 0 && n > maxOpen {
+		n = maxOpen
+	}
+
+	conns := make([]*sql.Conn, n)
+	defer func() {
+		for _, conn := range conns {
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}()
+	return util.WaitAndGoN(n, warmupConcurrency, func(i int) error {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		conns[i] = conn
+		return conn.PingContext(ctx)
+	})
+}
+
+// warmupMongo 并发执行 ping 命令，促使驱动建立连接
+func warmupMongo(ctx context.Context, coll *mongo.Collection, n int) error {
+	db := coll.Database()
+	return util.WaitAndGoN(n, 0, func(int) error {
+		return db.RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err()
+	})
+}
+
+// PoolStats 返回 GORM 底层 sql.DB 的连接池统计（使用中、空闲、等待次数等），用于诊断连接池耗尽
+// 默认并行执行的数据查询与总数统计会同时占用两个连接，可结合 InUse、WaitCount 观察连接池压力；
+// 配置 GormShards 时返回各分片连接池统计之和（分片共用同一 sql.DB 时只计一次）。
+// 未配置 GORM 时返回 ErrDataNotConfigured，底层连接池不是 sql.DB（如 DryRun）时返回 gorm.ErrInvalidDB
+func (p *DBProxy) PoolStats() (sql.DBStats, error) {
+	dbs := p.GormShards
+	if len(dbs) == 0 && p.DB != nil {
+		dbs = []*gorm.DB{p.DB}
+	}
+	if len(dbs) == 0 {
+		return sql.DBStats{}, ErrDataNotConfigured
+	}
+
+	var stats sql.DBStats
+	seen := make(map[*sql.DB]struct{}, len(dbs))
+	for _, db := range dbs {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return sql.DBStats{}, err
+		}
+		if _, ok := seen[sqlDB]; ok {
+			continue
+		}
+		seen[sqlDB] = struct{}{}
+
+		s := sqlDB.Stats()
+		stats.MaxOpenConnections += s.MaxOpenConnections
+		stats.OpenConnections += s.OpenConnections
+		stats.InUse += s.InUse
+		stats.Idle += s.Idle
+		stats.WaitCount += s.WaitCount
+		stats.WaitDuration += s.WaitDuration
+		stats.MaxIdleClosed += s.MaxIdleClosed
+		stats.MaxIdleTimeClosed += s.MaxIdleTimeClosed
+		stats.MaxLifetimeClosed += s.MaxLifetimeClosed
+	}
+	return stats, nil
+}
+
 // QueryMeta 查询元信息结构体（定义于 core 包，此处为类型别名）
 // 中间件可通过 builder.GetQueryMeta() 获取当前查询的元数据快照
 type QueryMeta = core.QueryMeta
@@ -126,6 +273,14 @@ type QuerierExplain interface {
 // QuerierMeta 查询元信息能力接口（定义于 core 包，此处为类型别名）
 type QuerierMeta = core.QuerierMeta
 
+// PageTokenSetter 支持不透明分页 token 的查询器接口
+// 以键值而非偏移分页的后端（如基于 DynamoDB LastEvaluatedKey 的自定义 Querier）实现该接口，
+// 接收上一页 ListResult / CursorPageResult 的 NextPageToken 经 WithPageToken 传回的 token，并在结果中填充新的 NextPageToken；
+// List 每次查询都会调用 SetPageToken（未传入时为 nil），避免沿用上一次查询的 token
+type PageTokenSetter interface {
+	SetPageToken(token []byte)
+}
+
 // Querier 通用查询接口，作为工厂函数的返回类型
 // 包含所有配置方法（Setter）和执行能力接口
 // 泛型参数:
@@ -165,12 +320,26 @@ type Querier[R any] interface {
 
 // queryConfig 分页配置
 type queryConfig struct {
-	start          uint32   // 分页起始位置
-	limit          uint32   // 每页数据条数
-	needTotal      bool     // 是否需要查询总数
-	totalLimit     uint32   // 总数统计上限，0 表示精确统计
-	needPagination bool     // 是否需要分页
-	fields         []string // 查询字段投影
+	start          uint32          // 分页起始位置
+	limit          uint32          // 每页数据条数
+	requestedLimit uint32          // 通过 SetLimit 请求的每页数据条数，不受条数上限收紧影响
+	needTotal      bool            // 是否需要查询总数
+	totalLimit     uint32          // 总数统计上限，0 表示精确统计
+	needPagination bool            // 是否需要分页
+	fields         []string        // 查询字段投影
+	skipData       bool            // 是否跳过数据查询，仅统计总数（仅 QueryList 生效）
+	resultCapacity int             // 结果切片预分配容量提示，0 表示按分页 limit 推断
+	queryName      string          // 逻辑查询名称，用于指标、日志、链路分组
+	countCtx       context.Context // 总数统计专用 ctx，为 nil 时与数据查询共用查询 ctx
+	resultPool     *sync.Pool      // 流式查询结果指针复用池，为 nil 表示不复用（Clone 后共享同一池）
+	peekNext       bool            // 列表查询是否多取一条以探测是否存在下一页
+	timeout        time.Duration   // 单次数据源访问的超时时间，0 表示不限制（仍受 ctx 自身截止时间约束）
+	countTimeout   time.Duration   // 总数统计分支的超时时间，超时后放弃总数而不是使查询失败，0 表示不限制
+	stableSortKey  string          // 偏移分页时追加为末位排序的唯一字段（通常为主键），为空表示不追加
+	parallelism    int             // 单次查询内并发访问数据源的最大数量，<= 0 表示不限制
+	emptyResult    bool            // 过滤条件必然不匹配任何记录，跳过数据查询与总数统计直接返回空结果
+	requireFilter  bool            // 查询必须带有过滤条件，未设置时返回 ErrMissingFilter
+	totalOverride  *int64          // 调用方已知的总数，设置后跳过总数统计直接使用该值，为 nil 表示不覆盖
 }
 
 // clone 返回 queryConfig 的深拷贝
@@ -190,6 +359,8 @@ type cursorConfig struct {
 	cursorValues       []any             // 游标初始值（外部传入，用于断点续查/App分页场景）
 	isCursorQuery      bool              // 是否为游标查询模式
 	isPITQuery         bool              // 是否为 Elasticsearch PIT + search_after 查询模式
+	cursorSigningKey   []byte            // 游标 token 签名密钥，配置后 QueryPage 返回签名 token
+	cursorToken        string            // 外部传入的签名游标 token，查询前解码为 cursorValues
 }
 
 // clone 返回 cursorConfig 的深拷贝
@@ -209,14 +380,25 @@ func (c cursorConfig) clone() cursorConfig {
 		copy(parsed, c.parsedCursorFields)
 		c.parsedCursorFields = parsed
 	}
+	if c.cursorSigningKey != nil {
+		key := make([]byte, len(c.cursorSigningKey))
+		copy(key, c.cursorSigningKey)
+		c.cursorSigningKey = key
+	}
 	return c
 }
 
 // hookChain 钩子与中间件链
 type hookChain[R any] struct {
-	beforeHook  BeforeQueryHook   // 查询前置钩子
-	afterHook   AfterQueryHook[R] // 查询后置钩子
-	middlewares []Middleware[R]   // 中间件链
+	beforeHook  BeforeQueryHook    // 查询前置钩子
+	afterHook   AfterQueryHook[R]  // 查询后置钩子
+	middlewares []Middleware[R]    // 中间件链
+	timingSink  *Timings           // 查询耗时累加器（Clone 后共享同一累加器）
+	dbCallHook  DBCallHook         // 数据库调用钩子
+	validator   ResultValidator[R] // 结果行校验函数
+	enricher    ResultEnricher[R]  // 结果集批量处理函数
+	dedupKey    DedupKey[R]        // 列表结果去重键函数
+	counter     Counter[R]         // 可替换的总数统计实现
 }
 
 // clone 返回 hookChain 的深拷贝
@@ -238,6 +420,11 @@ type builder[B queryBuilder[B, R], R any] struct {
 	data       *DBProxy
 	dataSource DataSource // 数据源类型，用于查询元信息
 	startTime  time.Time  // 查询开始时间
+	connTag    string     // 选用的 GORM 标签连接，为空时使用 DBProxy.DB
+	countData  *DBProxy   // 总数统计使用的数据实例，为 nil 时与数据查询共用 data
+
+	limiter  *PriorityLimiter // 按优先级调度的共享并发限制器，为 nil 表示不限制（Clone 后共享同一限制器）
+	priority QueryPriority    // 从 limiter 获取执行名额时使用的优先级
 
 	queryConfig  // 嵌入分页配置
 	cursorConfig // 嵌入游标配置
@@ -245,6 +432,10 @@ type builder[B queryBuilder[B, R], R any] struct {
 
 	selfRef    B          // 存储具体子类型引用，用于链式调用返回具体子类型
 	querierRef Querier[R] // 存储 Querier 接口引用，避免中间件执行时的类型断言
+
+	totalEstimated bool // 最近一次总数统计是否由 Counter 返回非精确值
+	totalTimedOut  bool // 最近一次总数统计是否因 countTimeout 超时被放弃
+	totalCapped    bool // 最近一次总数统计是否超出 totalLimit 而被截断
 }
 
 // setSelf 设置具体子类型引用，供子类型构造时调用
@@ -255,11 +446,20 @@ func (b *builder[B, R]) setSelf(self B, querier Querier[R]) {
 }
 
 // 以下方法实现 middlewareProvider[R] 接口，供 newMiddlewareContext 通过接口约束获取数据
-func (b *builder[B, R]) getMiddlewares() []Middleware[R] { return b.middlewares }
-func (b *builder[B, R]) getQuerierRef() Querier[R]       { return b.querierRef }
-func (b *builder[B, R]) getBeforeHook() BeforeQueryHook  { return b.beforeHook }
-func (b *builder[B, R]) getAfterHook() AfterQueryHook[R] { return b.afterHook }
-func (b *builder[B, R]) setStartTime(t time.Time)        { b.startTime = t }
+func (b *builder[B, R]) getMiddlewares() []Middleware[R]        { return b.middlewares }
+func (b *builder[B, R]) getQuerierRef() Querier[R]              { return b.querierRef }
+func (b *builder[B, R]) getBeforeHook() BeforeQueryHook         { return b.beforeHook }
+func (b *builder[B, R]) getAfterHook() AfterQueryHook[R]        { return b.afterHook }
+func (b *builder[B, R]) getCursorSigningKey() []byte            { return b.cursorSigningKey }
+func (b *builder[B, R]) getTimingSink() *Timings                { return b.timingSink }
+func (b *builder[B, R]) getTimeout() time.Duration              { return b.timeout }
+func (b *builder[B, R]) getLimiter() *PriorityLimiter           { return b.limiter }
+func (b *builder[B, R]) getPriority() QueryPriority             { return b.priority }
+func (b *builder[B, R]) getResultValidator() ResultValidator[R] { return b.validator }
+func (b *builder[B, R]) getResultEnricher() ResultEnricher[R]   { return b.enricher }
+func (b *builder[B, R]) getDedupKey() DedupKey[R]               { return b.dedupKey }
+func (b *builder[B, R]) isEmptyResult() bool                    { return b.emptyResult }
+func (b *builder[B, R]) setStartTime(t time.Time)               { b.startTime = t }
 
 // GetQueryMeta 返回当前查询元信息的只读快照
 // 中间件可通过 builder 参数直接调用此方法获取元数据
@@ -267,11 +467,13 @@ func (b *builder[B, R]) setStartTime(t time.Time)        { b.startTime = t }
 func (b *builder[B, R]) GetQueryMeta() QueryMeta {
 	meta := QueryMeta{
 		DataSource:     b.dataSource,
+		QueryName:      b.queryName,
 		Start:          b.start,
 		Limit:          b.limit,
 		NeedTotal:      b.needTotal,
 		TotalLimit:     b.totalLimit,
 		NeedPagination: b.needPagination,
+		SkipData:       b.skipData,
 		IsCursorQuery:  b.isCursorQuery,
 		IsPITQuery:     b.isPITQuery,
 		StartTime:      b.startTime,
@@ -298,10 +500,24 @@ func (b *builder[B, R]) prepareAndValidate() error {
 		return ErrDataNotConfigured
 	}
 
+	// 按标签切换连接
+	if b.connTag != "" {
+		data, err := b.data.Conn(b.connTag)
+		if err != nil {
+			return err
+		}
+		b.data = data
+	}
+
 	// 数据源校验
 	if err := b.data.CheckConfigured(b.dataSource); err != nil {
 		return err
 	}
+	if b.countData != nil {
+		if err := b.countData.CheckConfigured(b.dataSource); err != nil {
+			return err
+		}
+	}
 
 	// limit 校验
 	if b.limit > maxLimit {
@@ -314,6 +530,14 @@ func (b *builder[B, R]) prepareAndValidate() error {
 		if err := b.ensureDefaultCursorField(); err != nil {
 			return err
 		}
+		// 签名游标 token 优先于显式设置的 cursorValues
+		if b.cursorToken != "" {
+			values, err := DecodeCursor(b.cursorSigningKey, b.cursorToken)
+			if err != nil {
+				return err
+			}
+			b.cursorValues = values
+		}
 	}
 
 	// cursorValues 与 cursorFields 长度一致性校验
@@ -321,9 +545,38 @@ func (b *builder[B, R]) prepareAndValidate() error {
 		return ErrCursorMismatch
 	}
 
+	// 大表等场景要求必须带过滤条件，拒绝意外的全表扫描
+	if err := b.checkRequireFilter(); err != nil {
+		return err
+	}
+
+	// 专属构建器的数据源相关校验
+	if v, ok := any(b.selfRef).(backendValidator); ok {
+		return v.validateQuery()
+	}
 	return nil
 }
 
+// checkRequireFilter 开启 SetRequireFilter 时校验是否带有过滤条件，未带时以 DBCallRejected 回调 DBCallHook 并返回 ErrMissingFilter
+func (b *builder[B, R]) checkRequireFilter() error {
+	// 数据实例未配置时交由 prepareAndValidate 返回对应错误
+	if !b.requireFilter || b.data == nil || b.data.CheckConfigured(b.dataSource) != nil {
+		return nil
+	}
+	if f, ok := any(b.selfRef).(filterInspector); !ok || f.hasFilterCondition() {
+		return nil
+	}
+	if b.dbCallHook != nil {
+		b.dbCallHook(DBCallRejected, 0)
+	}
+	return ErrMissingFilter
+}
+
+// backendValidator 专属构建器的查询前校验，由 prepareAndValidate 在通用校验之后调用
+type backendValidator interface {
+	validateQuery() error
+}
+
 // getParsedCursorFields 返回解析后的游标字段缓存。
 // 若缓存为空且 cursorFields 已设置，则延迟解析一次并写回缓存。
 func (b *builder[B, R]) getParsedCursorFields() []cursorSortField {
@@ -370,6 +623,10 @@ func (b *builder[B, R]) sanitizeFields() {
 func (b *builder[B, R]) cloneBase(dst *builder[B, R]) {
 	dst.data = b.data
 	dst.dataSource = b.dataSource
+	dst.connTag = b.connTag
+	dst.countData = b.countData
+	dst.limiter = b.limiter
+	dst.priority = b.priority
 
 	// 通过子结构体的 clone() 方法进行深拷贝，确保切片引用独立
 	dst.queryConfig = b.queryConfig.clone()
@@ -393,15 +650,234 @@ func (b *builder[B, R]) SetStart(start uint32) B {
 // SetLimit 设置每页数据条数
 func (b *builder[B, R]) SetLimit(limit uint32) B {
 	b.limit = limit
+	b.requestedLimit = limit
 	return b.selfRef
 }
 
+// capLimit 将每页数据条数收紧到 limit，保留请求值用于 Pagination.RequestedLimit
+func (b *builder[B, R]) capLimit(limit uint32) {
+	b.limit = limit
+}
+
+// effectiveLimit 返回实际生效的每页数据条数，未指定时为 defaultLimit
+func (b *builder[B, R]) effectiveLimit() uint32 {
+	if b.limit == 0 {
+		return defaultLimit
+	}
+	return b.limit
+}
+
 // SetNeedTotal 设置是否需要查询总数
 func (b *builder[B, R]) SetNeedTotal(needTotal bool) B {
 	b.needTotal = needTotal
 	return b.selfRef
 }
 
+// SetTotalOverride 设置调用方已知的总数，列表查询不再统计总数而直接以 total 作为 ListResult.Total，且 HasTotal 为 true
+// 优先于 SetNeedTotal：即使未开启 needTotal 也返回该总数；适用于缓存中与分页数据一并保存了总数、无需重复统计的场景。
+// 总数统计被跳过，因此 Counter 与 countTimeout 不生效，TotalEstimated 为 false
+func (b *builder[B, R]) SetTotalOverride(total int64) B {
+	b.totalOverride = &total
+	return b.selfRef
+}
+
+// SetNeedData 设置是否需要查询数据（与 SetNeedTotal 对应）
+// 设置为 false 时 QueryList 跳过数据查询，仅按相同的过滤条件统计总数并返回空列表；游标查询模式不受影响
+func (b *builder[B, R]) SetNeedData(needData bool) B {
+	b.skipData = !needData
+	return b.selfRef
+}
+
+// SetQueryName 设置逻辑查询名称（如 "users.list"、"orders.search"）
+// 名称通过 QueryMeta.QueryName 暴露给中间件，可观测中间件会将其作为 querybuilder.query_name 属性输出，
+// 避免同一数据源的所有查询聚合到同一条指标序列；名称应保持低基数，不要包含用户输入
+func (b *builder[B, R]) SetQueryName(name string) B {
+	b.queryName = name
+	return b.selfRef
+}
+
+// SetCountContext 设置总数统计专用的 ctx，使数据查询与总数统计拥有独立的超时预算
+// 例如数据查询使用较短的请求超时，总数统计使用更宽松的超时；该 ctx 不继承查询 ctx 中的值，传入 nil 表示恢复共用查询 ctx
+func (b *builder[B, R]) SetCountContext(ctx context.Context) B {
+	b.countCtx = ctx
+	return b.selfRef
+}
+
+// countContext 返回总数统计使用的 ctx
+func (b *builder[B, R]) countContext(ctx context.Context) context.Context {
+	if b.countCtx != nil {
+		return b.countCtx
+	}
+	return ctx
+}
+
+// SetCountProxy 设置总数统计使用的数据实例，数据查询仍使用构建器的数据实例
+// 适用于 CQRS 等读写分离场景：总数统计改在只读副本或独立连接上执行，与主读路径解耦；
+// 统计沿用同一数据源类型与相同的过滤条件，Counter 收到的 exact 同样在该实例上执行；需要改用其他数据源类型
+// 或统计策略（如汇总表、搜索引擎）时通过 SetCounter 实现。GORM 一致性读、分片查询与绑定会话的 MongoDB 查询
+// （SetSession、一致性读或 operationTime 回写，会话不能跨客户端使用）不使用该实例，传入 nil 表示恢复共用数据实例
+func (b *builder[B, R]) SetCountProxy(data *DBProxy) B {
+	b.countData = data
+	return b.selfRef
+}
+
+// countProxy 返回总数统计使用的数据实例
+func (b *builder[B, R]) countProxy() *DBProxy {
+	if b.countData != nil {
+		return b.countData
+	}
+	return b.data
+}
+
+// SetTimeout 设置单次数据源访问的超时时间（游标查询按批次计算），0 表示不限制
+// 与 ctx 自身的截止时间取较早者：请求 ctx 先到期时以 ctx 为准，宽松的查询超时不会超出请求的截止时间
+func (b *builder[B, R]) SetTimeout(timeout time.Duration) B {
+	b.timeout = timeout
+	return b.selfRef
+}
+
+// SetPriority 设置共享的优先级并发限制器与本查询的优先级，limiter 为 nil 时不限制
+// 每次查询（游标查询按批次）在访问数据源前获取一个名额，名额已满时按优先级排队，
+// 使交互式查询优先于后台报表查询获得连接；排队等待受请求 ctx 约束，不计入 SetTimeout
+func (b *builder[B, R]) SetPriority(limiter *PriorityLimiter, priority QueryPriority) B {
+	b.limiter = limiter
+	b.priority = priority
+	return b.selfRef
+}
+
+// SetBranchTimeouts 分别设置数据查询与总数统计分支的超时预算，0 表示不单独限制
+// find 等同于 SetTimeout，总数统计与数据查询并行执行，同样受其约束；
+// count 仅作用于总数统计，超时后查询不失败，返回已查到的数据且 ListResult.HasTotal 为 false，
+// 使数据库压力下较慢的总数统计降级而不拖累列表返回。分片查询的各分片统计不受 count 约束
+func (b *builder[B, R]) SetBranchTimeouts(find, count time.Duration) B {
+	if find > 0 {
+		b.timeout = find
+	}
+	b.countTimeout = count
+	return b.selfRef
+}
+
+// SetStableSort 设置偏移分页查询的稳定排序字段（通常为主键）
+// 排序条件未包含该字段时会在末尾追加按其升序排序，避免主排序字段取值相同时翻页出现重复或遗漏；
+// 游标查询已以游标字段为主排序，不受影响；传入空字符串表示不追加
+func (b *builder[B, R]) SetStableSort(key string) B {
+	b.stableSortKey = key
+	return b.selfRef
+}
+
+// SetParallelism 设置单次查询内并发访问数据源的最大数量（数据查询、总数统计、各分片查询等），<= 0 表示不限制
+// 用于让扇出较多的查询（如分片查询）自我限流，与全局限流器互为补充；设置为 1 时各分支顺序执行
+func (b *builder[B, R]) SetParallelism(n int) B {
+	b.parallelism = n
+	return b.selfRef
+}
+
+// runParallel 以 parallelism 限制的并发度执行各查询分支，等待全部完成并返回首个错误
+func (b *builder[B, R]) runParallel(fns ...func() error) error {
+	return util.WaitAndGoN(len(fns), b.parallelism, func(i int) error {
+		return fns[i]()
+	})
+}
+
+// SetEmptyResult 标记过滤条件必然不匹配任何记录（如 in 运算符的集合为空），查询时跳过数据查询与总数统计，直接返回空结果
+// 钩子与中间件照常执行；通过 WithCondition 传入的条件由 List 自动判断（见 StructFilter.Unsatisfiable），无需手动设置
+func (b *builder[B, R]) SetEmptyResult(empty bool) B {
+	b.emptyResult = empty
+	return b.selfRef
+}
+
+// SetRequireFilter 设置查询是否必须带有过滤条件，开启后未设置任何过滤条件的查询直接返回 ErrMissingFilter 而不访问数据源
+// 用于数据量巨大的表，防止遗漏条件导致生产环境的全表扫描：GORM 检查过滤作用域是否产生 WHERE 条件（软删除条件不计入），
+// MongoDB 检查合并后的过滤文档是否为空，ElasticSearch 检查是否设置了非 match_all 的过滤查询。
+// 拒绝时以 DBCallRejected 回调 DBCallHook，便于记录日志与告警；经 List 查询时在追加强制过滤条件之前校验，
+// 租户隔离等强制条件不能代替调用方的过滤条件
+func (b *builder[B, R]) SetRequireFilter(require bool) B {
+	b.requireFilter = require
+	return b.selfRef
+}
+
+// SetResultPointerReuse 设置流式查询（QueryCursor）是否复用结果指针，以减少逐行分配带来的 GC 压力
+// 开启后每条记录在下一次 yield 前会被清零并放回 sync.Pool，调用方必须在迭代到下一条之前用完当前指针，
+// 不得保存或跨迭代引用；仅 MongoDB 与 ElasticSearch 的解码会从池中取用（GORM 由驱动内部分配），其他查询方式不受影响
+func (b *builder[B, R]) SetResultPointerReuse(reuse bool) B {
+	if !reuse {
+		b.resultPool = nil
+		return b.selfRef
+	}
+	if b.resultPool == nil {
+		b.resultPool = &sync.Pool{New: func() any { return new(R) }}
+	}
+	return b.selfRef
+}
+
+// newResultItem 返回用于解码单条记录的指针，开启指针复用时从池中获取
+func (b *builder[B, R]) newResultItem() *R {
+	if b.resultPool == nil {
+		return new(R)
+	}
+	return b.resultPool.Get().(*R)
+}
+
+// recycleResultItem 清零并回收已被调用方用完的结果指针，未开启指针复用时不做任何处理
+func (b *builder[B, R]) recycleResultItem(item *R) {
+	if b.resultPool == nil || item == nil {
+		return
+	}
+	var zero R
+	*item = zero
+	b.resultPool.Put(item)
+}
+
+// SetPeekNext 设置列表查询是否通过多取一条记录探测下一页
+// 开启后分页查询实际拉取 limit+1 条，多出的一条仅用于判断并从结果中裁掉，ListResult.HasMore 据此给出是否存在下一页；
+// 适用于无需总数的无限滚动场景，可与 SetNeedTotal(false) 搭配替代 Count；游标查询自带探测，不受影响
+func (b *builder[B, R]) SetPeekNext(peek bool) B {
+	b.peekNext = peek
+	return b.selfRef
+}
+
+// pageFetchLimit 返回列表查询实际拉取的条数，开启 peekNext 时为 limit+1
+func (b *builder[B, R]) pageFetchLimit() uint32 {
+	if b.peekNext {
+		return b.limit + 1
+	}
+	return b.limit
+}
+
+// trimPeek 裁掉 peekNext 多取的记录，返回裁剪后的结果与是否存在下一页
+func (b *builder[B, R]) trimPeek(list []*R) ([]*R, bool) {
+	if !b.peekNext || !b.needPagination || len(list) <= int(b.limit) {
+		return list, false
+	}
+	return list[:b.limit], true
+}
+
+// trimPeekValues 与 trimPeek 相同，作用于值切片
+func trimPeekValues[B queryBuilder[B, R], R any](b *builder[B, R], values []R) []R {
+	if !b.peekNext || !b.needPagination || len(values) <= int(b.limit) {
+		return values
+	}
+	return values[:b.limit]
+}
+
+// SetResultCapacity 设置结果切片的预分配容量提示
+// 开启分页时默认按 limit 预分配，无需设置；未开启分页但能预估结果规模时，设置该值可避免切片反复扩容
+func (b *builder[B, R]) SetResultCapacity(capacity int) B {
+	b.resultCapacity = max(capacity, 0)
+	return b.selfRef
+}
+
+// resultCapacityHint 返回结果切片的预分配容量：优先使用显式设置的容量，其次为分页 limit
+func (b *builder[B, R]) resultCapacityHint() int {
+	if b.resultCapacity > 0 {
+		return b.resultCapacity
+	}
+	if b.needPagination {
+		return int(b.limit)
+	}
+	return 0
+}
+
 // SetTotalLimit 设置总数统计上限，0 表示精确统计。
 func (b *builder[B, R]) SetTotalLimit(totalLimit uint32) B {
 	b.totalLimit = totalLimit
@@ -432,6 +908,124 @@ func (b *builder[B, R]) SetAfterQueryHook(hook AfterQueryHook[R]) B {
 	return b.selfRef
 }
 
+// SetTimingSink 设置查询耗时累加器
+// 每次访问数据源后追加一条 Timing 记录；未设置时回退到 ContextWithTimings 挂载在 ctx 上的累加器
+func (b *builder[B, R]) SetTimingSink(sink *Timings) B {
+	b.timingSink = sink
+	return b.selfRef
+}
+
+// SetDBCallHook 设置数据库调用钩子，仅包围实际的数据源访问（Find、Count 等），不含中间件、钩子与过滤条件构建，
+// 用于区分耗时来自数据库还是应用侧；GORM 的作用域函数在 Find/Count 内部执行，会计入调用耗时；
+// SetRequireFilter 拒绝的查询不访问数据源，以 DBCallRejected 与耗时 0 回调一次
+func (b *builder[B, R]) SetDBCallHook(hook DBCallHook) B {
+	b.dbCallHook = hook
+	return b.selfRef
+}
+
+// SetResultValidator 设置结果行校验函数，数据源返回后逐行校验，任一行校验失败时查询返回 ErrResultRejected
+// 游标与流式查询按批次校验，失败时迭代以错误结束
+func (b *builder[B, R]) SetResultValidator(validator ResultValidator[R]) B {
+	b.validator = validator
+	return b.selfRef
+}
+
+// SetResultEnricher 设置结果集批量处理函数，数据源返回后以整批结果调用一次，可原地修改各行，返回错误时查询失败
+// 游标与流式查询按批次调用，失败时迭代以错误结束
+func (b *builder[B, R]) SetResultEnricher(enricher ResultEnricher[R]) B {
+	b.enricher = enricher
+	return b.selfRef
+}
+
+// SetDedupBy 设置列表结果去重键函数，数据源返回后按键去除重复行（保留首次出现的行），
+// 适用于一对多 joins 未使用 DISTINCT 导致的重复行；去重在 ResultValidator 与 ResultEnricher 之前执行。
+// 去重仅作用于当前页，needTotal 时 Total 仍为去重前的行数；游标与流式查询不去重
+func (b *builder[B, R]) SetDedupBy(keyFn DedupKey[R]) B {
+	b.dedupKey = keyFn
+	return b.selfRef
+}
+
+// SetCounter 设置可替换的总数统计实现（如估算、缓存或封顶统计），替代数据源默认的精确统计
+// 分片查询仍在各分片上执行精确统计
+func (b *builder[B, R]) SetCounter(counter Counter[R]) B {
+	b.counter = counter
+	return b.selfRef
+}
+
+// setResultHook 按类型设置结果去重、校验、批量处理函数或总数统计实现，供 List 统一应用对应选项
+func (b *builder[B, R]) setResultHook(hook any) {
+	switch h := hook.(type) {
+	case DedupKey[R]:
+		b.dedupKey = h
+	case ResultValidator[R]:
+		b.validator = h
+	case ResultEnricher[R]:
+		b.enricher = h
+	case Counter[R]:
+		b.counter = h
+	}
+}
+
+// countWith 执行总数统计：配置 Counter 时交由其处理并记录是否为估算值，否则直接执行数据源默认的精确统计
+// 配置 countTimeout 时统计在独立的超时 ctx 中执行，仅因该超时失败时放弃总数并记录 totalTimedOut，不返回错误
+func (b *builder[B, R]) countWith(ctx context.Context, exact func(context.Context) (int64, error)) (int64, error) {
+	b.totalTimedOut = false
+	b.totalCapped = false
+	if b.totalOverride != nil {
+		b.totalEstimated = false
+		return *b.totalOverride, nil
+	}
+	if b.countTimeout <= 0 {
+		return b.count(ctx, exact)
+	}
+
+	countCtx, cancel := context.WithTimeout(ctx, b.countTimeout)
+	defer cancel()
+	total, err := b.count(countCtx, exact)
+	if err != nil && ctx.Err() == nil && errors.Is(countCtx.Err(), context.DeadlineExceeded) {
+		b.totalTimedOut = true
+		return 0, nil
+	}
+	return total, err
+}
+
+// count 按是否配置 Counter 选择总数统计方式，统计结果超出 totalLimit 时截断为上限
+func (b *builder[B, R]) count(ctx context.Context, exact func(context.Context) (int64, error)) (int64, error) {
+	if b.counter == nil {
+		total, err := exact(ctx)
+		return b.capTotal(total), err
+	}
+	total, isExact, err := b.counter.Count(ctx, b.querierRef, exact)
+	b.totalEstimated = err == nil && !isExact
+	return b.capTotal(total), err
+}
+
+// countProbeLimit 配置 totalLimit 时总数统计实际扫描的记录数上限
+// 多统计一条，用于区分总数恰好等于 totalLimit 与超出上限
+func (b *builder[B, R]) countProbeLimit() int64 {
+	return int64(b.totalLimit) + 1
+}
+
+// capTotal 将超出 totalLimit 的统计结果截断为上限，并记录 totalCapped
+func (b *builder[B, R]) capTotal(total int64) int64 {
+	b.totalCapped = b.totalLimit > 0 && total > int64(b.totalLimit)
+	if b.totalCapped {
+		return int64(b.totalLimit)
+	}
+	return total
+}
+
+// observeDBCall 开始一次数据库调用计时，返回的函数在调用结束时回调 DBCallHook
+func (b *builder[B, R]) observeDBCall(op string) func() {
+	if b.dbCallHook == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		b.dbCallHook(op, time.Since(begin))
+	}
+}
+
 // SetCursorField 设置游标分页排序字段（支持多字段）
 func (b *builder[B, R]) SetCursorField(fields ...string) B {
 	b.cursorFields = fields
@@ -447,6 +1041,20 @@ func (b *builder[B, R]) SetCursorValue(values ...any) B {
 	return b.selfRef
 }
 
+// SetCursorSigningKey 设置游标 token 的 HMAC 签名密钥
+// 配置后 QueryPage 会在 CursorPageResult.NextCursorToken 中返回签名后的不透明 token
+func (b *builder[B, R]) SetCursorSigningKey(key []byte) B {
+	b.cursorSigningKey = key
+	return b.selfRef
+}
+
+// SetCursorToken 设置签名游标 token（由上一页的 NextCursorToken 得到）
+// 查询前会使用 SetCursorSigningKey 配置的密钥校验签名并解码为游标值，校验失败返回 ErrCursorTokenInvalid
+func (b *builder[B, R]) SetCursorToken(token string) B {
+	b.cursorToken = token
+	return b.selfRef
+}
+
 // beginQueryMode 标记当前执行入口是否为游标查询。
 func (b *builder[B, R]) beginQueryMode(isCursorQuery bool) {
 	b.isCursorQuery = isCursorQuery
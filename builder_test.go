@@ -0,0 +1,160 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// countingDriver 记录建立的物理连接与 Ping 次数的 database/sql 驱动，用于连接池预热测试
+type countingDriver struct {
+	opened atomic.Int32
+	pinged atomic.Int32
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) {
+	d.opened.Add(1)
+	return &countingConn{driver: d}, nil
+}
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingConn) Ping(context.Context) error {
+	c.driver.pinged.Add(1)
+	return nil
+}
+
+// countingConnector 将 countingDriver 适配为 driver.Connector
+type countingConnector struct {
+	driver *countingDriver
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c *countingConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// TestDBProxy_Warmup 测试连接池预热建立不同的物理连接，并受 MaxOpenConns 限制
+func TestDBProxy_Warmup(t *testing.T) {
+	testCases := []struct {
+		name     string
+		maxOpen  int
+		n        int
+		expected int32
+	}{
+		{name: "建立 n 个连接", n: 5, expected: 5},
+		{name: "不超过 MaxOpenConns", maxOpen: 3, n: 5, expected: 3},
+		{name: "n 为 0 时不预热", n: 0, expected: 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			drv := &countingDriver{}
+			sqlDB := sql.OpenDB(&countingConnector{driver: drv})
+			defer func() {
+				_ = sqlDB.Close()
+			}()
+			sqlDB.SetMaxOpenConns(tt.maxOpen)
+			sqlDB.SetMaxIdleConns(10)
+
+			db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true})
+			if err != nil {
+				t.Fatalf("open gorm failed: %v", err)
+			}
+
+			if err := NewDBProxy(db, nil, nil).Warmup(context.Background(), tt.n); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := drv.opened.Load(); got != tt.expected {
+				t.Errorf("expected %d opened connections, got %d", tt.expected, got)
+			}
+			if got := drv.pinged.Load(); got != tt.expected {
+				t.Errorf("expected %d pings, got %d", tt.expected, got)
+			}
+			if idle := sqlDB.Stats().Idle; idle != int(tt.expected) {
+				t.Errorf("expected warmed connections to return to the pool, idle %d", idle)
+			}
+		})
+	}
+}
+
+// TestDBProxy_WarmupNotConfigured 测试未配置 GORM 与 MongoDB 时返回 ErrDataNotConfigured
+func TestDBProxy_WarmupNotConfigured(t *testing.T) {
+	if err := NewDBProxy(nil, nil, nil).Warmup(context.Background(), 1); !errors.Is(err, ErrDataNotConfigured) {
+		t.Errorf("expected ErrDataNotConfigured, got %v", err)
+	}
+}
+
+// TestDBProxy_PoolStats 测试连接池统计读取 GORM 底层 sql.DB，分片时累加且共享的 sql.DB 只计一次
+func TestDBProxy_PoolStats(t *testing.T) {
+	newDB := func(maxOpen int) (*gorm.DB, *sql.DB) {
+		sqlDB := sql.OpenDB(&countingConnector{driver: &countingDriver{}})
+		t.Cleanup(func() {
+			_ = sqlDB.Close()
+		})
+		sqlDB.SetMaxOpenConns(maxOpen)
+		sqlDB.SetMaxIdleConns(maxOpen)
+		db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true})
+		if err != nil {
+			t.Fatalf("open gorm failed: %v", err)
+		}
+		return db, sqlDB
+	}
+
+	db0, sqlDB0 := newDB(4)
+	conn, err := sqlDB0.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	stats, err := NewDBProxy(db0, nil, nil).PoolStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.MaxOpenConnections != 4 || stats.InUse != 1 || stats.OpenConnections != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	db1, _ := newDB(6)
+	stats, err = NewShardedDBProxy(db0, db1, db0).PoolStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.MaxOpenConnections != 10 || stats.InUse != 1 {
+		t.Errorf("unexpected sharded stats: %+v", stats)
+	}
+
+	if _, err := NewDBProxy(nil, nil, nil).PoolStats(); !errors.Is(err, ErrDataNotConfigured) {
+		t.Errorf("expected ErrDataNotConfigured, got %v", err)
+	}
+	dryRun, _ := newDryRunGormProxy(t)
+	if _, err := dryRun.PoolStats(); !errors.Is(err, gorm.ErrInvalidDB) {
+		t.Errorf("expected gorm.ErrInvalidDB, got %v", err)
+	}
+}
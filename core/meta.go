@@ -13,16 +13,19 @@ type QuerierMeta interface {
 // 中间件可通过 builder.GetQueryMeta() 获取当前查询的元数据快照
 type QueryMeta struct {
 	DataSource     DataSource // 数据源类型
+	QueryName      string     // 逻辑查询名称（如 users.list），用于指标、日志分组
 	Start          uint32     // 分页起始位置
 	Limit          uint32     // 每页数据条数
 	NeedTotal      bool       // 是否需要查询总数
 	TotalLimit     uint32     // 总数统计上限，0 表示精确统计
 	NeedPagination bool       // 是否需要分页
+	SkipData       bool       // 是否跳过数据查询，仅统计总数
 	Fields         []string   // 查询字段投影
 	IsCursorQuery  bool       // 是否为游标查询模式
 	IsPITQuery     bool       // 是否为 Elasticsearch PIT + search_after 查询模式
 	CursorFields   []string   // 游标分页排序字段列表
 	CursorValues   []any      // 游标初始值（外部传入，用于断点续查/App分页场景）
+	PageToken      []byte     // 外部传入的不透明分页 token（由 WithPageToken 传入，CachedQuerier 据此区分各页缓存键）
 	StartTime      time.Time  // 查询开始时间
 }
 
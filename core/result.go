@@ -39,8 +39,26 @@ func (k ResultKind) String() string {
 //
 //	R: 查询结果的实体类型
 type ListResult[R any] struct {
-	Items []*R  // 当前页的数据列表
-	Total int64 // 总数（仅在 needTotal=true 时有效）
+	Items          []*R        // 当前页的数据列表
+	Total          int64       // 总数（仅在 needTotal=true 时有效）
+	HasTotal       bool        // 是否统计了总数，用于区分"未统计总数"与"总数为 0"
+	TotalCapped    bool        // 总数是否超出 totalLimit 上限并被截断，为 true 时 Total 应理解为"上限+"而非精确总数
+	TotalEstimated bool        // 总数是否由自定义 Counter 估算（非精确统计）得到
+	HasMore        bool        // 是否存在下一页（仅开启 peekNext 探测时有效）
+	Pagination     *Pagination // 本次查询的分页信息，未分页时为 nil
+	NextPageToken  []byte      // 键值分页后端的不透明下一页 token（如 DynamoDB 的 LastEvaluatedKey），由自定义 Querier 填充，最后一页为 nil
+}
+
+// Pagination 列表查询实际生效的分页信息
+type Pagination struct {
+	Start          uint32 // 分页起始位置
+	Limit          uint32 // 实际生效的每页数据条数（未指定时为默认值，可能被条数上限收紧）
+	RequestedLimit uint32 // 请求的每页数据条数，0 表示未指定
+}
+
+// Adjusted 报告实际生效的每页条数是否与请求值不同，分页界面可据此以实际窗口展示
+func (p *Pagination) Adjusted() bool {
+	return p.Limit != p.RequestedLimit
 }
 
 // GetResultKind 返回结果类型
@@ -64,9 +82,12 @@ func (r *ListResult[R]) GetTotal() int64 {
 	return r.Total
 }
 
-// GetHasMore 列表查询结果不支持 HasMore，始终返回 false
+// GetHasMore 返回是否存在下一页，未开启 peekNext 探测时始终为 false
 func (r *ListResult[R]) GetHasMore() bool {
-	return false
+	if r == nil {
+		return false
+	}
+	return r.HasMore
 }
 
 // GetNextCursorValues 列表查询结果不支持游标值，始终返回 nil
@@ -80,10 +101,12 @@ func (r *ListResult[R]) GetNextCursorValues() []any {
 //
 //	R: 查询结果的实体类型
 type CursorPageResult[R any] struct {
-	Items            []*R  // 当前页的数据列表
-	Total            int64 // 总数（仅在 needTotal=true 时有效）
-	HasMore          bool  // 是否还有下一页数据
-	NextCursorValues []any // 下一页的游标值（用于传入下次查询的 SetCursorValue），HasMore=false 时为 nil
+	Items            []*R   // 当前页的数据列表
+	Total            int64  // 总数（仅在 needTotal=true 时有效）
+	HasMore          bool   // 是否还有下一页数据
+	NextCursorValues []any  // 下一页的游标值（用于传入下次查询的 SetCursorValue），HasMore=false 时为 nil
+	NextCursorToken  string // 签名后的下一页游标 token（配置签名密钥时有效），HasMore=false 时为空
+	NextPageToken    []byte // 键值分页后端的不透明下一页 token（如 DynamoDB 的 LastEvaluatedKey），由自定义 Querier 填充，HasMore=false 时为 nil
 }
 
 // GetResultKind 返回结果类型
@@ -189,6 +189,8 @@ func executeBuilderCursorQueryWithCleanup[B queryBuilder[B, R], R any](
 			if !yield(item, err) {
 				return
 			}
+			// 调用方已处理完当前记录，开启指针复用时回收
+			b.recycleResultItem(item)
 		}
 	}
 }
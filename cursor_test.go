@@ -1386,3 +1386,40 @@ func TestListQueryPage_PanicRecovery(t *testing.T) {
 		t.Errorf("expected nil result, got %v", result)
 	}
 }
+
+// TestExecuteBuilderCursorQuery_ResultPointerReuse 测试开启指针复用后，已 yield 的记录在下一条前被清零回收
+func TestExecuteBuilderCursorQuery_ResultPointerReuse(t *testing.T) {
+	for _, reuse := range []bool{true, false} {
+		proxy, _ := newDryRunGormProxy(t)
+		b := NewGormBuilder[GormTestEntity](proxy)
+		b.SetResultPointerReuse(reuse)
+		b.SetLimit(2)
+
+		fetch := func(ctx context.Context, cursorValues []any, isFirstBatch bool) ([]*GormTestEntity, []any, int64, bool, error) {
+			if !isFirstBatch {
+				return nil, nil, 0, false, nil
+			}
+			batch := make([]*GormTestEntity, 0, 2)
+			for id := uint32(1); id <= 2; id++ {
+				item := b.builder.newResultItem()
+				item.ID = id
+				batch = append(batch, item)
+			}
+			return batch, []any{uint32(2)}, 0, false, nil
+		}
+
+		var previous *GormTestEntity
+		for item, err := range executeBuilderCursorQuery(context.Background(), &b.builder, fetch) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if previous != nil && (previous.ID == 0) != reuse {
+				t.Errorf("reuse=%v: unexpected previous item state %+v", reuse, previous)
+			}
+			previous = item
+		}
+		if previous == nil {
+			t.Fatal("expected items")
+		}
+	}
+}
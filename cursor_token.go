@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// cursorTokenVersion 游标 token 载荷版本号，用于后续格式演进时的兼容判断
+const cursorTokenVersion = 2
+
+// 游标值类型标签，用于还原 JSON 无法原样表达的类型
+const (
+	cursorValueObjectID = "oid"
+	cursorValueTime     = "time"
+)
+
+var (
+	// ErrCursorSigningKeyMissing 未配置游标 token 签名密钥
+	ErrCursorSigningKeyMissing = errors.New("cursor signing key is required")
+	// ErrCursorTokenInvalid 游标 token 格式非法或签名校验失败
+	ErrCursorTokenInvalid = errors.New("cursor token invalid")
+)
+
+// cursorTokenPayload 游标 token 载荷结构
+type cursorTokenPayload struct {
+	Version int                `json:"v"`
+	Values  []cursorTokenValue `json:"c"`
+}
+
+// cursorTokenValue 带类型标签的游标值，Type 为空时 Value 按 JSON 原始形态编码
+type cursorTokenValue struct {
+	Type  string `json:"t,omitempty"`
+	Value any    `json:"x"`
+}
+
+// EncodeCursor 将游标值编码为带 HMAC-SHA256 签名的不透明 token
+// token 格式为 base64url(payload) + "." + base64url(signature)，客户端无法篡改游标位置
+// 参数:
+//
+//	key: 签名密钥，不能为空
+//	values: 游标值（通常为 CursorPageResult.NextCursorValues）
+func EncodeCursor(key []byte, values []any) (string, error) {
+	if len(key) == 0 {
+		return "", ErrCursorSigningKeyMissing
+	}
+
+	tagged := make([]cursorTokenValue, len(values))
+	for i, v := range values {
+		tagged[i] = tagCursorTokenValue(v)
+	}
+	payload, err := json.Marshal(cursorTokenPayload{Version: cursorTokenVersion, Values: tagged})
+	if err != nil {
+		return "", fmt.Errorf("cursor token marshal failed: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(signCursorPayload(key, payload)), nil
+}
+
+// DecodeCursor 校验游标 token 签名并解码出游标值
+// bson.ObjectID 与 time.Time 按类型标签原样还原，数值会被还原为 int64（整数）或 float64（小数），
+// 其余类型保持 JSON 解码后的原始形态
+// 参数:
+//
+//	key: 签名密钥，需与 EncodeCursor 使用的密钥一致
+//	token: EncodeCursor 生成的 token
+func DecodeCursor(key []byte, token string) ([]any, error) {
+	if len(key) == 0 {
+		return nil, ErrCursorSigningKeyMissing
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrCursorTokenInvalid
+	}
+
+	encoding := base64.RawURLEncoding
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrCursorTokenInvalid
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrCursorTokenInvalid
+	}
+	if !hmac.Equal(signature, signCursorPayload(key, payload)) {
+		return nil, ErrCursorTokenInvalid
+	}
+
+	var decoded cursorTokenPayload
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, ErrCursorTokenInvalid
+	}
+	if decoded.Version != cursorTokenVersion {
+		return nil, ErrCursorTokenInvalid
+	}
+
+	values := make([]any, len(decoded.Values))
+	for i, v := range decoded.Values {
+		value, err := untagCursorTokenValue(v)
+		if err != nil {
+			return nil, ErrCursorTokenInvalid
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// signCursorPayload 计算载荷的 HMAC-SHA256 签名
+func signCursorPayload(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// tagCursorTokenValue 为 JSON 往返会丢失类型的游标值附加类型标签
+func tagCursorTokenValue(v any) cursorTokenValue {
+	switch value := v.(type) {
+	case bson.ObjectID:
+		return cursorTokenValue{Type: cursorValueObjectID, Value: value.Hex()}
+	case time.Time:
+		return cursorTokenValue{Type: cursorValueTime, Value: value.Format(time.RFC3339Nano)}
+	default:
+		return cursorTokenValue{Value: v}
+	}
+}
+
+// untagCursorTokenValue 按类型标签还原游标值
+func untagCursorTokenValue(v cursorTokenValue) (any, error) {
+	if v.Type == "" {
+		return normalizeCursorTokenValue(v.Value), nil
+	}
+
+	s, ok := v.Value.(string)
+	if !ok {
+		return nil, fmt.Errorf("cursor value of type %q must be a string", v.Type)
+	}
+	switch v.Type {
+	case cursorValueObjectID:
+		return bson.ObjectIDFromHex(s)
+	case cursorValueTime:
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		return nil, fmt.Errorf("unknown cursor value type %q", v.Type)
+	}
+}
+
+// normalizeCursorTokenValue 将 json.Number 还原为 int64 或 float64，便于直接作为查询参数使用
+func normalizeCursorTokenValue(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
@@ -0,0 +1,183 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gorm.io/gorm"
+)
+
+var testCursorSigningKey = []byte("cursor-test-secret")
+
+// TestCursorToken_RoundTrip 测试游标 token 编解码往返及数值还原
+func TestCursorToken_RoundTrip(t *testing.T) {
+	token, err := EncodeCursor(testCursorSigningKey, []any{int64(1700000000), "alice", 1.5, uint32(42)})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	values, err := DecodeCursor(testCursorSigningKey, token)
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if len(values) != 4 {
+		t.Fatalf("expected 4 values, got %d", len(values))
+	}
+	if values[0] != int64(1700000000) || values[1] != "alice" || values[2] != 1.5 || values[3] != int64(42) {
+		t.Errorf("unexpected decoded values: %#v", values)
+	}
+}
+
+// TestCursorToken_TypedValues 测试 ObjectID 与时间游标值往返后保持原始类型
+func TestCursorToken_TypedValues(t *testing.T) {
+	id := bson.NewObjectID()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.FixedZone("UTC+8", 8*3600))
+	token, err := EncodeCursor(testCursorSigningKey, []any{created, id, "tail"})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	values, err := DecodeCursor(testCursorSigningKey, token)
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	decodedTime, ok := values[0].(time.Time)
+	if !ok || !decodedTime.Equal(created) {
+		t.Errorf("expected time.Time %v, got %#v", created, values[0])
+	}
+	if decodedID, ok := values[1].(bson.ObjectID); !ok || decodedID != id {
+		t.Errorf("expected bson.ObjectID %v, got %#v", id, values[1])
+	}
+	if values[2] != "tail" {
+		t.Errorf("expected plain string, got %#v", values[2])
+	}
+}
+
+// TestCursorToken_Tampered 测试篡改载荷或签名后校验失败
+func TestCursorToken_Tampered(t *testing.T) {
+	token, err := EncodeCursor(testCursorSigningKey, []any{100})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	forged, err := EncodeCursor([]byte("other-secret"), []any{1})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+
+	cases := map[string]string{
+		"payload swapped": payload + "." + signature,
+		"no separator":    strings.ReplaceAll(token, ".", ""),
+		"bad base64":      "!!!." + signature,
+		"empty":           "",
+	}
+	for name, tampered := range cases {
+		if _, err := DecodeCursor(testCursorSigningKey, tampered); !errors.Is(err, ErrCursorTokenInvalid) {
+			t.Errorf("%s: expected ErrCursorTokenInvalid, got %v", name, err)
+		}
+	}
+}
+
+// TestCursorToken_WrongKey 测试使用不同密钥解码失败
+func TestCursorToken_WrongKey(t *testing.T) {
+	token, err := EncodeCursor(testCursorSigningKey, []any{"x"})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if _, err := DecodeCursor([]byte("wrong-secret"), token); !errors.Is(err, ErrCursorTokenInvalid) {
+		t.Errorf("expected ErrCursorTokenInvalid, got %v", err)
+	}
+}
+
+// TestCursorToken_MissingKey 测试未配置签名密钥
+func TestCursorToken_MissingKey(t *testing.T) {
+	if _, err := EncodeCursor(nil, []any{1}); !errors.Is(err, ErrCursorSigningKeyMissing) {
+		t.Errorf("expected ErrCursorSigningKeyMissing on encode, got %v", err)
+	}
+	if _, err := DecodeCursor(nil, "a.b"); !errors.Is(err, ErrCursorSigningKeyMissing) {
+		t.Errorf("expected ErrCursorSigningKeyMissing on decode, got %v", err)
+	}
+}
+
+// TestGormBuilder_QueryPageCursorToken 测试 QueryPage 返回签名 token，且 token 可用于下一页查询
+func TestGormBuilder_QueryPageCursorToken(t *testing.T) {
+	ctx := context.Background()
+	var seenCursorValues []any
+
+	// 使用中间件短路，避免真实数据库查询
+	shortCircuit := func(ctx context.Context, builder Querier[CursorTestEntity], next func(context.Context) (core.Result[CursorTestEntity], error)) (core.Result[CursorTestEntity], error) {
+		seenCursorValues = builder.GetQueryMeta().CursorValues
+		return &core.CursorPageResult[CursorTestEntity]{
+			Items:            []*CursorTestEntity{{ID: 7, Name: "g"}},
+			HasMore:          true,
+			NextCursorValues: []any{int64(7)},
+		}, nil
+	}
+
+	first := NewGormBuilder[CursorTestEntity](NewDBProxy(&gorm.DB{}, nil, nil))
+	first.SetCursorSigningKey(testCursorSigningKey).SetCursorField("ID").SetLimit(1)
+	first.Use(shortCircuit)
+
+	page, err := first.QueryPage(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.NextCursorToken == "" {
+		t.Fatal("expected NextCursorToken to be set")
+	}
+
+	second := NewGormBuilder[CursorTestEntity](NewDBProxy(&gorm.DB{}, nil, nil))
+	second.SetCursorSigningKey(testCursorSigningKey).SetCursorToken(page.NextCursorToken).SetCursorField("ID").SetLimit(1)
+	second.Use(shortCircuit)
+
+	if _, err := second.QueryPage(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seenCursorValues) != 1 || seenCursorValues[0] != int64(7) {
+		t.Errorf("expected decoded cursor values [7], got %#v", seenCursorValues)
+	}
+}
+
+// TestGormBuilder_QueryPageInvalidCursorToken 测试非法 token 在查询前被拒绝
+func TestGormBuilder_QueryPageInvalidCursorToken(t *testing.T) {
+	b := NewGormBuilder[CursorTestEntity](NewDBProxy(&gorm.DB{}, nil, nil))
+	b.SetCursorSigningKey(testCursorSigningKey).SetCursorToken("forged.token").SetCursorField("ID")
+
+	if _, err := b.QueryPage(context.Background()); !errors.Is(err, ErrCursorTokenInvalid) {
+		t.Errorf("expected ErrCursorTokenInvalid, got %v", err)
+	}
+}
+
+// TestListQueryPage_WithCursorTokenOption 测试 List 通过 QueryOption 传递签名密钥与 token
+func TestListQueryPage_WithCursorTokenOption(t *testing.T) {
+	token, err := EncodeCursor(testCursorSigningKey, []any{int64(3)})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	var seenCursorValues []any
+	querier := NewGormBuilder[CursorTestEntity](NewDBProxy(&gorm.DB{}, nil, nil))
+	querier.Use(func(ctx context.Context, builder Querier[CursorTestEntity], next func(context.Context) (core.Result[CursorTestEntity], error)) (core.Result[CursorTestEntity], error) {
+		seenCursorValues = builder.GetQueryMeta().CursorValues
+		return &core.CursorPageResult[CursorTestEntity]{}, nil
+	})
+
+	list := NewList[CursorTestEntity]()
+	list.SetQuerier(querier)
+	if _, err := list.QueryPage(context.Background(),
+		WithCursorField("ID"),
+		WithCursorSigningKey(testCursorSigningKey),
+		WithCursorToken(token),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seenCursorValues) != 1 || seenCursorValues[0] != int64(3) {
+		t.Errorf("expected decoded cursor values [3], got %#v", seenCursorValues)
+	}
+}
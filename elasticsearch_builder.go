@@ -6,10 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"slices"
 	"time"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
-	"github.com/fantasticbin/QueryBuilder/v2/util"
 	"github.com/olivere/elastic/v7"
 )
 
@@ -23,6 +23,7 @@ type ElasticSearchBuilder[R any] struct {
 	builder[*ElasticSearchBuilder[R], R]
 	index        string           // ES 索引名，仅 ElasticSearch 构建器专属
 	filter       elastic.Query    // ES 专属过滤条件
+	extraFilters []elastic.Query  // 通过 AddFilter 追加的过滤条件，以 bool filter 与 filter 组合
 	sort         []elastic.Sorter // ES 专属排序条件
 	pitKeepAlive time.Duration    // Point-in-Time 保持时间
 	pitID        string           // 外部传入/内部更新的 PIT ID（用于跨请求分页）
@@ -59,6 +60,8 @@ func (e *ElasticSearchBuilder[R]) Clone() *ElasticSearchBuilder[R] {
 	e.builder.cloneBase(&cloned.builder)
 	cloned.builder.setSelf(cloned, cloned)
 
+	cloned.extraFilters = append([]elastic.Query(nil), e.extraFilters...)
+
 	// 深拷贝 sort 切片
 	if e.sort != nil {
 		cloned.sort = make([]elastic.Sorter, len(e.sort))
@@ -80,12 +83,61 @@ func (e *ElasticSearchBuilder[R]) SetFilter(filter elastic.Query) *ElasticSearch
 	return e
 }
 
+// AddFilter 追加 ElasticSearch 过滤条件，以 bool 查询的 filter 子句与 SetFilter 设置的条件组合
+func (e *ElasticSearchBuilder[R]) AddFilter(filters ...elastic.Query) *ElasticSearchBuilder[R] {
+	for _, filter := range filters {
+		if filter != nil {
+			e.extraFilters = append(e.extraFilters, filter)
+		}
+	}
+	return e
+}
+
+// hasFilter 是否设置了任何过滤条件（filter 或追加的过滤条件）
+func (e *ElasticSearchBuilder[R]) hasFilter() bool {
+	return e.filter != nil || len(e.extraFilters) > 0
+}
+
+// buildFilter 组合用户 filter 与追加的过滤条件，数据查询与总数统计共用
+func (e *ElasticSearchBuilder[R]) buildFilter() elastic.Query {
+	if len(e.extraFilters) == 0 {
+		if e.filter == nil {
+			return elastic.NewMatchAllQuery()
+		}
+		return e.filter
+	}
+
+	query := elastic.NewBoolQuery().Filter(e.extraFilters...)
+	if e.filter != nil {
+		query = query.Must(e.filter)
+	}
+	return query
+}
+
 // SetSort 设置 ElasticSearch 排序条件
 func (e *ElasticSearchBuilder[R]) SetSort(sort ...elastic.Sorter) *ElasticSearchBuilder[R] {
 	e.sort = sort
 	return e
 }
 
+// listSort 返回偏移分页使用的排序条件，配置稳定排序字段且 sort 未包含该字段时在末尾追加升序排序
+func (e *ElasticSearchBuilder[R]) listSort() []elastic.Sorter {
+	key := e.builder.stableSortKey
+	if key == "" {
+		return e.sort
+	}
+	for _, s := range e.sort {
+		if src, err := s.Source(); err == nil {
+			if fields, ok := src.(map[string]any); ok {
+				if _, ok := fields[key]; ok {
+					return e.sort
+				}
+			}
+		}
+	}
+	return append(slices.Clone(e.sort), elastic.NewFieldSort(key).Asc())
+}
+
 // Use 添加中间件（实现 Querier 接口）
 func (e *ElasticSearchBuilder[R]) Use(middleware Middleware[R]) Querier[R] {
 	e.builder.Use(middleware)
@@ -180,13 +232,14 @@ func (e *ElasticSearchBuilder[R]) QueryList(ctx context.Context) (*core.ListResu
 		newMiddlewareContext[R](&e.builder),
 		func(ctx context.Context) (core.Result[R], error) {
 			list, total, err := e.doQuery(ctx)
-			return &core.ListResult[R]{Items: list, Total: total}, err
+			list, hasMore := e.builder.trimPeek(list)
+			return &core.ListResult[R]{Items: list, Total: total, HasMore: hasMore}, err
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
-	return listResultFromResult(result), nil
+	return e.builder.finishListResult(listResultFromResult(result)), nil
 }
 
 // QueryCursor 执行 ElasticSearch 游标分页查询，返回迭代器（实现 Querier 接口）
@@ -332,15 +385,17 @@ func (e *ElasticSearchBuilder[R]) doQuery(ctx context.Context) (list []*R, total
 		return nil, 0, errors.New("elasticsearch index not configured")
 	}
 
-	if e.filter == nil {
-		e.filter = elastic.NewMatchAllQuery()
-	}
+	filter := e.buildFilter()
 
-	// 使用 WaitAndGo 并行执行数据查询和总数统计操作
-	if err = util.WaitAndGo(func() error {
+	// 使用 runParallel 并行执行数据查询和总数统计操作
+	if err = e.builder.runParallel(func() error {
+		if e.builder.skipData {
+			list = []*R{}
+			return nil
+		}
 		searchService := e.builder.data.ElasticSearch.Search().
 			Index(e.index).
-			Query(e.filter)
+			Query(filter)
 
 		// 应用字段投影
 		if len(e.builder.fields) > 0 {
@@ -349,7 +404,7 @@ func (e *ElasticSearchBuilder[R]) doQuery(ctx context.Context) (list []*R, total
 		}
 
 		// 处理排序
-		for _, s := range e.sort {
+		for _, s := range e.listSort() {
 			searchService = searchService.SortBy(s)
 		}
 
@@ -357,15 +412,18 @@ func (e *ElasticSearchBuilder[R]) doQuery(ctx context.Context) (list []*R, total
 			if e.builder.limit == 0 {
 				e.builder.limit = defaultLimit
 			}
-			searchService = searchService.From(int(e.builder.start)).Size(int(e.builder.limit))
+			searchService = searchService.From(int(e.builder.start)).Size(int(e.builder.pageFetchLimit()))
 		}
 
+		done := e.builder.observeDBCall(DBCallFind)
 		searchResult, err := searchService.Do(ctx)
+		done()
 		if err != nil {
 			return err
 		}
 
-		// 解析查询结果
+		// 解析查询结果，按实际命中数预分配
+		list = make([]*R, 0, len(searchResult.Hits.Hits))
 		for _, hit := range searchResult.Hits.Hits {
 			var item R
 			if err := json.Unmarshal(hit.Source, &item); err != nil {
@@ -380,7 +438,7 @@ func (e *ElasticSearchBuilder[R]) doQuery(ctx context.Context) (list []*R, total
 			return nil
 		}
 
-		count, err := e.countTotal(ctx, e.filter)
+		count, err := e.countTotal(e.builder.countContext(ctx), filter)
 		if err != nil {
 			return err
 		}
@@ -395,20 +453,29 @@ func (e *ElasticSearchBuilder[R]) doQuery(ctx context.Context) (list []*R, total
 	return list, total, nil
 }
 
-// countTotal 执行 Elasticsearch 总数统计；配置 totalLimit 时使用 track_total_hits 上限统计。
+// countTotal 执行总数统计，配置 Counter 时交由其处理，否则执行 exactCount
 func (e *ElasticSearchBuilder[R]) countTotal(ctx context.Context, filter elastic.Query) (int64, error) {
+	return e.builder.countWith(ctx, func(ctx context.Context) (int64, error) {
+		return e.exactCount(ctx, filter)
+	})
+}
+
+// exactCount 执行 Elasticsearch 精确总数统计；配置 totalLimit 时使用 track_total_hits 上限统计。
+func (e *ElasticSearchBuilder[R]) exactCount(ctx context.Context, filter elastic.Query) (int64, error) {
+	defer e.builder.observeDBCall(DBCallCount)()
+	client := e.builder.countProxy().ElasticSearch
 	if e.builder.totalLimit == 0 {
-		return e.builder.data.ElasticSearch.Count().
+		return client.Count().
 			Index(e.index).
 			Query(filter).
 			Do(ctx)
 	}
 
-	searchResult, err := e.builder.data.ElasticSearch.Search().
+	searchResult, err := client.Search().
 		Index(e.index).
 		Query(filter).
 		Size(0).
-		TrackTotalHits(int(e.builder.totalLimit)).
+		TrackTotalHits(int(e.builder.countProbeLimit())).
 		Do(ctx)
 	if err != nil {
 		return 0, err
@@ -432,16 +499,12 @@ func (e *ElasticSearchBuilder[R]) Explain(ctx context.Context) (string, error) {
 		return e.explainCursor(ctx)
 	}
 
-	if e.filter == nil {
-		e.filter = elastic.NewMatchAllQuery()
-	}
-
 	result := map[string]any{
 		"index": e.index,
 	}
 
 	// 序列化查询条件
-	querySource, err := e.filter.Source()
+	querySource, err := e.buildFilter().Source()
 	if err != nil {
 		return "", err
 	}
@@ -451,9 +514,9 @@ func (e *ElasticSearchBuilder[R]) Explain(ctx context.Context) (string, error) {
 		result["_source"] = e.builder.fields
 	}
 
-	if len(e.sort) > 0 {
+	if sorters := e.listSort(); len(sorters) > 0 {
 		var sortList []any
-		for _, s := range e.sort {
+		for _, s := range sorters {
 			src, err := s.Source()
 			if err != nil {
 				return "", err
@@ -468,7 +531,7 @@ func (e *ElasticSearchBuilder[R]) Explain(ctx context.Context) (string, error) {
 			e.builder.limit = defaultLimit
 		}
 		result["from"] = e.builder.start
-		result["size"] = e.builder.limit
+		result["size"] = e.builder.pageFetchLimit()
 	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
@@ -538,10 +601,7 @@ func (e *ElasticSearchBuilder[R]) explainCursor(ctx context.Context) (string, er
 		batchSize = defaultLimit
 	}
 
-	filter := e.filter
-	if filter == nil {
-		filter = elastic.NewMatchAllQuery()
-	}
+	filter := e.buildFilter()
 
 	result := map[string]any{
 		"mode":  "cursor",
@@ -626,10 +686,7 @@ func (e *ElasticSearchBuilder[R]) doCursorQuery(
 		batchSize = defaultLimit
 	}
 
-	filter := e.filter
-	if filter == nil {
-		filter = elastic.NewMatchAllQuery()
-	}
+	filter := e.buildFilter()
 
 	querySize := batchSize
 	if forcePIT {
@@ -656,9 +713,11 @@ func (e *ElasticSearchBuilder[R]) doCursorQuery(
 			return nil, nil, 0, false, errors.New("pitID pointer is nil")
 		}
 		if *pitID == "" {
+			done := e.builder.observeDBCall(DBCallOpenPIT)
 			openResp, err := e.builder.data.ElasticSearch.OpenPointInTime(e.index).
 				KeepAlive(e.pitKeepAliveString()).
 				Do(ctx)
+			done()
 			if err != nil {
 				return nil, nil, 0, false, err
 			}
@@ -685,9 +744,11 @@ func (e *ElasticSearchBuilder[R]) doCursorQuery(
 	}
 
 	var searchResult *elastic.SearchResult
-	if err = util.WaitAndGo(func() error {
+	if err = e.builder.runParallel(func() error {
 		var err error
+		done := e.builder.observeDBCall(DBCallFind)
 		searchResult, err = searchService.Do(ctx)
+		done()
 		if err != nil {
 			return err
 		}
@@ -703,7 +764,7 @@ func (e *ElasticSearchBuilder[R]) doCursorQuery(
 			return nil
 		}
 
-		count, err := e.countTotal(ctx, filter)
+		count, err := e.countTotal(e.builder.countContext(ctx), filter)
 		if err != nil {
 			return err
 		}
@@ -724,11 +785,11 @@ func (e *ElasticSearchBuilder[R]) doCursorQuery(
 
 	list = make([]*R, 0, len(effectiveHits))
 	for _, hit := range effectiveHits {
-		var item R
-		if err := json.Unmarshal(hit.Source, &item); err != nil {
+		item := e.builder.newResultItem()
+		if err := json.Unmarshal(hit.Source, item); err != nil {
 			return nil, nil, 0, false, err
 		}
-		list = append(list, &item)
+		list = append(list, item)
 	}
 
 	// 从最后一条有效 hit 的 Sort 字段提取 sort values 作为下一批的 search_after 参数
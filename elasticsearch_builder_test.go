@@ -261,3 +261,18 @@ func TestElasticSearchBuilderQueryPageWithPITRejectsCursorWithoutPITID(t *testin
 		t.Fatalf("expected ErrPITCursorWithoutPITID, got %v", err)
 	}
 }
+
+// TestElasticSearchBuilder_StableSort 测试稳定排序字段仅在 sort 未包含时追加
+func TestElasticSearchBuilder_StableSort(t *testing.T) {
+	b := NewElasticSearchBuilder[TestEntity](NewDBProxy(nil, nil, nil), "test")
+	b.SetSort(elastic.NewFieldSort("score").Desc())
+	b.SetStableSort("id")
+	if sort := b.listSort(); len(sort) != 2 {
+		t.Fatalf("expected tiebreaker to be appended, got %d sorters", len(sort))
+	}
+
+	b.SetSort(elastic.NewFieldSort("id").Desc())
+	if sort := b.listSort(); len(sort) != 1 {
+		t.Errorf("expected no tiebreaker when sort already has the key, got %d sorters", len(sort))
+	}
+}
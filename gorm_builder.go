@@ -2,29 +2,77 @@ package builder
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
 	"github.com/fantasticbin/QueryBuilder/v2/util"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 )
 
 // GormScope GORM 查询作用域类型
 type GormScope = func(*gorm.DB) *gorm.DB
 
+var (
+	// ErrInvalidSubqueryAlias 使用子查询或表值函数作为数据源时别名为空或不是合法的 SQL 标识符
+	ErrInvalidSubqueryAlias = errors.New("invalid subquery alias")
+	// ErrShardedCursorUnsupported 分片数据源不支持游标分页查询
+	ErrShardedCursorUnsupported = errors.New("cursor queries are not supported on sharded GORM data sources")
+)
+
+const (
+	// defaultShardConcurrency 分片查询默认的最大并发数
+	defaultShardConcurrency = 8
+	// rawQueryAlias 原生 SQL 数据源包装为子查询时使用的别名
+	rawQueryAlias = "raw_query"
+)
+
 // GormBuilder GORM 兼容数据库专属查询构建器
 // 泛型参数:
 //
 //	R: 查询结果的实体类型
 type GormBuilder[R any] struct {
 	builder[*GormBuilder[R], R]
-	filter GormScope // GORM 专属过滤条件
-	sort   GormScope // GORM 专属排序条件
+	filter GormScope   // GORM 专属过滤条件
+	sort   []GormScope // GORM 专属排序条件，按顺序依次应用
+
+	extraFilters     []GormScope         // 通过 AddFilter 追加的过滤条件，与 filter 以 AND 组合
+	mandatoryFilters []GormScope         // List 强制过滤条件（如租户隔离），在用户过滤条件分组之外以 AND 追加
+	softDeleteColumn string              // 非标准软删除列名（如 is_deleted），为空表示不启用
+	softDeleteValue  any                 // 表示"已删除"的列值
+	fromSubquery     *gorm.DB            // 作为数据源的子查询，为 nil 表示直接查询 R 对应的表
+	fromAlias        string              // 子查询或函数数据源的别名
+	fromFunction     string              // 作为数据源的表值函数表达式（如 unnest(?)），为空表示不启用
+	fromFunctionArgs []any               // 表值函数表达式的参数
+	rawQuery         string              // 作为数据源的原生 SQL，为空表示不启用
+	rawQueryArgs     []any               // 原生 SQL 的参数，支持 sql.Named 命名参数
+	rawTable         string              // 直接查询的表名，配置后不再调用 Model(new(R)) 解析模型
+	joins            []gormJoin          // 通过 AddJoin 追加的关联查询，在 filter 之前应用
+	countSkipJoins   bool                // 总数统计是否跳过 joins，默认与数据查询一致
+	clauses          []clause.Expression // 透传给数据查询的 GORM 子句（如锁、索引提示等）
+	countModifiers   []GormScope         // 仅作用于总数统计的查询修饰（如强制覆盖索引）
+	countPartitions  []string            // 分区统计时逐个统计的分区表（或表表达式），为空表示统计整表
+	fullTextRank     GormScope           // 按全文检索相关度排序的作用域，在所有排序之后应用并置于最前
+	shardCompare     func(a, b *R) int   // 分片结果合并后的排序比较函数，为 nil 时按分片顺序拼接
+	shardConcurrency int                 // 分片查询的最大并发数，<= 0 时使用 defaultShardConcurrency
+	consistentRead   bool                // 数据查询与总数统计是否在同一事务快照中顺序执行
+	deadlockRetries  int                 // 遇到数据库死锁时的最大重试次数，0 表示不重试
+}
+
+// gormJoin 单个 Joins 条件
+type gormJoin struct {
+	query string
+	args  []any
 }
 
 // self 返回自身引用，实现 builderInterface 接口
@@ -47,8 +95,29 @@ func NewGormBuilder[R any](data *DBProxy) *GormBuilder[R] {
 // 注意：原 GormBuilder 非并发安全，请勿在多 goroutine 中共享同一实例进行写操作
 func (g *GormBuilder[R]) Clone() *GormBuilder[R] {
 	cloned := &GormBuilder[R]{
-		filter: g.filter,
-		sort:   g.sort,
+		filter:           g.filter,
+		sort:             append([]GormScope(nil), g.sort...),
+		extraFilters:     append([]GormScope(nil), g.extraFilters...),
+		mandatoryFilters: append([]GormScope(nil), g.mandatoryFilters...),
+		softDeleteColumn: g.softDeleteColumn,
+		softDeleteValue:  g.softDeleteValue,
+		fromSubquery:     g.fromSubquery,
+		fromAlias:        g.fromAlias,
+		fromFunction:     g.fromFunction,
+		fromFunctionArgs: append([]any(nil), g.fromFunctionArgs...),
+		rawQuery:         g.rawQuery,
+		rawQueryArgs:     append([]any(nil), g.rawQueryArgs...),
+		rawTable:         g.rawTable,
+		joins:            append([]gormJoin(nil), g.joins...),
+		countSkipJoins:   g.countSkipJoins,
+		clauses:          append([]clause.Expression(nil), g.clauses...),
+		countModifiers:   append([]GormScope(nil), g.countModifiers...),
+		countPartitions:  append([]string(nil), g.countPartitions...),
+		fullTextRank:     g.fullTextRank,
+		shardCompare:     g.shardCompare,
+		shardConcurrency: g.shardConcurrency,
+		consistentRead:   g.consistentRead,
+		deadlockRetries:  g.deadlockRetries,
 	}
 	g.builder.cloneBase(&cloned.builder)
 	cloned.builder.setSelf(cloned, cloned)
@@ -61,12 +130,247 @@ func (g *GormBuilder[R]) SetFilter(filter GormScope) *GormBuilder[R] {
 	return g
 }
 
-// SetSort 设置 GORM 排序条件
-func (g *GormBuilder[R]) SetSort(sort GormScope) *GormBuilder[R] {
-	g.sort = sort
+// AddFilter 追加 GORM 过滤条件，与 SetFilter 设置的条件以 AND 组合
+// 适合叠加 GormJSONFilter 等可复用的过滤片段
+func (g *GormBuilder[R]) AddFilter(filters ...GormScope) *GormBuilder[R] {
+	for _, filter := range filters {
+		if filter != nil {
+			g.extraFilters = append(g.extraFilters, filter)
+		}
+	}
+	return g
+}
+
+// addMandatoryFilter 追加强制过滤条件，不计入 hasFilter，也不与用户过滤条件合并分组
+func (g *GormBuilder[R]) addMandatoryFilter(filter GormScope) {
+	g.mandatoryFilters = append(g.mandatoryFilters, filter)
+}
+
+// SetSort 设置 GORM 排序条件，支持传入多个排序作用域并按顺序应用
+// 适合"先按相关度、再按时间"等组合排序场景；nil 会被忽略，不传参数表示清空排序
+func (g *GormBuilder[R]) SetSort(sorts ...GormScope) *GormBuilder[R] {
+	g.sort = nil
+	for _, sort := range sorts {
+		if sort != nil {
+			g.sort = append(g.sort, sort)
+		}
+	}
 	return g
 }
 
+// SetSoftDelete 设置非标准软删除列（如 is_deleted = true、deleted = 1）
+// 启用后数据查询与总数统计都会自动追加 column <> deletedValue 条件，
+// 用于兼容未使用 gorm.DeletedAt 约定的旧表；注意该列为 NULL 的行同样会被排除
+func (g *GormBuilder[R]) SetSoftDelete(column string, deletedValue any) *GormBuilder[R] {
+	g.softDeleteColumn = column
+	g.softDeleteValue = deletedValue
+	return g
+}
+
+// SetFromSubquery 使用子查询（或视图查询）作为数据源，生成 SELECT ... FROM (sub) AS alias
+// filter/sort/分页/游标条件均作用于子查询结果之上，总数统计同样基于该子查询；
+// 适用于不对应实体表的报表类查询；alias 须为合法标识符，生成 SQL 时按方言加引号，
+// 子查询中不包含 R 对应的表，外层不追加 gorm.DeletedAt 软删除条件
+func (g *GormBuilder[R]) SetFromSubquery(sub *gorm.DB, alias string) *GormBuilder[R] {
+	g.fromSubquery = sub
+	g.fromAlias = alias
+	return g
+}
+
+// SetFromFunction 使用表值函数（如 PostgreSQL 的 unnest、generate_series 或返回表的存储函数）作为数据源，
+// 生成 SELECT ... FROM expr AS alias，expr 中的 ? 占位符由 args 参数化绑定；
+// filter/sort/分页/游标条件均作用于函数结果之上，与 SetFromSubquery 同时设置时以子查询为准；
+// alias 须为合法标识符，可附带列别名列表（如 t(id)），生成 SQL 时按方言加引号，外层不追加 gorm.DeletedAt 软删除条件
+func (g *GormBuilder[R]) SetFromFunction(expr, alias string, args ...any) *GormBuilder[R] {
+	g.fromFunction = expr
+	g.fromFunctionArgs = args
+	g.fromAlias = alias
+	return g
+}
+
+// SetRawQuery 使用手写的原生 SQL 作为数据源（等价于 db.Raw(sql, args...)），生成 SELECT ... FROM (sql) AS raw_query
+// 原生 SQL 作为子查询包装后仍经过中间件链，filter/sort/分页/游标条件与总数统计均作用于其结果之上，
+// 外层条件可通过 raw_query 别名引用列；args 支持 ? 占位符或 sql.Named 命名参数（SQL 中写作 @name），
+// 同时设置 SetFromSubquery 时以子查询为准，优先于 SetFromFunction
+func (g *GormBuilder[R]) SetRawQuery(sql string, args ...any) *GormBuilder[R] {
+	g.rawQuery = sql
+	g.rawQueryArgs = args
+	return g
+}
+
+// SetRawScan 直接从指定表查询并扫描结果（等价于 db.Table(table).Find(&list)），不再调用 Model(new(R))
+// 适用于匿名、嵌入或报表类等无法映射为 GORM 模型的结果类型，同时省去按模型推导表名的开销；
+// 注意 R 上的 gorm.DeletedAt 软删除条件等模型约定随之失效，与 SetFromSubquery 同时设置时以子查询为准
+func (g *GormBuilder[R]) SetRawScan(table string) *GormBuilder[R] {
+	g.rawTable = table
+	return g
+}
+
+// SetConsistentRead 设置数据查询与总数统计是否读取同一快照
+// 默认两者并行执行，期间写入的数据可能导致总数与返回的数据不一致；开启后两者在同一只读快照事务中顺序执行，
+// PostgreSQL、MySQL 使用只读的 REPEATABLE READ 事务，SQL Server 使用 SNAPSHOT（需开启 ALLOW_SNAPSHOT_ISOLATION），
+// 其余方言使用驱动默认隔离级别（SQLite 事务本身即可串行化）；以损失并行度换取一致性，对分片查询不生效
+func (g *GormBuilder[R]) SetConsistentRead(consistent bool) *GormBuilder[R] {
+	g.consistentRead = consistent
+	return g
+}
+
+// AddJoin 追加关联查询（等价于 db.Joins(query, args...)），在 filter 之前应用，
+// 使 filter 的 WHERE 条件可以引用关联表的列；数据查询、游标查询与总数统计默认都会应用 joins
+// 注意：一对多关联会使结果行成倍增加，需要时在 SetFields 中使用 DISTINCT 或改用 EXISTS 子查询过滤
+func (g *GormBuilder[R]) AddJoin(query string, args ...any) *GormBuilder[R] {
+	g.joins = append(g.joins, gormJoin{query: query, args: args})
+	return g
+}
+
+// SetCountWithJoins 设置总数统计是否应用 joins（默认 true）
+// 当 joins 仅用于补充查询字段、filter 不依赖关联表时，可关闭以避免一对多关联导致总数被放大
+func (g *GormBuilder[R]) SetCountWithJoins(withJoins bool) *GormBuilder[R] {
+	g.countSkipJoins = !withJoins
+	return g
+}
+
+// AddClauses 追加透传给数据查询的 GORM 子句（等价于 db.Clauses(...)）
+// 作为结构化配置无法覆盖的高级子句的兜底手段（如 clause.Locking、hints.UseIndex、方言专属子句），
+// 与 filter/sort/分页共同生效；仅作用于数据查询与游标查询，不作用于总数统计
+func (g *GormBuilder[R]) AddClauses(clauses ...clause.Expression) *GormBuilder[R] {
+	for _, c := range clauses {
+		if c != nil {
+			g.clauses = append(g.clauses, c)
+		}
+	}
+	return g
+}
+
+// AddCountModifier 追加仅作用于总数统计的查询修饰，在过滤条件之后应用，nil 会被忽略
+// 适用于只需调整 Count 执行计划的场景，例如强制走覆盖索引而不影响数据查询
+func (g *GormBuilder[R]) AddCountModifier(modifiers ...GormScope) *GormBuilder[R] {
+	for _, modifier := range modifiers {
+		if modifier != nil {
+			g.countModifiers = append(g.countModifiers, modifier)
+		}
+	}
+	return g
+}
+
+// SetPartitionedCount 设置按分区并行统计总数：对每个分区以相同的 joins、过滤条件与统计修饰分别执行 COUNT 并求和，
+// 适用于按月等维度分区的大表，精确的全表 COUNT 较慢而各分区并行统计更快的场景。partitions 为分区表名或表表达式，
+// 如 PostgreSQL 的子表 "orders_2024_01 AS orders"、MySQL 的 "orders PARTITION (p202401)"，需覆盖全部分区且互不重叠；
+// 并发度默认为 8，配置 SetParallelism 时不超过该值；软删除条件以表达式的首个标识符限定；配置子查询、原生 SQL 或表值函数数据源时不生效
+func (g *GormBuilder[R]) SetPartitionedCount(partitions ...string) *GormBuilder[R] {
+	g.countPartitions = partitions
+	return g
+}
+
+// SetFullText 设置 PostgreSQL 全文检索：追加 column @@ plainto_tsquery(?) 过滤条件，query 以参数形式绑定
+// rank 为 true 时数据查询按 ts_rank 相关度降序排列，已有的 sort 与稳定排序作为同分时的次级排序（游标查询不受影响）；
+// query 去除空白后为空时不做任何修改
+func (g *GormBuilder[R]) SetFullText(column, query string, rank bool) *GormBuilder[R] {
+	if strings.TrimSpace(query) == "" {
+		return g
+	}
+	g.extraFilters = append(g.extraFilters, GormFullText(column, query))
+	if rank {
+		g.fullTextRank = gormFullTextRankScope(column, query)
+	}
+	return g
+}
+
+// SetConnTag 选择 DBProxy.GormConns 中指定标签的连接执行查询，适用于同一服务以相同模型访问多个数据库的场景
+// tag 为空时使用 DBProxy.DB；标签不存在时查询返回 ErrConnTagNotFound
+func (g *GormBuilder[R]) SetConnTag(tag string) *GormBuilder[R] {
+	g.builder.connTag = tag
+	return g
+}
+
+// SetShardCompare 设置分片结果合并后的排序比较函数（语义同 slices.SortFunc），应与 SetSort 的排序口径一致
+// 仅在 DBProxy 配置了 GormShards 时生效；未设置时各分片结果按分片顺序拼接
+func (g *GormBuilder[R]) SetShardCompare(cmp func(a, b *R) int) *GormBuilder[R] {
+	g.shardCompare = cmp
+	return g
+}
+
+// SetShardConcurrency 设置分片查询的最大并发数，<= 0 时使用默认值 8
+func (g *GormBuilder[R]) SetShardConcurrency(n int) *GormBuilder[R] {
+	g.shardConcurrency = n
+	return g
+}
+
+// applyJoins 按添加顺序应用 joins
+func (g *GormBuilder[R]) applyJoins(query *gorm.DB) *gorm.DB {
+	for _, join := range g.joins {
+		query = query.Joins(join.query, join.args...)
+	}
+	return query
+}
+
+// baseQuery 创建查询的基础对象：默认为 R 对应的表，配置子查询时为 (sub) AS alias，
+// 配置原生 SQL 时为 (sql) AS raw_query，配置表值函数时为 expr AS alias，配置 SetRawScan 时为指定表
+func (g *GormBuilder[R]) baseQuery(db *gorm.DB) *gorm.DB {
+	if !g.hasCustomSource() && g.rawTable != "" {
+		return db.Table(g.rawTable)
+	}
+	query := db.Model(new(R))
+	if !g.hasCustomSource() {
+		return query
+	}
+	// 子查询、原生 SQL 与函数结果中不包含 R 对应的表，关闭 gorm.DeletedAt 软删除条件，否则外层会引用 FROM 中不存在的表
+	query = query.Unscoped()
+	if g.fromSubquery == nil && g.rawQuery != "" {
+		raw := db.Session(&gorm.Session{NewDB: true}).Raw(g.rawQuery, g.rawQueryArgs...)
+		return query.Table("(?) AS "+rawQueryAlias, raw)
+	}
+	if g.fromSubquery == nil {
+		return aliasedSource(query, g.fromFunction, g.fromAlias, true, g.fromFunctionArgs...)
+	}
+	return aliasedSource(query, "(?)", g.fromAlias, false, g.fromSubquery)
+}
+
+// aliasedSource 以 expr AS alias 形式设置数据源，alias 经校验后按方言加引号，避免拼接注入；
+// withColumns 为 true 时允许表值函数的列别名列表形式 t(a, b)
+func aliasedSource(query *gorm.DB, expr, alias string, withColumns bool, args ...any) *gorm.DB {
+	quoted, name, ok := quoteSourceAlias(query.Statement, alias, withColumns)
+	if !ok {
+		_ = query.AddError(fmt.Errorf("%w: %q", ErrInvalidSubqueryAlias, alias))
+		return query
+	}
+	query = query.Table(expr+" AS "+quoted, args...)
+	// 加引号的别名无法被 Table 识别为当前表名，需显式设置，供 clause.CurrentTable 等引用
+	query.Statement.Table = name
+	return query
+}
+
+// quoteSourceAlias 校验并按方言引用数据源别名，返回引用后的别名表达式与表名
+// 别名及列别名均须为合法标识符，withColumns 为 false 时不允许列别名列表
+func quoteSourceAlias(stmt *gorm.Statement, alias string, withColumns bool) (quoted, name string, ok bool) {
+	name, columnList, hasColumns := strings.Cut(alias, "(")
+	name = strings.TrimSpace(name)
+	if !sqlIdentifierPattern.MatchString(name) {
+		return "", "", false
+	}
+	if !hasColumns {
+		return stmt.Quote(name), name, true
+	}
+	columnList, closed := strings.CutSuffix(strings.TrimSpace(columnList), ")")
+	if !withColumns || !closed {
+		return "", "", false
+	}
+	columns := strings.Split(columnList, ",")
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		if !sqlIdentifierPattern.MatchString(column) {
+			return "", "", false
+		}
+		columns[i] = stmt.Quote(column)
+	}
+	return stmt.Quote(name) + "(" + strings.Join(columns, ", ") + ")", name, true
+}
+
+// hasCustomSource 是否配置了子查询、原生 SQL 或表值函数数据源
+func (g *GormBuilder[R]) hasCustomSource() bool {
+	return g.fromSubquery != nil || g.rawQuery != "" || g.fromFunction != ""
+}
+
 // Use 添加中间件（实现 Querier 接口）
 func (g *GormBuilder[R]) Use(middleware Middleware[R]) Querier[R] {
 	g.builder.Use(middleware)
@@ -148,14 +452,22 @@ func (g *GormBuilder[R]) QueryList(ctx context.Context) (*core.ListResult[R], er
 		ctx,
 		newMiddlewareContext[R](&g.builder),
 		func(ctx context.Context) (core.Result[R], error) {
-			list, total, err := g.doQuery(ctx)
-			return &core.ListResult[R]{Items: list, Total: total}, err
+			var (
+				list  []*R
+				total int64
+			)
+			err := g.retryOnDeadlock(ctx, func() (err error) {
+				list, total, err = g.doQuery(ctx)
+				return err
+			})
+			list, hasMore := g.builder.trimPeek(list)
+			return &core.ListResult[R]{Items: list, Total: total, HasMore: hasMore}, err
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
-	return listResultFromResult(result), nil
+	return g.builder.finishListResult(listResultFromResult(result)), nil
 }
 
 // QueryCursor 执行 GORM 游标分页查询，返回迭代器（实现 Querier 接口）
@@ -164,7 +476,7 @@ func (g *GormBuilder[R]) QueryCursor(ctx context.Context) iter.Seq2[*R, error] {
 		ctx,
 		&g.builder,
 		func(ctx context.Context, cursorValues []any, isFirstBatch bool) ([]*R, []any, int64, bool, error) {
-			return g.doCursorQuery(ctx, cursorValues, isFirstBatch, false)
+			return g.retryCursorQuery(ctx, cursorValues, isFirstBatch, false)
 		},
 	)
 }
@@ -180,50 +492,191 @@ func (g *GormBuilder[R]) QueryPage(ctx context.Context) (*core.CursorPageResult[
 		ctx,
 		newMiddlewareContext[R](&g.builder),
 		func(ctx context.Context, cursorValues []any, isFirstBatch bool) ([]*R, []any, int64, bool, error) {
-			return g.doCursorQuery(ctx, cursorValues, isFirstBatch, true)
+			return g.retryCursorQuery(ctx, cursorValues, isFirstBatch, true)
 		},
 	)
 }
 
+// retryCursorQuery 执行单批次游标查询，遇到死锁时按 deadlockRetries 重试
+func (g *GormBuilder[R]) retryCursorQuery(ctx context.Context, cursorValues []any, isFirstBatch bool, probeHasMore bool) ([]*R, []any, int64, bool, error) {
+	var (
+		list    []*R
+		next    []any
+		total   int64
+		hasMore bool
+	)
+	err := g.retryOnDeadlock(ctx, func() (err error) {
+		list, next, total, hasMore, err = g.doCursorQuery(ctx, cursorValues, isFirstBatch, probeHasMore)
+		return err
+	})
+	return list, next, total, hasMore, err
+}
+
+// BuildQuery 返回已应用字段投影、过滤、排序与分页的 *gorm.DB，不执行查询
+// 供调用方继续链式调用构建器未覆盖的 GORM 操作（如 Pluck、Update、自定义 Scan）；
+// LIMIT 为请求的 limit，不包含 peekNext 探测下一页多取的一条
+func (g *GormBuilder[R]) BuildQuery(ctx context.Context) (*gorm.DB, error) {
+	return g.buildModelQuery(ctx, false)
+}
+
+// buildModelQuery 校验配置并构建绑定 R 模型的数据查询，peek 为 true 时按 peekNext 多取一条
+func (g *GormBuilder[R]) buildModelQuery(ctx context.Context, peek bool) (*gorm.DB, error) {
+	if err := g.builder.prepareAndValidate(); err != nil {
+		return nil, err
+	}
+	query := g.paginate(g.buildUnpagedQuery(g.builder.data.DB.WithContext(ctx)), peek)
+	if !g.hasCustomSource() && g.rawTable != "" {
+		return query, nil
+	}
+	return query.Model(new(R)), nil
+}
+
 // buildQuery 构建公共的 GORM 查询对象（私有方法）
-// 将字段投影、过滤条件、排序条件、分页等公共逻辑统一抽取
+// 将字段投影、过滤条件、排序条件、分页等公共逻辑统一抽取，开启 peekNext 时多取一条用于探测下一页
 func (g *GormBuilder[R]) buildQuery(db *gorm.DB) *gorm.DB {
-	query := db.Model(new(R))
+	return g.paginate(g.buildUnpagedQuery(db), true)
+}
+
+// paginate 在需要分页时应用 OFFSET/LIMIT，peek 为 true 时 LIMIT 使用 pageFetchLimit
+func (g *GormBuilder[R]) paginate(query *gorm.DB, peek bool) *gorm.DB {
+	if !g.builder.needPagination {
+		return query
+	}
+	if g.builder.limit == 0 {
+		g.builder.limit = defaultLimit
+	}
+	limit := g.builder.limit
+	if peek {
+		limit = g.builder.pageFetchLimit()
+	}
+	// LIMIT/OFFSET 经 clause.Limit 以绑定参数传递而非内联，不同页共用同一条预编译语句
+	return query.Offset(int(g.builder.start)).Limit(int(limit))
+}
+
+// buildUnpagedQuery 构建不含分页的 GORM 查询对象，分片查询在此基础上自行决定每个分片的拉取条数
+func (g *GormBuilder[R]) buildUnpagedQuery(db *gorm.DB) *gorm.DB {
+	query := g.applyJoins(g.baseQuery(db))
+	if len(g.clauses) > 0 {
+		query = query.Clauses(g.clauses...)
+	}
 
 	// 应用字段投影
 	if len(g.builder.fields) > 0 {
 		query = query.Select(g.builder.fields)
 	}
 
-	if g.filter != nil {
-		query = query.Scopes(g.filter)
+	query = g.applyFilter(query)
+	if len(g.sort) > 0 {
+		query = query.Scopes(g.sort...)
 	}
-	if g.sort != nil {
-		query = query.Scopes(g.sort)
+	if g.builder.stableSortKey != "" {
+		query = query.Scopes(stableSortScope(g.builder.stableSortKey))
+	}
+	if g.fullTextRank != nil {
+		query = query.Scopes(g.fullTextRank)
 	}
 
-	if g.builder.needPagination {
-		if g.builder.limit == 0 {
-			g.builder.limit = defaultLimit
+	return query
+}
+
+// stableSortScope 返回追加稳定排序的作用域，需在用户 sort 之后应用，排序中已包含该列时不重复追加
+func stableSortScope(column string) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		if orderByHasColumn(db.Statement, column) {
+			return db
+		}
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}})
+	}
+}
+
+// orderByHasColumn 判断已有的 ORDER BY 子句是否包含指定列，兼容 Order("a DESC, t.b") 形式的原始排序表达式
+func orderByHasColumn(stmt *gorm.Statement, column string) bool {
+	c, ok := stmt.Clauses["ORDER BY"]
+	if !ok {
+		return false
+	}
+	orderBy, ok := c.Expression.(clause.OrderBy)
+	if !ok {
+		return false
+	}
+	for _, col := range orderBy.Columns {
+		for part := range strings.SplitSeq(col.Column.Name, ",") {
+			fields := strings.Fields(part)
+			if len(fields) == 0 {
+				continue
+			}
+			name := strings.Trim(fields[0], "`\"[]")
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = strings.Trim(name[i+1:], "`\"[]")
+			}
+			if strings.EqualFold(name, column) {
+				return true
+			}
 		}
-		query = query.Offset(int(g.builder.start)).Limit(int(g.builder.limit))
 	}
+	return false
+}
 
+// hasFilter 是否设置了任何过滤条件（filter 或追加的过滤条件）
+func (g *GormBuilder[R]) hasFilter() bool {
+	return g.filter != nil || len(g.extraFilters) > 0
+}
+
+// applyFilter 应用用户 filter、强制过滤条件与软删除条件，数据查询与总数统计共用，保证两者过滤口径一致
+// 用户过滤条件整体作为一个括号分组追加，其中的 Or 条件不会与强制过滤条件、软删除条件按运算符优先级错误组合
+func (g *GormBuilder[R]) applyFilter(query *gorm.DB) *gorm.DB {
+	if g.hasFilter() {
+		query = query.Where(scopeGroup(query, append([]GormScope{g.filter}, g.extraFilters...)))
+	}
+	for _, filter := range g.mandatoryFilters {
+		query = query.Where(scopeGroup(query, []GormScope{filter}))
+	}
+	if g.softDeleteColumn != "" {
+		query = query.Where(clause.Neq{Column: clause.Column{Name: g.softDeleteColumn}, Value: g.softDeleteValue})
+	}
 	return query
 }
 
+// scopeGroup 在不继承已有条件的新会话上依次应用作用域（忽略 nil），供 Where 作为一个分组引用
+// 作用域中记录的错误（如非法 JSON 路径）同步到 query，避免随分组会话一起被丢弃
+func scopeGroup(query *gorm.DB, scopes []GormScope) *gorm.DB {
+	group := query.Session(&gorm.Session{NewDB: true})
+	for _, scope := range scopes {
+		if scope != nil {
+			group = scope(group)
+		}
+	}
+	if query.Error == nil && group.Error != nil {
+		_ = query.AddError(group.Error)
+	}
+	return group
+}
+
 // doQuery 执行实际的 GORM 查询逻辑
 func (g *GormBuilder[R]) doQuery(ctx context.Context) (list []*R, total int64, err error) {
-	// 使用 WaitAndGo 并行执行数据查询和总数统计操作
-	if err = util.WaitAndGo(func() error {
+	if len(g.builder.data.GormShards) > 0 {
+		return g.doShardedQuery(ctx)
+	}
+	if g.consistentRead {
+		return g.doConsistentQuery(ctx)
+	}
+
+	// 使用 runParallel 并行执行数据查询和总数统计操作
+	if err = g.builder.runParallel(func() error {
+		if g.builder.skipData {
+			list = []*R{}
+			return nil
+		}
 		query := g.buildQuery(g.builder.data.DB.WithContext(ctx))
+		list = make([]*R, 0, g.builder.resultCapacityHint())
+		defer g.builder.observeDBCall(DBCallFind)()
 		return query.Find(&list).Error
 	}, func() error {
 		if !g.builder.needTotal {
 			return nil
 		}
 
-		return g.countTotal(ctx, &total)
+		return g.countTotal(g.countDB(ctx), &total)
 	}); err != nil {
 		return nil, 0, err
 	}
@@ -231,19 +684,211 @@ func (g *GormBuilder[R]) doQuery(ctx context.Context) (list []*R, total int64, e
 	return list, total, nil
 }
 
-// countTotal 执行总数统计；配置 totalLimit 时通过子查询限制最多扫描的记录数。
-func (g *GormBuilder[R]) countTotal(ctx context.Context, total *int64) error {
-	query := g.builder.data.DB.WithContext(ctx).Model(new(R))
-	if g.filter != nil {
-		query = query.Scopes(g.filter)
+// queryValues 执行数据查询并解码为值切片，needTotal 时随后统计总数；不经过钩子与中间件
+func (g *GormBuilder[R]) queryValues(ctx context.Context) (values []R, total int64, err error) {
+	if len(g.builder.data.GormShards) > 0 {
+		return nil, 0, ErrQueryValuesUnsupported
+	}
+	if g.builder.emptyResult {
+		return []R{}, 0, nil
+	}
+	values = make([]R, 0, g.builder.resultCapacityHint())
+	if !g.builder.skipData {
+		query, err := g.buildModelQuery(ctx, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := g.findValues(query, &values); err != nil {
+			return nil, 0, err
+		}
+		values = trimPeekValues(&g.builder, values)
+	}
+	if g.builder.needTotal {
+		if err := g.countTotal(g.countDB(ctx), &total); err != nil {
+			return nil, 0, err
+		}
 	}
+	return values, total, nil
+}
+
+// findValues 执行数据查询并解码到值切片
+func (g *GormBuilder[R]) findValues(query *gorm.DB, values *[]R) error {
+	defer g.builder.observeDBCall(DBCallFind)()
+	return query.Find(values).Error
+}
+
+// doConsistentQuery 在同一快照事务中顺序执行数据查询和总数统计，保证总数与返回的数据一致
+func (g *GormBuilder[R]) doConsistentQuery(ctx context.Context) (list []*R, total int64, err error) {
+	db := g.builder.data.DB.WithContext(ctx)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if g.builder.skipData {
+			list = []*R{}
+		} else if err := g.findInTx(tx, &list); err != nil {
+			return err
+		}
+		if !g.builder.needTotal {
+			return nil
+		}
+		return g.countTotal(tx.WithContext(g.builder.countContext(ctx)), &total)
+	}, consistentReadTxOptions(db.Dialector.Name()))
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// findInTx 在事务中执行数据查询
+func (g *GormBuilder[R]) findInTx(tx *gorm.DB, list *[]*R) error {
+	*list = make([]*R, 0, g.builder.resultCapacityHint())
+	defer g.builder.observeDBCall(DBCallFind)()
+	return g.buildQuery(tx).Find(list).Error
+}
+
+// consistentReadTxOptions 返回按方言选择的快照读事务选项
+// PostgreSQL 与 MySQL 以只读的 REPEATABLE READ 事务读取快照；SQL Server 驱动不支持只读事务，仅设置 SNAPSHOT 隔离级别
+func consistentReadTxOptions(dialect string) *sql.TxOptions {
+	switch dialect {
+	case "postgres", "mysql":
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	case "sqlserver":
+		return &sql.TxOptions{Isolation: sql.LevelSnapshot}
+	default:
+		return &sql.TxOptions{}
+	}
+}
+
+// doShardedQuery 在各分片上并行执行相同的查询并合并结果（scatter-gather）
+// 每个分片拉取前 start+limit 条，合并后按 shardCompare 重新排序再截取全局分页窗口；总数为各分片总数之和
+func (g *GormBuilder[R]) doShardedQuery(ctx context.Context) (list []*R, total int64, err error) {
+	shards := g.builder.data.GormShards
+	if g.builder.needPagination && g.builder.limit == 0 {
+		g.builder.limit = defaultLimit
+	}
+	concurrency := g.shardConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultShardConcurrency
+	}
+	// 每个分片内数据查询与总数统计并行，配置 parallelism 时同步收紧分片并发度，使总并发不超过该值
+	if p := g.builder.parallelism; p > 0 {
+		concurrency = min(concurrency, max(1, p/2))
+	}
+
+	lists := make([][]*R, len(shards))
+	totals := make([]int64, len(shards))
+	if err = util.WaitAndGoN(len(shards), concurrency, func(i int) error {
+		db := shards[i].WithContext(ctx)
+		return g.builder.runParallel(func() error {
+			if g.builder.skipData {
+				return nil
+			}
+			query := g.buildUnpagedQuery(db)
+			if g.builder.needPagination {
+				query = query.Limit(int(g.builder.start + g.builder.pageFetchLimit()))
+			}
+			defer g.builder.observeDBCall(DBCallFind)()
+			return query.Find(&lists[i]).Error
+		}, func() error {
+			if !g.builder.needTotal || g.builder.totalOverride != nil {
+				return nil
+			}
+			return g.exactCount(shards[i].WithContext(g.builder.countContext(ctx)), &totals[i])
+		})
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	list = slices.Concat(lists...)
+	if list == nil {
+		list = []*R{}
+	}
+	if g.shardCompare != nil {
+		slices.SortStableFunc(list, g.shardCompare)
+	}
+	if g.builder.needPagination {
+		start := min(int(g.builder.start), len(list))
+		end := min(start+int(g.builder.pageFetchLimit()), len(list))
+		list = list[start:end]
+	}
+
+	for _, t := range totals {
+		total += t
+	}
+	return list, g.builder.capTotal(total), nil
+}
+
+// countDB 返回总数统计使用的 *gorm.DB，配置 SetCountProxy 时使用其中的 DB
+func (g *GormBuilder[R]) countDB(ctx context.Context) *gorm.DB {
+	return g.builder.countProxy().DB.WithContext(g.builder.countContext(ctx))
+}
+
+// countTotal 执行总数统计，配置 Counter 时交由其处理，否则执行 exactCount
+func (g *GormBuilder[R]) countTotal(db *gorm.DB, total *int64) (err error) {
+	*total, err = g.builder.countWith(db.Statement.Context, func(ctx context.Context) (int64, error) {
+		var exact int64
+		err := g.exactCount(db.WithContext(ctx), &exact)
+		return exact, err
+	})
+	return err
+}
+
+// exactCount 执行精确总数统计；配置 totalLimit 时通过子查询限制最多扫描的记录数。
+func (g *GormBuilder[R]) exactCount(db *gorm.DB, total *int64) error {
+	if len(g.countPartitions) > 0 && !g.hasCustomSource() {
+		return g.partitionedCount(db, total)
+	}
+	return g.countFrom(db, g.baseQuery(db), total)
+}
+
+// partitionedCount 并行统计各分区的总数并求和；配置 totalLimit 时各分区分别封顶，求和结果由调用方按 totalLimit 截断
+func (g *GormBuilder[R]) partitionedCount(db *gorm.DB, total *int64) error {
+	concurrency := defaultShardConcurrency
+	if p := g.builder.parallelism; p > 0 {
+		concurrency = min(concurrency, p)
+	}
+	counts := make([]int64, len(g.countPartitions))
+	if err := util.WaitAndGoN(len(counts), concurrency, func(i int) error {
+		return g.countFrom(db, partitionSource(g.baseQuery(db), g.countPartitions[i]), &counts[i])
+	}); err != nil {
+		return err
+	}
+
+	*total = 0
+	for _, count := range counts {
+		*total += count
+	}
+	return nil
+}
+
+// partitionSource 以分区表达式作为统计的数据源。GORM 无法从 "orders PARTITION (p1)" 这类表达式解析表名，
+// 软删除条件会引用 FROM 中不存在的模型表，此时以表达式的首个标识符作为表名；无法确定表名时关闭模型的软删除条件
+func partitionSource(query *gorm.DB, partition string) *gorm.DB {
+	query = query.Table(partition)
+	if query.Statement.Table != "" {
+		return query
+	}
+	if name, _, _ := strings.Cut(strings.TrimSpace(partition), " "); sqlIdentifierPattern.MatchString(name) {
+		query.Statement.Table = name
+		return query
+	}
+	return query.Unscoped()
+}
+
+// countFrom 在 query 指定的数据源上应用 joins、过滤条件与统计修饰后执行 COUNT
+func (g *GormBuilder[R]) countFrom(db, query *gorm.DB, total *int64) error {
+	if !g.countSkipJoins {
+		query = g.applyJoins(query)
+	}
+	query = g.applyFilter(query)
+	if len(g.countModifiers) > 0 {
+		query = query.Scopes(g.countModifiers...)
+	}
+	defer g.builder.observeDBCall(DBCallCount)()
 	if g.builder.totalLimit == 0 {
 		return query.Count(total).Error
 	}
 
-	subQuery := query.Select("1").Limit(int(g.builder.totalLimit))
-	return g.builder.data.DB.WithContext(ctx).
-		Table("(?) AS querybuilder_total_limit", subQuery).
+	subQuery := query.Select("1").Limit(int(g.builder.countProbeLimit()))
+	return db.Table("(?) AS querybuilder_total_limit", subQuery).
 		Count(total).Error
 }
 
@@ -281,6 +926,111 @@ func (g *GormBuilder[R]) Explain(ctx context.Context) (string, error) {
 	return sql, nil
 }
 
+// RedactedStatement 返回最终生成的 SQL（Dry Run 模式），绑定参数保留为占位符，
+// SQL 中内联的字符串与数值字面量替换为 ?，可作为链路 span 的 db.statement 属性而不泄露业务数据；
+// 若已配置游标字段，返回游标查询模式首批查询的 SQL
+func (g *GormBuilder[R]) RedactedStatement(ctx context.Context) (string, error) {
+	if err := g.builder.prepareAndValidate(); err != nil {
+		return "", err
+	}
+
+	dryRun := g.builder.data.DB.WithContext(ctx).Session(&gorm.Session{DryRun: true})
+	var query *gorm.DB
+	if len(g.builder.cursorFields) > 0 {
+		query = g.buildCursorQuery(dryRun)
+	} else {
+		query = g.buildQuery(dryRun)
+	}
+	stmt := query.Find(new([]R)).Statement
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+	return redactSQL(stmt.SQL.String()), nil
+}
+
+// sqlLiteralPattern 匹配 SQL 中的字符串字面量、PostgreSQL 风格占位符（$1）与数值字面量
+var sqlLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+
+// redactSQL 将 SQL 中的字符串与数值字面量替换为 ?，占位符保持不变
+func redactSQL(sql string) string {
+	return sqlLiteralPattern.ReplaceAllStringFunc(sql, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+}
+
+// ExplainPlan 对最终生成的 SQL 执行 EXPLAIN，返回数据库的查询执行计划（JSON 格式的结果行）
+// 与 Explain 不同，该方法会实际访问数据库，用于开发阶段排查缺失索引等问题；
+// 若已配置游标字段，返回游标查询模式首批查询的执行计划。
+// 需数据库支持 EXPLAIN 语法（MySQL、PostgreSQL、SQLite 等），DryRun 模式下返回 gorm.ErrDryRunModeUnsupported
+func (g *GormBuilder[R]) ExplainPlan(ctx context.Context) (string, error) {
+	if err := g.builder.prepareAndValidate(); err != nil {
+		return "", err
+	}
+
+	db := g.builder.data.DB.WithContext(ctx)
+	if db.DryRun {
+		return "", gorm.ErrDryRunModeUnsupported
+	}
+
+	dryRun := db.Session(&gorm.Session{DryRun: true})
+	var query *gorm.DB
+	if len(g.builder.cursorFields) > 0 {
+		query = g.buildCursorQuery(dryRun)
+	} else {
+		query = g.buildQuery(dryRun)
+	}
+	stmt := query.Find(new([]R)).Statement
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+
+	// 直接使用连接池执行，避免 Raw 对已绑定参数的 SQL 再次展开占位符
+	rows, err := stmt.ConnPool.QueryContext(ctx, "EXPLAIN "+stmt.SQL.String(), stmt.Vars...)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	plan := make([]map[string]any, 0)
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return "", err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+				continue
+			}
+			row[column] = values[i]
+		}
+		plan = append(plan, row)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // buildCursorBatchSize 获取游标查询的批次大小
 func (g *GormBuilder[R]) buildCursorBatchSize() int {
 	batchSize := int(g.builder.limit)
@@ -293,7 +1043,10 @@ func (g *GormBuilder[R]) buildCursorBatchSize() int {
 // buildCursorQuery 构建游标查询的公共 GORM 查询对象（不含游标条件）
 // 包含字段投影、用户 filter、游标字段排序、用户辅助排序、批次大小
 func (g *GormBuilder[R]) buildCursorQuery(db *gorm.DB) *gorm.DB {
-	query := db.Model(new(R))
+	query := g.applyJoins(g.baseQuery(db))
+	if len(g.clauses) > 0 {
+		query = query.Clauses(g.clauses...)
+	}
 
 	// 应用字段投影
 	if len(g.builder.fields) > 0 {
@@ -301,9 +1054,7 @@ func (g *GormBuilder[R]) buildCursorQuery(db *gorm.DB) *gorm.DB {
 	}
 
 	// 应用用户 filter 条件
-	if g.filter != nil {
-		query = query.Scopes(g.filter)
-	}
+	query = g.applyFilter(query)
 
 	// 游标字段排序为主（升序）
 	cursorFields := g.builder.getParsedCursorFields()
@@ -316,8 +1067,8 @@ func (g *GormBuilder[R]) buildCursorQuery(db *gorm.DB) *gorm.DB {
 	}
 
 	// 用户 sort 作为辅助排序
-	if g.sort != nil {
-		query = query.Scopes(g.sort)
+	if len(g.sort) > 0 {
+		query = query.Scopes(g.sort...)
 	}
 
 	// 设置批次大小
@@ -352,10 +1103,13 @@ func (g *GormBuilder[R]) explainCursor(ctx context.Context) (string, error) {
 }
 
 // doCursorQuery 执行 GORM 游标分页的单批次查询
-// 构建基于行值表达式的 SQL 游标条件
+// 游标条件由 gormCursorCondition 按排序方向与方言生成
 // probeHasMore 为 true 时，通过 limit+1 探测精确判断是否还有下一页
 // isFirstBatch 为 true 时，若 needTotal 也为 true，则并行执行 Count 查询
 func (g *GormBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any, isFirstBatch bool, probeHasMore bool) ([]*R, []any, int64, bool, error) {
+	if len(g.builder.data.GormShards) > 0 {
+		return nil, nil, 0, false, ErrShardedCursorUnsupported
+	}
 	batchSize := g.buildCursorBatchSize()
 
 	// 构建查询
@@ -367,52 +1121,14 @@ func (g *GormBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 
 	// 构建游标条件（仅在有游标值时添加）
 	if len(cursorValues) > 0 {
-		cursorFields := g.builder.getParsedCursorFields()
-		if len(cursorFields) == 1 {
-			op := ">"
-			if !cursorFields[0].Asc {
-				op = "<"
-			}
-			query = query.Where(fmt.Sprintf("%s %s ?", cursorFields[0].Field, op), cursorValues[0])
-		} else {
-			if asc, uniform := isUniformCursorDirection(cursorFields); uniform {
-				// 性能优化：方向一致时使用行值比较，通常比 OR 组合条件更利于索引与执行计划。
-				op := ">"
-				if !asc {
-					op = "<"
-				}
-				fieldList := make([]string, 0, len(cursorFields))
-				for _, cf := range cursorFields {
-					fieldList = append(fieldList, cf.Field)
-				}
-				placeholders := strings.TrimRight(strings.Repeat("?,", len(cursorValues)), ",")
-				query = query.Where(fmt.Sprintf("(%s) %s (%s)", strings.Join(fieldList, ", "), op, placeholders), cursorValues...)
-			} else {
-				// 混排场景（如 created_at DESC, id ASC）无法直接使用单一行值比较，回退到词典序 OR 条件。
-				var orParts []string
-				args := make([]any, 0, len(cursorFields)*(len(cursorFields)+1)/2)
-				for i := 0; i < len(cursorFields); i++ {
-					andParts := make([]string, 0, i+1)
-					for j := 0; j < i; j++ {
-						andParts = append(andParts, fmt.Sprintf("%s = ?", cursorFields[j].Field))
-						args = append(args, cursorValues[j])
-					}
-					op := ">"
-					if !cursorFields[i].Asc {
-						op = "<"
-					}
-					andParts = append(andParts, fmt.Sprintf("%s %s ?", cursorFields[i].Field, op))
-					args = append(args, cursorValues[i])
-					orParts = append(orParts, "("+strings.Join(andParts, " AND ")+")")
-				}
-				query = query.Where(strings.Join(orParts, " OR "), args...)
-			}
-		}
+		cond, args := gormCursorCondition(query.Dialector.Name(), g.builder.getParsedCursorFields(), cursorValues)
+		query = query.Where(cond, args...)
 	}
 
-	var list []*R
+	list := make([]*R, 0, batchSize+1)
 	var total int64
-	if err := util.WaitAndGo(func() error {
+	if err := g.builder.runParallel(func() error {
+		defer g.builder.observeDBCall(DBCallFind)()
 		return query.Find(&list).Error
 	}, func() error {
 		// 首批次且需要总数时，并行执行数据查询和 Count 查询
@@ -420,7 +1136,7 @@ func (g *GormBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 			return nil
 		}
 
-		return g.countTotal(ctx, &total)
+		return g.countTotal(g.countDB(ctx), &total)
 	}); err != nil {
 		return nil, nil, 0, false, err
 	}
@@ -456,3 +1172,53 @@ func (g *GormBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 
 	return list, nextCursorValues, total, hasMore, nil
 }
+
+// gormCursorCondition 生成游标分页的 keyset 条件，多字段时按词典序比较：
+//
+//	单字段:                     created_at > ?
+//	方向一致且方言支持行值比较: (created_at, id) > (?, ?)
+//	混排或方言不支持行值比较:   (created_at > ?) OR (created_at = ? AND id > ?)
+func gormCursorCondition(dialect string, fields []cursorSortField, values []any) (string, []any) {
+	if len(fields) == 1 {
+		return fmt.Sprintf("%s %s ?", fields[0].Field, cursorCompareOp(fields[0].Asc)), values[:1]
+	}
+
+	if asc, uniform := isUniformCursorDirection(fields); uniform && gormSupportsRowValues(dialect) {
+		// 性能优化：方向一致时使用行值比较，通常比 OR 组合条件更利于索引与执行计划。
+		fieldList := make([]string, 0, len(fields))
+		for _, cf := range fields {
+			fieldList = append(fieldList, cf.Field)
+		}
+		placeholders := strings.TrimRight(strings.Repeat("?,", len(values)), ",")
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(fieldList, ", "), cursorCompareOp(asc), placeholders), values
+	}
+
+	// 混排场景（如 created_at DESC, id ASC）无法直接使用单一行值比较，回退到词典序 OR 条件。
+	orParts := make([]string, 0, len(fields))
+	args := make([]any, 0, len(fields)*(len(fields)+1)/2)
+	for i := range fields {
+		andParts := make([]string, 0, i+1)
+		for j := range i {
+			andParts = append(andParts, fmt.Sprintf("%s = ?", fields[j].Field))
+			args = append(args, values[j])
+		}
+		andParts = append(andParts, fmt.Sprintf("%s %s ?", fields[i].Field, cursorCompareOp(fields[i].Asc)))
+		args = append(args, values[i])
+		orParts = append(orParts, "("+strings.Join(andParts, " AND ")+")")
+	}
+	return strings.Join(orParts, " OR "), args
+}
+
+// cursorCompareOp 返回游标条件的比较运算符，升序取下一条使用 >，降序使用 <
+func cursorCompareOp(asc bool) string {
+	if asc {
+		return ">"
+	}
+	return "<"
+}
+
+// gormSupportsRowValues 判断方言是否支持 (a, b) > (?, ?) 形式的行值比较
+// SQL Server 不支持行值比较，需展开为等价的 OR 条件
+func gormSupportsRowValues(dialect string) bool {
+	return dialect != "sqlserver"
+}
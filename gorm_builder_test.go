@@ -0,0 +1,1318 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils/tests"
+)
+
+type GormTestEntity struct {
+	ID        uint32 `gorm:"column:id"`
+	Name      string `gorm:"column:name"`
+	IsDeleted bool   `gorm:"column:is_deleted"`
+}
+
+// GormSoftDeleteEntity 使用 gorm.DeletedAt 软删除约定的测试实体
+type GormSoftDeleteEntity struct {
+	ID        uint32         `gorm:"column:id"`
+	Name      string         `gorm:"column:name"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+// sqlRecorder 记录 Dry Run 模式下生成的 SQL，用于断言构建结果
+type sqlRecorder struct {
+	mu   sync.Mutex
+	sqls []string
+}
+
+func (r *sqlRecorder) record(db *gorm.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sqls = append(r.sqls, db.Statement.SQL.String())
+}
+
+func (r *sqlRecorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sqls...)
+}
+
+// newDryRunGormProxy 创建 Dry Run 模式的 GORM 代理，并记录每条查询 SQL
+func newDryRunGormProxy(t *testing.T) (*DBProxy, *sqlRecorder) {
+	t.Helper()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	recorder := &sqlRecorder{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:record_sql", recorder.record); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+	return NewDBProxy(db, nil, nil), recorder
+}
+
+// TestGormBuilder_SoftDeleteAppliesToFindAndCount 测试软删除条件同时作用于数据查询与总数统计
+func TestGormBuilder_SoftDeleteAppliesToFindAndCount(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetSoftDelete("is_deleted", true)
+	b.SetNeedTotal(true)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "`is_deleted` <> ?") {
+			t.Errorf("expected soft delete condition in %q", sql)
+		}
+	}
+}
+
+// TestGormBuilder_SoftDeleteWithTotalLimit 测试有上限的总数统计同样应用软删除条件
+func TestGormBuilder_SoftDeleteWithTotalLimit(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetSoftDelete("deleted", 1)
+	b.SetNeedTotal(true)
+	b.SetTotalLimit(100)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var countSQL string
+	for _, sql := range recorder.all() {
+		if strings.Contains(sql, "querybuilder_total_limit") {
+			countSQL = sql
+		}
+	}
+	if !strings.Contains(countSQL, "`deleted` <> ?") {
+		t.Errorf("expected soft delete condition in bounded count, got %q", countSQL)
+	}
+}
+
+// TestGormBuilder_SoftDeleteExplain 测试 Explain 及游标模式输出包含软删除条件，并与用户 filter 组合
+func TestGormBuilder_SoftDeleteExplain(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetSoftDelete("is_deleted", true).SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	})
+
+	sql, err := b.Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "name = ?") || !strings.Contains(sql, "`is_deleted` <> ?") {
+		t.Errorf("expected filter and soft delete condition, got %q", sql)
+	}
+
+	b.SetCursorField("id")
+	sql, err = b.Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "`is_deleted` <> ?") {
+		t.Errorf("expected soft delete condition in cursor query, got %q", sql)
+	}
+}
+
+// TestGormBuilder_SoftDeleteGroupsOrFilter 测试含 Or 的用户过滤条件整体加括号，软删除条件不会被 Or 绕过
+func TestGormBuilder_SoftDeleteGroupsOrFilter(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetSoftDelete("is_deleted", true).SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice").Or("name = ?", "bob")
+	})
+	b.SetNeedTotal(true)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected count and find queries, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "WHERE (name = ? OR name = ?) AND `is_deleted` <> ?") {
+			t.Errorf("expected grouped filter before soft delete condition, got %q", sql)
+		}
+	}
+}
+
+// TestListQuery_WithSoftDeleteOption 测试 List 通过 QueryOption 配置软删除列
+func TestListQuery_WithSoftDeleteOption(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(),
+		WithData(proxy),
+		WithNeedTotal(false),
+		WithSoftDelete("is_deleted", true),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 1 || !strings.Contains(sqls[0], "`is_deleted` <> ?") {
+		t.Errorf("expected soft delete condition, got %v", sqls)
+	}
+}
+
+// namedDialector 覆盖 DummyDialector 的方言名称，用于验证方言相关的 SQL 生成
+type namedDialector struct {
+	tests.DummyDialector
+	name string
+}
+
+func (d namedDialector) Name() string { return d.name }
+
+// explainWithDialect 使用指定方言的 Dry Run 连接生成 Explain SQL
+func explainWithDialect(t *testing.T, dialect string, configure func(b *GormBuilder[GormTestEntity])) (string, error) {
+	t.Helper()
+	db, err := gorm.Open(namedDialector{name: dialect}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil))
+	configure(b)
+	return b.Explain(context.Background())
+}
+
+// TestGormJSONFilter_Dialects 测试 JSON 过滤在各方言下生成的表达式
+func TestGormJSONFilter_Dialects(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		path     string
+		value    any
+		expected string
+		args     string
+	}{
+		{dialect: "mysql", path: "profile.city", value: "Paris", expected: "JSON_EXTRACT(`attrs`, ?) = ?", args: "args: [$.profile.city, Paris]"},
+		{dialect: "sqlite", path: "city", value: "Paris", expected: "JSON_EXTRACT(`attrs`, ?) = ?", args: "args: [$.city, Paris]"},
+		{dialect: "postgres", path: "city", value: "Paris", expected: "`attrs` ->> ? = ?", args: "args: [city, Paris]"},
+		{dialect: "postgres", path: "profile.address.city", value: "Paris", expected: "`attrs` -> ? -> ? ->> ? = ?", args: "args: [profile, address, city, Paris]"},
+		{dialect: "postgres", path: "profile.age", value: 30, expected: "(`attrs` -> ? ->> ?)::numeric = ?", args: "args: [profile, age, 30]"},
+		{dialect: "postgres", path: "active", value: true, expected: "(`attrs` ->> ?)::boolean = ?", args: "args: [active, true]"},
+		{dialect: "sqlserver", path: "profile.city", value: "Paris", expected: "JSON_VALUE(`attrs`, ?) = ?", args: "args: [$.profile.city, Paris]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect+"/"+tt.path, func(t *testing.T) {
+			sql, err := explainWithDialect(t, tt.dialect, func(b *GormBuilder[GormTestEntity]) {
+				b.AddFilter(GormJSONFilter("attrs", tt.path, tt.value))
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.expected) || !strings.Contains(sql, tt.args) {
+				t.Errorf("expected %q with %q, got %q", tt.expected, tt.args, sql)
+			}
+		})
+	}
+}
+
+// TestGormJSONFilter_Errors 测试非法路径与不支持的方言
+func TestGormJSONFilter_Errors(t *testing.T) {
+	if _, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		b.AddFilter(GormJSONFilter("attrs", "profile..city", 1))
+	}); !errors.Is(err, ErrInvalidJSONPath) {
+		t.Errorf("expected ErrInvalidJSONPath, got %v", err)
+	}
+
+	if _, err := explainWithDialect(t, "clickhouse", func(b *GormBuilder[GormTestEntity]) {
+		b.AddFilter(GormJSONFilter("attrs", "city", 1))
+	}); err == nil {
+		t.Error("expected unsupported dialect error, got nil")
+	}
+}
+
+// TestGormBuilder_AddFilterCombinesWithSetFilter 测试 AddFilter 与 SetFilter 以 AND 组合并作用于总数统计
+func TestGormBuilder_AddFilterCombinesWithSetFilter(t *testing.T) {
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	}, nil))
+
+	recorder := &sqlRecorder{}
+	mysqlDB, err := gorm.Open(namedDialector{name: "mysql"}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	if err := mysqlDB.Callback().Query().After("gorm:query").Register("test:record_sql", recorder.record); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+	if _, err := list.Query(context.Background(),
+		WithData(NewDBProxy(mysqlDB, nil, nil)),
+		WithJSONFilter("attrs", "city", "Paris"),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "name = ?") || !strings.Contains(sql, "JSON_EXTRACT(`attrs`, ?) = ?") {
+			t.Errorf("expected both filters in %q", sql)
+		}
+	}
+}
+
+// TestGormBuilder_NeedDataFalse 测试跳过数据查询时仅执行总数统计并返回空列表
+func TestGormBuilder_NeedDataFalse(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetNeedData(false).SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	})
+	b.SetNeedTotal(true)
+
+	result, err := b.QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Items == nil || len(result.Items) != 0 {
+		t.Errorf("expected empty non-nil items, got %#v", result.Items)
+	}
+	if !b.GetQueryMeta().SkipData {
+		t.Error("expected SkipData in query meta")
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 1 || !strings.Contains(sqls[0], "count(*)") || !strings.Contains(sqls[0], "name = ?") {
+		t.Errorf("expected only filtered count statement, got %v", sqls)
+	}
+}
+
+// TestListQuery_WithNeedDataOption 测试 List 通过 WithNeedData 跳过数据查询
+func TestListQuery_WithNeedDataOption(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(proxy), WithNeedData(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 1 || !strings.Contains(sqls[0], "count(*)") {
+		t.Errorf("expected only count statement, got %v", sqls)
+	}
+
+	// 默认仍查询数据
+	if _, err := list.Query(context.Background(), WithData(proxy)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 3 {
+		t.Errorf("expected find and count statements on default query, got %v", sqls)
+	}
+}
+
+// TestGormBuilder_ExplainPlan 测试 ExplainPlan 在 DryRun 与未配置数据源时返回错误而不执行查询
+func TestGormBuilder_ExplainPlan(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	_, err := NewGormBuilder[GormTestEntity](proxy).ExplainPlan(context.Background())
+	if !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Errorf("expected gorm.ErrDryRunModeUnsupported, got %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 0 {
+		t.Errorf("expected no statement to be executed, got %v", sqls)
+	}
+
+	_, err = NewGormBuilder[GormTestEntity](NewDBProxy(nil, nil, nil)).ExplainPlan(context.Background())
+	if !errors.Is(err, ErrDataNotConfigured) {
+		t.Errorf("expected ErrDataNotConfigured, got %v", err)
+	}
+}
+
+// TestGormBuilder_MultipleSortScopes 测试多个排序作用域按传入顺序应用，nil 被忽略
+func TestGormBuilder_MultipleSortScopes(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetSort(
+		func(db *gorm.DB) *gorm.DB { return db.Order("score DESC") },
+		nil,
+		func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") },
+	)
+	if len(b.sort) != 2 {
+		t.Fatalf("expected nil sort scope to be ignored, got %d scopes", len(b.sort))
+	}
+
+	cloned := b.Clone()
+	cloned.SetSort()
+	if len(b.sort) != 2 || len(cloned.sort) != 0 {
+		t.Errorf("expected clone sort isolation, original %d, cloned %d", len(b.sort), len(cloned.sort))
+	}
+
+	sql, err := b.Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "ORDER BY score DESC,created_at DESC") {
+		t.Errorf("expected sort scopes applied in order, got %s", sql)
+	}
+}
+
+// TestGormBuilder_ResultCapacity 测试结果切片按分页 limit 或显式容量提示预分配
+func TestGormBuilder_ResultCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(b *GormBuilder[GormTestEntity])
+		expected  int
+	}{
+		{
+			name: "按分页 limit 预分配",
+			configure: func(b *GormBuilder[GormTestEntity]) {
+				b.SetNeedPagination(true).SetLimit(25)
+			},
+			expected: 25,
+		},
+		{
+			name: "未分页时使用容量提示",
+			configure: func(b *GormBuilder[GormTestEntity]) {
+				b.SetResultCapacity(500)
+			},
+			expected: 500,
+		},
+		{
+			name:      "未分页且无提示时不预分配",
+			configure: func(b *GormBuilder[GormTestEntity]) {},
+			expected:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, _ := newDryRunGormProxy(t)
+			b := NewGormBuilder[GormTestEntity](proxy)
+			tt.configure(b)
+
+			result, err := b.QueryList(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cap(result.Items) != tt.expected {
+				t.Errorf("expected capacity %d, got %d", tt.expected, cap(result.Items))
+			}
+		})
+	}
+}
+
+// TestGormBuilder_FromSubquery 测试子查询数据源同时作用于数据查询与总数统计
+func TestGormBuilder_FromSubquery(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	sub := proxy.DB.Table("orders").Select("user_id, SUM(amount) AS total").Group("user_id")
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetFromSubquery(sub, "t").
+		SetFilter(func(db *gorm.DB) *gorm.DB {
+			return db.Where("t.total > ?", 100)
+		})
+	b.SetNeedTotal(true)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 子查询构建时同样会触发 Query 回调，仅检查外层语句
+	var sqls []string
+	for _, sql := range recorder.all() {
+		if strings.HasPrefix(sql, "SELECT count(*) FROM (") || strings.HasPrefix(sql, "SELECT * FROM (") {
+			sqls = append(sqls, sql)
+		}
+	}
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "FROM (SELECT user_id, SUM(amount) AS total FROM `orders` GROUP BY `user_id`) AS `t` WHERE t.total > ?") {
+			t.Errorf("expected subquery source with filter, got %s", sql)
+		}
+	}
+
+	for _, alias := range []string{"", "t WHERE 1=1 --", "t(id)"} {
+		_, err := NewGormBuilder[GormTestEntity](proxy).SetFromSubquery(sub, alias).Explain(context.Background())
+		if !errors.Is(err, ErrInvalidSubqueryAlias) {
+			t.Errorf("alias %q: expected ErrInvalidSubqueryAlias, got %v", alias, err)
+		}
+	}
+}
+
+// TestGormBuilder_FromSubquerySoftDeleteModel 测试实体使用 gorm.DeletedAt 时，外层查询不追加引用模型表的软删除条件
+func TestGormBuilder_FromSubquerySoftDeleteModel(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+
+	sub := proxy.DB.Table("orders").Select("id, name")
+	sql, err := NewGormBuilder[GormSoftDeleteEntity](proxy).SetFromSubquery(sub, "t").Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(sql, "deleted_at") || !strings.Contains(sql, "FROM (SELECT id, name FROM `orders`) AS `t`") {
+		t.Errorf("expected subquery source without model soft delete condition, got %q", sql)
+	}
+}
+
+// TestGormBuilder_FromFunction 测试表值函数数据源的参数绑定，并同时作用于数据查询与总数统计
+func TestGormBuilder_FromFunction(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetFromFunction("unnest(?::int[])", "t(id)", "{1,2,3}").
+		SetFilter(func(db *gorm.DB) *gorm.DB {
+			return db.Where("t.id > ?", 1)
+		})
+	b.SetNeedTotal(true)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "FROM unnest(?::int[]) AS `t`(`id`) WHERE t.id > ?") {
+			t.Errorf("expected function source with filter, got %s", sql)
+		}
+	}
+
+	explain, err := NewGormBuilder[GormTestEntity](proxy).SetFromFunction("generate_series(?, ?)", "g", 1, 10).Explain(context.Background())
+	if err != nil || !strings.Contains(explain, "FROM generate_series(?, ?) AS `g`") || !strings.HasSuffix(explain, "args: [1, 10]") {
+		t.Errorf("expected parameterized function source, got %q, %v", explain, err)
+	}
+
+	for _, alias := range []string{"", "t(id", "t(id; DROP TABLE x)", "t WHERE 1=1 --"} {
+		_, err = NewGormBuilder[GormTestEntity](proxy).SetFromFunction("unnest(?)", alias, []int{1}).Explain(context.Background())
+		if !errors.Is(err, ErrInvalidSubqueryAlias) {
+			t.Errorf("alias %q: expected ErrInvalidSubqueryAlias, got %v", alias, err)
+		}
+	}
+
+	// 函数结果中不包含模型表，不追加 gorm.DeletedAt 软删除条件
+	explain, err = NewGormBuilder[GormSoftDeleteEntity](proxy).SetFromFunction("search_items(?)", "s(id, name)", "x").Explain(context.Background())
+	if err != nil || strings.Contains(explain, "deleted_at") || !strings.Contains(explain, "FROM search_items(?) AS `s`(`id`, `name`)") {
+		t.Errorf("expected function source without model soft delete condition, got %q, %v", explain, err)
+	}
+}
+
+// TestGormBuilder_RawQuery 测试原生 SQL 作为子查询数据源，支持命名参数并参与分页与总数统计
+func TestGormBuilder_RawQuery(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetRawQuery("SELECT id, name FROM gorm_test_entities WHERE status = @status", sql.Named("status", 1)).
+		SetFilter(func(db *gorm.DB) *gorm.DB {
+			return db.Where("raw_query.id > ?", 10)
+		})
+	b.SetNeedTotal(true).SetNeedPagination(true).SetLimit(5)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	const source = "FROM (SELECT id, name FROM gorm_test_entities WHERE status = ?) AS raw_query WHERE raw_query.id > ?"
+	for _, stmt := range sqls {
+		if !strings.Contains(stmt, source) {
+			t.Errorf("expected raw query source with filter, got %s", stmt)
+		}
+	}
+	if !slices.ContainsFunc(sqls, func(stmt string) bool { return strings.HasSuffix(stmt, "LIMIT ?") }) {
+		t.Errorf("expected paginated find statement, got %v", sqls)
+	}
+
+	explain, err := NewGormBuilder[GormTestEntity](proxy).
+		SetRawQuery("SELECT * FROM gorm_test_entities WHERE id IN @ids", sql.Named("ids", []int{1, 2})).
+		Explain(context.Background())
+	if err != nil || !strings.Contains(explain, "WHERE id IN (?,?)) AS raw_query") {
+		t.Errorf("expected named slice parameter expanded, got %q, %v", explain, err)
+	}
+
+	// 原生 SQL 结果中不包含模型表，不追加 gorm.DeletedAt 软删除条件
+	explain, err = NewGormBuilder[GormSoftDeleteEntity](proxy).
+		SetRawQuery("SELECT id, name FROM gorm_soft_delete_entities WHERE deleted_at IS NULL AND name <> ?", "x").
+		Explain(context.Background())
+	if err != nil || strings.Contains(explain, "raw_query`.`deleted_at") || strings.Contains(explain, "gorm_soft_delete_entities`.`deleted_at") {
+		t.Errorf("expected raw query source without model soft delete condition, got %q, %v", explain, err)
+	}
+}
+
+// TestGormBuilder_CustomSourcePaginationBound 测试原生 SQL 与子查询数据源的分页值及总数上限均以绑定参数传递，不内联到 SQL
+func TestGormBuilder_CustomSourcePaginationBound(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	sources := map[string]func(b *GormBuilder[GormTestEntity]){
+		"raw": func(b *GormBuilder[GormTestEntity]) {
+			b.SetRawQuery("SELECT id, name FROM gorm_test_entities WHERE status = ?", 1)
+		},
+		"subquery": func(b *GormBuilder[GormTestEntity]) {
+			b.SetFromSubquery(proxy.DB.Model(&GormTestEntity{}).Where("status = ?", 1), "sub")
+		},
+	}
+	for name, configure := range sources {
+		t.Run(name, func(t *testing.T) {
+			db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+			if err != nil {
+				t.Fatalf("open dry run db failed: %v", err)
+			}
+			type statement struct {
+				sql  string
+				vars []any
+			}
+			var mu sync.Mutex
+			var stmts []statement
+			if err := db.Callback().Query().After("gorm:query").Register("test:record_vars", func(db *gorm.DB) {
+				// 总数上限子查询构建时同样会以 Dry Run 模式触发查询回调，不是实际执行的语句
+				if strings.HasPrefix(db.Statement.SQL.String(), "SELECT 1 FROM") {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				stmts = append(stmts, statement{sql: db.Statement.SQL.String(), vars: slices.Clone(db.Statement.Vars)})
+			}); err != nil {
+				t.Fatalf("register callback failed: %v", err)
+			}
+
+			b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil))
+			configure(b)
+			b.SetStart(20).SetLimit(10).SetTotalLimit(1000).SetNeedTotal(true).SetNeedPagination(true)
+			if _, err := b.QueryList(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(stmts) != 2 {
+				t.Fatalf("expected find and count statements, got %v", stmts)
+			}
+			for _, stmt := range stmts {
+				for _, literal := range []string{"LIMIT 10", "OFFSET 20", "LIMIT 1000"} {
+					if strings.Contains(stmt.sql, literal) {
+						t.Errorf("expected bound pagination, got inlined %q in %s", literal, stmt.sql)
+					}
+				}
+				switch {
+				case strings.HasSuffix(stmt.sql, "LIMIT ? OFFSET ?"):
+					if got := stmt.vars[len(stmt.vars)-2:]; got[0] != 10 || got[1] != 20 {
+						t.Errorf("expected limit and offset vars [10 20], got %v", got)
+					}
+				case strings.Contains(stmt.sql, "LIMIT ?) AS querybuilder_total_limit"):
+					if got := stmt.vars[len(stmt.vars)-1]; got != 1001 {
+						t.Errorf("expected total limit probe var 1001, got %v", got)
+					}
+				default:
+					t.Errorf("unexpected statement %s", stmt.sql)
+				}
+			}
+		})
+	}
+}
+
+// TestGormBuilder_ConnTag 测试按标签选择连接执行查询，标签不存在时返回 ErrConnTagNotFound
+func TestGormBuilder_ConnTag(t *testing.T) {
+	proxy, primary := newDryRunGormProxy(t)
+	reportingProxy, reporting := newDryRunGormProxy(t)
+	proxy.GormConns = map[string]*gorm.DB{"reporting": reportingProxy.DB}
+
+	b := NewGormBuilder[GormTestEntity](proxy).SetConnTag("reporting")
+	b.SetNeedTotal(true)
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := reporting.all(); len(got) != 2 {
+		t.Errorf("expected find and count on tagged connection, got %v", got)
+	}
+	if got := primary.all(); len(got) != 0 {
+		t.Errorf("expected primary connection unused, got %v", got)
+	}
+
+	if _, err := NewGormBuilder[GormTestEntity](proxy).QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := primary.all(); len(got) != 1 {
+		t.Errorf("expected untagged query on primary connection, got %v", got)
+	}
+
+	_, err := NewGormBuilder[GormTestEntity](proxy).SetConnTag("archive").QueryList(context.Background())
+	if !errors.Is(err, ErrConnTagNotFound) {
+		t.Errorf("expected ErrConnTagNotFound, got %v", err)
+	}
+}
+
+// TestGormBuilder_Joins 测试 joins 在 filter 之前应用，并可配置是否作用于总数统计
+func TestGormBuilder_Joins(t *testing.T) {
+	const joinSQL = "LEFT JOIN profiles ON profiles.user_id = gorm_test_entities.id AND profiles.kind = ?"
+
+	for _, withJoins := range []bool{true, false} {
+		proxy, recorder := newDryRunGormProxy(t)
+		b := NewGormBuilder[GormTestEntity](proxy)
+		b.AddJoin(joinSQL, "primary").
+			SetCountWithJoins(withJoins).
+			SetFilter(func(db *gorm.DB) *gorm.DB {
+				return db.Where("profiles.city = ?", "Shanghai")
+			})
+		b.SetNeedTotal(true)
+
+		if _, err := b.QueryList(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		sqls := recorder.all()
+		if len(sqls) != 2 {
+			t.Fatalf("expected find and count statements, got %v", sqls)
+		}
+		for _, sql := range sqls {
+			isCount := strings.HasPrefix(sql, "SELECT count(*)")
+			wantJoin := !isCount || withJoins
+			if strings.Contains(sql, joinSQL+" WHERE profiles.city = ?") != wantJoin {
+				t.Errorf("withJoins=%v: unexpected join presence in %s", withJoins, sql)
+			}
+		}
+	}
+}
+
+// TestGormBuilder_ListResultTotalAndPagination 测试列表结果区分"未统计总数"与"总数为 0"，并携带分页信息
+func TestGormBuilder_ListResultTotalAndPagination(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+
+	result, err := NewGormBuilder[GormTestEntity](proxy).QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HasTotal || result.Pagination != nil {
+		t.Errorf("expected no total and no pagination, got HasTotal=%v Pagination=%v", result.HasTotal, result.Pagination)
+	}
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetNeedTotal(true).SetNeedPagination(true).SetStart(20)
+	result, err = b.QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.HasTotal || result.Total != 0 {
+		t.Errorf("expected counted zero total, got HasTotal=%v Total=%d", result.HasTotal, result.Total)
+	}
+	if result.Pagination == nil || *result.Pagination != (core.Pagination{Start: 20, Limit: defaultLimit}) {
+		t.Errorf("expected pagination {20 %d}, got %v", defaultLimit, result.Pagination)
+	}
+}
+
+// TestGormBuilder_Clauses 测试透传子句作用于数据查询，不作用于总数统计
+func TestGormBuilder_Clauses(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.AddClauses(clause.Locking{Strength: clause.LockingStrengthUpdate}, nil)
+	b.SetNeedTotal(true)
+	if len(b.clauses) != 1 {
+		t.Fatalf("expected nil clause to be ignored, got %d clauses", len(b.clauses))
+	}
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		isCount := strings.HasPrefix(sql, "SELECT count(*)")
+		if strings.HasSuffix(sql, "FOR UPDATE") == isCount {
+			t.Errorf("expected FOR UPDATE only on the data query, got %s", sql)
+		}
+	}
+}
+
+// newFakeShard 创建返回固定数据的 Dry Run 分片，同时记录每个分片收到的 SQL
+func newFakeShard(t *testing.T, count int64, ids ...uint32) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	recorder := &sqlRecorder{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:fake_rows", func(db *gorm.DB) {
+		recorder.record(db)
+		switch dest := db.Statement.Dest.(type) {
+		case *[]*GormTestEntity:
+			for _, id := range ids {
+				*dest = append(*dest, &GormTestEntity{ID: id})
+			}
+		case *[]GormTestEntity:
+			for _, id := range ids {
+				*dest = append(*dest, GormTestEntity{ID: id})
+			}
+		case *int64:
+			*dest = count
+			db.RowsAffected = 1
+		}
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+	return db, recorder
+}
+
+// TestGormBuilder_ShardedQuery 测试分片查询的并行执行、合并排序、全局分页与总数累加
+func TestGormBuilder_ShardedQuery(t *testing.T) {
+	shard0, recorder0 := newFakeShard(t, 3, 1, 4, 7)
+	shard1, _ := newFakeShard(t, 2, 2, 5)
+	shard2, _ := newFakeShard(t, 4, 3, 6, 8, 9)
+
+	b := NewGormBuilder[GormTestEntity](NewShardedDBProxy(shard0, shard1, shard2))
+	b.SetShardCompare(func(a, b *GormTestEntity) int {
+		return int(a.ID) - int(b.ID)
+	}).SetShardConcurrency(2)
+	b.SetSort(func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	b.SetStart(2).SetLimit(3).SetNeedTotal(true).SetNeedPagination(true)
+
+	result, err := b.QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []uint32
+	for _, item := range result.Items {
+		ids = append(ids, item.ID)
+	}
+	if !slices.Equal(ids, []uint32{3, 4, 5}) {
+		t.Errorf("expected merged page [3 4 5], got %v", ids)
+	}
+	if result.Total != 9 {
+		t.Errorf("expected summed total 9, got %d", result.Total)
+	}
+
+	// 每个分片拉取 start+limit 条且不带 OFFSET
+	for _, sql := range recorder0.all() {
+		if strings.HasPrefix(sql, "SELECT * ") && (!strings.Contains(sql, "LIMIT ?") || strings.Contains(sql, "OFFSET")) {
+			t.Errorf("expected shard query without offset, got %s", sql)
+		}
+	}
+
+	// 游标查询不支持分片
+	if _, err := b.SetCursorField("id").QueryPage(context.Background()); !errors.Is(err, ErrShardedCursorUnsupported) {
+		t.Errorf("expected ErrShardedCursorUnsupported, got %v", err)
+	}
+}
+
+// TestGormBuilder_CountModifier 测试总数统计修饰仅作用于 Count 查询
+func TestGormBuilder_CountModifier(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	forceIndex := func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.Expr{SQL: "/* force_index */"})
+	}
+	if _, err := list.Query(context.Background(), WithData(proxy), WithCountModifier(forceIndex)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		isCount := strings.HasPrefix(sql, "SELECT count(*)")
+		if strings.Contains(sql, "force_index") != isCount {
+			t.Errorf("expected modifier only on the count query, got %s", sql)
+		}
+	}
+}
+
+// TestGormBuilder_TotalCapped 测试总数统计超出 totalLimit 上限时截断并标记 TotalCapped，恰好等于上限或覆盖总数时不标记
+func TestGormBuilder_TotalCapped(t *testing.T) {
+	testCases := []struct {
+		count      int64
+		totalLimit uint32
+		override   bool
+		total      int64
+		expected   bool
+	}{
+		{count: 1001, totalLimit: 1000, total: 1000, expected: true},
+		{count: 1000, totalLimit: 1000, total: 1000, expected: false},
+		{count: 999, totalLimit: 1000, total: 999, expected: false},
+		{count: 5000, totalLimit: 0, total: 5000, expected: false},
+		{count: 1001, totalLimit: 1000, override: true, total: 1000, expected: false},
+	}
+	for _, tc := range testCases {
+		db, recorder := newFakeShard(t, tc.count)
+		b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil))
+		b.SetTotalLimit(tc.totalLimit).SetNeedTotal(true)
+		if tc.override {
+			b.SetTotalOverride(tc.total)
+		}
+		result, err := b.QueryList(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Total != tc.total || result.TotalCapped != tc.expected {
+			t.Errorf("count=%d limit=%d: expected total=%d capped=%v, got total=%d capped=%v",
+				tc.count, tc.totalLimit, tc.total, tc.expected, result.Total, result.TotalCapped)
+		}
+		if tc.override {
+			continue
+		}
+		if tc.totalLimit > 0 {
+			var found bool
+			for _, sql := range recorder.all() {
+				found = found || strings.Contains(sql, "AS querybuilder_total_limit")
+			}
+			if !found {
+				t.Errorf("expected limited subquery count, got %v", recorder.all())
+			}
+		}
+	}
+}
+
+// TestGormBuilder_CountContext 测试总数统计使用独立的 ctx，数据查询仍使用查询 ctx
+func TestGormBuilder_CountContext(t *testing.T) {
+	type ctxKey struct{}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	var mu sync.Mutex
+	seen := map[string]any{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:record_ctx", func(db *gorm.DB) {
+		kind := "find"
+		if _, ok := db.Statement.Dest.(*int64); ok {
+			kind = "count"
+		}
+		mu.Lock()
+		seen[kind] = db.Statement.Context.Value(ctxKey{})
+		mu.Unlock()
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "query")
+	countCtx := context.WithValue(context.Background(), ctxKey{}, "count")
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithCountContext(countCtx)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen["find"] != "query" || seen["count"] != "count" {
+		t.Errorf("expected find on query ctx and count on count ctx, got %v", seen)
+	}
+
+	// Clone 保留总数统计 ctx
+	b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil))
+	b.SetCountContext(countCtx)
+	if cloned := b.Clone(); cloned.builder.countContext(ctx) != countCtx {
+		t.Error("expected clone to keep the count context")
+	}
+}
+
+// TestGormBuilder_StableSort 测试稳定排序在排序未包含主键时追加到末尾
+func TestGormBuilder_StableSort(t *testing.T) {
+	testCases := []struct {
+		sort     string
+		expected string
+	}{
+		{sort: "", expected: "ORDER BY `id`"},
+		{sort: "created_at DESC", expected: "ORDER BY created_at DESC,`id`"},
+		{sort: "created_at DESC, `t`.`id` DESC", expected: "ORDER BY created_at DESC, `t`.`id` DESC"},
+	}
+	for _, tc := range testCases {
+		sql, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+			if tc.sort != "" {
+				b.SetSort(func(db *gorm.DB) *gorm.DB { return db.Order(tc.sort) })
+			}
+			b.SetStableSort("id")
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(sql, tc.expected) {
+			t.Errorf("sort %q: expected SQL ending with %q, got %s", tc.sort, tc.expected, sql)
+		}
+	}
+}
+
+// gormReportRow 不对应任何 GORM 模型表的报表行
+type gormReportRow struct {
+	Day   string
+	Total int64
+}
+
+// TestGormBuilder_RawScan 测试直接从指定表查询，数据查询与总数统计均不再按模型推导表名
+func TestGormBuilder_RawScan(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[gormReportRow]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(proxy), WithNeedTotal(true), WithRawScan("daily_reports")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "FROM `daily_reports`") {
+			t.Errorf("expected raw table in %q", sql)
+		}
+	}
+
+	query, err := NewGormBuilder[gormReportRow](proxy).SetRawScan("daily_reports").BuildQuery(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Statement.Model != nil {
+		t.Errorf("expected no model on raw scan query, got %T", query.Statement.Model)
+	}
+}
+
+// TestGormCursorCondition 测试多字段游标条件按排序方向与方言生成行值比较或展开的 OR 条件
+func TestGormCursorCondition(t *testing.T) {
+	asc := parseCursorSortFields([]string{"created_at", "id"})
+	mixed := parseCursorSortFields([]string{"-created_at", "id"})
+	tests := []struct {
+		name     string
+		dialect  string
+		fields   []cursorSortField
+		expected string
+		args     []any
+	}{
+		{name: "单字段", dialect: "mysql", fields: parseCursorSortFields([]string{"-id"}), expected: "id < ?", args: []any{"t"}},
+		{name: "行值比较", dialect: "postgres", fields: asc, expected: "(created_at, id) > (?,?)", args: []any{"t", 5}},
+		{name: "SQL Server 展开", dialect: "sqlserver", fields: asc, expected: "(created_at > ?) OR (created_at = ? AND id > ?)", args: []any{"t", "t", 5}},
+		{name: "混排展开", dialect: "mysql", fields: mixed, expected: "(created_at < ?) OR (created_at = ? AND id > ?)", args: []any{"t", "t", 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args := gormCursorCondition(tt.dialect, tt.fields, []any{"t", 5}[:len(tt.fields)])
+			if cond != tt.expected || !slices.Equal(args, tt.args) {
+				t.Errorf("expected %q %v, got %q %v", tt.expected, tt.args, cond, args)
+			}
+		})
+	}
+}
+
+// txConn 记录事务隔离级别的 database/sql 连接，查询本身由 Dry Run 跳过
+type txConn struct {
+	countingConn
+	isolation *driver.IsolationLevel
+	committed *bool
+	readOnly  bool
+}
+
+func (c *txConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	*c.isolation = opts.Isolation
+	c.readOnly = opts.ReadOnly
+	return c, nil
+}
+
+func (c *txConn) Commit() error {
+	*c.committed = true
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	return nil
+}
+
+// txConnector 将 txConn 适配为 driver.Connector
+type txConnector struct {
+	conn *txConn
+}
+
+func (c *txConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c *txConnector) Driver() driver.Driver {
+	return &countingDriver{}
+}
+
+// TestGormBuilder_ConsistentRead 测试一致性读在同一 REPEATABLE READ 事务中顺序执行数据查询与总数统计
+func TestGormBuilder_ConsistentRead(t *testing.T) {
+	var isolation driver.IsolationLevel
+	var committed bool
+	conn := &txConn{isolation: &isolation, committed: &committed}
+	sqlDB := sql.OpenDB(&txConnector{conn: conn})
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	db, err := gorm.Open(namedDialector{name: "postgres"}, &gorm.Config{ConnPool: sqlDB, DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open gorm failed: %v", err)
+	}
+	recorder := &sqlRecorder{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:record_sql", recorder.record); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(NewDBProxy(db, nil, nil)), WithConsistentRead()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if isolation != driver.IsolationLevel(sql.LevelRepeatableRead) || !conn.readOnly || !committed {
+		t.Errorf("expected a committed read-only REPEATABLE READ transaction, got isolation %d read-only %v committed %v", isolation, conn.readOnly, committed)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 || !strings.Contains(sqls[0], "LIMIT") || !strings.Contains(sqls[1], "count(*)") {
+		t.Errorf("expected find then count in the transaction, got %v", sqls)
+	}
+
+	for dialect, expected := range map[string]sql.IsolationLevel{
+		"mysql":     sql.LevelRepeatableRead,
+		"sqlserver": sql.LevelSnapshot,
+		"sqlite":    sql.LevelDefault,
+	} {
+		opts := consistentReadTxOptions(dialect)
+		if opts.Isolation != expected || opts.ReadOnly != (dialect == "mysql") {
+			t.Errorf("%s: expected %v, got %+v", dialect, expected, opts)
+		}
+	}
+}
+
+// TestGormBuilder_Parallelism 测试 parallelism 限制分片查询的总并发（含每个分片内的数据查询与总数统计）
+func TestGormBuilder_Parallelism(t *testing.T) {
+	var inflight, peak atomic.Int32
+	track := func(*gorm.DB) {
+		n := inflight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inflight.Add(-1)
+	}
+
+	for _, parallelism := range []int{1, 3} {
+		peak.Store(0)
+		shards := make([]*gorm.DB, 4)
+		for i := range shards {
+			shards[i], _ = newFakeShard(t, 1, uint32(i+1))
+			if err := shards[i].Callback().Query().Before("gorm:query").Register("test:inflight", track); err != nil {
+				t.Fatalf("register callback failed: %v", err)
+			}
+		}
+
+		list := NewList[GormTestEntity]()
+		list.SetDataSource(Gorm)
+		result, err := list.Query(context.Background(), WithData(NewShardedDBProxy(shards...)), WithParallelism(parallelism))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Items) != 4 || result.Total != 4 {
+			t.Errorf("expected 4 items and total 4, got %d, %d", len(result.Items), result.Total)
+		}
+		if got := peak.Load(); got > int32(parallelism) {
+			t.Errorf("parallelism %d: expected at most %d concurrent calls, got %d", parallelism, parallelism, got)
+		}
+	}
+}
+
+// TestGormBuilder_RedactedStatement 测试脱敏 SQL 保留占位符并替换内联字面量
+func TestGormBuilder_RedactedStatement(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = 'alice' AND age > ?", 18)
+	})
+	b.SetLimit(10)
+
+	statement, err := b.RedactedStatement(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(statement, "alice") || strings.Contains(statement, "18") || strings.Contains(statement, "10") {
+		t.Errorf("expected literals to be redacted, got %s", statement)
+	}
+	if !strings.Contains(statement, "name = ? AND age > ?") {
+		t.Errorf("expected placeholders to be kept, got %s", statement)
+	}
+
+	if got := redactSQL(`SELECT * FROM "t_2024" WHERE a = $1 AND b = 'it''s' AND c = 1.5`); got != `SELECT * FROM "t_2024" WHERE a = $1 AND b = ? AND c = ?` {
+		t.Errorf("unexpected redacted sql: %s", got)
+	}
+}
+
+// TestGormBuilder_PartitionedCount 测试按分区并行统计总数：每个分区带相同过滤条件分别统计并求和，totalLimit 在求和后截断
+func TestGormBuilder_PartitionedCount(t *testing.T) {
+	db, recorder := newFakeShard(t, 5, 1, 2)
+	partitions := []string{"orders_2024_01", "orders_2024_02", "orders_2024_03"}
+
+	b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil))
+	b.SetPartitionedCount(partitions...).SetParallelism(2)
+	b.SetFilter(func(db *gorm.DB) *gorm.DB { return db.Where("name = ?", "a") })
+	b.SetNeedTotal(true)
+
+	result, err := b.QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Total != 15 {
+		t.Errorf("expected total summed over partitions = 15, got %d", result.Total)
+	}
+	if len(result.Items) != 2 {
+		t.Errorf("expected data query on the whole table, got %d rows", len(result.Items))
+	}
+	for _, partition := range partitions {
+		var found bool
+		for _, sql := range recorder.all() {
+			if strings.HasPrefix(sql, "SELECT count(*)") && strings.Contains(sql, partition) {
+				found = strings.Contains(sql, "name = ?")
+			}
+		}
+		if !found {
+			t.Errorf("expected filtered count on partition %s, got %v", partition, recorder.all())
+		}
+	}
+
+	b.SetTotalLimit(12)
+	result, err = b.QueryList(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Total != 12 {
+		t.Errorf("expected total capped at 12, got %d", result.Total)
+	}
+}
+
+// TestGormBuilder_PartitionedCountSoftDelete 测试分区表达式上的统计软删除条件引用 FROM 中的表，而不是模型表
+func TestGormBuilder_PartitionedCountSoftDelete(t *testing.T) {
+	db, recorder := newFakeShard(t, 5, 1)
+	b := NewGormBuilder[GormSoftDeleteEntity](NewDBProxy(db, nil, nil))
+	b.SetPartitionedCount("orders PARTITION (p202401)", "orders_2024_02", "`sales`.orders PARTITION (p202403)").SetNeedTotal(true)
+
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]bool{
+		"SELECT count(*) FROM orders PARTITION (p202401) WHERE `orders`.`deleted_at` IS NULL": false,
+		"SELECT count(*) FROM `orders_2024_02` WHERE `orders_2024_02`.`deleted_at` IS NULL":   false,
+		"SELECT count(*) FROM `sales`.orders PARTITION (p202403)":                             false,
+	}
+	for _, sql := range recorder.all() {
+		if _, ok := expected[sql]; ok {
+			expected[sql] = true
+		}
+	}
+	for sql, found := range expected {
+		if !found {
+			t.Errorf("expected count statement %q, got %v", sql, recorder.all())
+		}
+	}
+}
+
+// MySQLError 模拟 go-sql-driver/mysql 的错误类型，仅以 Number 字段暴露错误码
+type MySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *MySQLError) Error() string { return e.Message }
+
+// sqlStateError 模拟 pgx / lib/pq 暴露 SQLState 的错误类型
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pg error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// mssqlError 模拟 go-mssqldb 暴露 SQLErrorNumber 的错误类型
+type mssqlError int32
+
+func (e mssqlError) Error() string         { return "mssql error" }
+func (e mssqlError) SQLErrorNumber() int32 { return int32(e) }
+
+// TestIsDeadlockError 测试按各驱动错误码识别死锁，包装后的错误同样可识别
+func TestIsDeadlockError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "mysql deadlock", err: &MySQLError{Number: 1213}, want: true},
+		{name: "mysql lock wait timeout", err: &MySQLError{Number: 1205}, want: false},
+		{name: "postgres deadlock wrapped", err: fmt.Errorf("query: %w", sqlStateError("40P01")), want: true},
+		{name: "postgres serialization failure", err: sqlStateError("40001"), want: false},
+		{name: "sql server deadlock", err: mssqlError(1205), want: true},
+		{name: "plain error", err: errors.New("deadlock"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDeadlockError(tt.err); got != tt.want {
+				t.Errorf("IsDeadlockError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGormBuilder_DeadlockRetry 测试遇到死锁时按上限重试，超过上限后返回死锁错误
+func TestGormBuilder_DeadlockRetry(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	var attempts, failures int
+	if err := db.Callback().Query().Before("gorm:query").Register("test:deadlock", func(db *gorm.DB) {
+		attempts++
+		if attempts <= failures {
+			_ = db.AddError(&MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+		}
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+
+	b := NewGormBuilder[GormTestEntity](NewDBProxy(db, nil, nil)).SetDeadlockRetry(2)
+	failures = 2
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts, failures = 0, 5
+	if _, err := b.QueryList(context.Background()); !IsDeadlockError(err) {
+		t.Fatalf("expected deadlock error after exhausting retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected retries bounded to 3 attempts, got %d", attempts)
+	}
+
+	attempts, failures = 0, 1
+	b.SetDeadlockRetry(0)
+	if _, err := b.QueryList(context.Background()); !IsDeadlockError(err) {
+		t.Errorf("expected no retry when disabled, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
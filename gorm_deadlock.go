@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"time"
+)
+
+// deadlockRetryDelay 死锁重试的基础等待时间，第 n 次重试等待 n 倍基础时间并叠加 ±50% 的随机抖动
+const deadlockRetryDelay = 10 * time.Millisecond
+
+// 各数据库表示死锁的错误码
+const (
+	mysqlDeadlockNumber     = 1213    // MySQL ER_LOCK_DEADLOCK
+	postgresDeadlockState   = "40P01" // PostgreSQL deadlock_detected
+	sqlServerDeadlockNumber = 1205    // SQL Server 死锁牺牲品
+)
+
+// IsDeadlockError 判断错误链中是否包含数据库报告的死锁错误，按驱动错误码识别，无需引入驱动依赖：
+// MySQL（go-sql-driver/mysql 的 MySQLError.Number 为 1213）、PostgreSQL（pgx 与 lib/pq 的 SQLState() 为 40P01）、
+// SQL Server（go-mssqldb 的 SQLErrorNumber() 为 1205）；锁等待超时、序列化失败等其他错误不视为死锁
+func IsDeadlockError(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == postgresDeadlockState {
+		return true
+	}
+	var number interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &number) && number.SQLErrorNumber() == sqlServerDeadlockNumber {
+		return true
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		if isMySQLDeadlock(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMySQLDeadlock 判断是否为 go-sql-driver/mysql 的死锁错误，该驱动仅以 Number 字段暴露错误码
+func isMySQLDeadlock(err error) bool {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.Type().Name() != "MySQLError" {
+		return false
+	}
+	number := v.FieldByName("Number")
+	return number.IsValid() && number.CanUint() && number.Uint() == mysqlDeadlockNumber
+}
+
+// SetDeadlockRetry 设置查询遇到数据库死锁（见 IsDeadlockError）时的最大重试次数，<= 0 表示不重试
+// 本包只执行读查询，重新执行是安全的；作用于 QueryList 与游标查询的每个批次，重试前等待带随机抖动的递增时间，
+// 等待与重试均受 SetTimeout 的超时约束，ctx 结束时返回 ctx.Err()；其他错误不重试
+func (g *GormBuilder[R]) SetDeadlockRetry(maxRetries int) *GormBuilder[R] {
+	g.deadlockRetries = maxRetries
+	return g
+}
+
+// retryOnDeadlock 执行 fn，遇到死锁错误时按 deadlockRetries 重试
+func (g *GormBuilder[R]) retryOnDeadlock(ctx context.Context, fn func() error) error {
+	err := fn()
+	for attempt := 1; attempt <= g.deadlockRetries && IsDeadlockError(err); attempt++ {
+		wait := deadlockRetryDelay * time.Duration(attempt)
+		timer := time.NewTimer(wait/2 + rand.N(wait))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fantasticbin/QueryBuilder/v2/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// likeEscapeChar LIKE 模式统一使用的转义字符
+	// 不使用反斜杠：MySQL 是否将字符串字面量中的反斜杠视为转义符取决于 NO_BACKSLASH_ESCAPES，无法写出通用的 ESCAPE 子句
+	likeEscapeChar = '!'
+	// likeEscapeClause 声明 likeEscapeChar 为转义字符的 ESCAPE 子句，各方言写法一致
+	likeEscapeClause = "ESCAPE '!'"
+)
+
+// ErrInvalidJSONPath JSON 路径为空或包含空段
+var ErrInvalidJSONPath = errors.New("invalid json path")
+
+// GormJSONFilter 创建按 JSON 列嵌套键等值过滤的 GormScope
+// 根据当前连接的方言生成对应的 JSON 路径表达式，列名与路径均以参数形式传入，避免拼接注入：
+//
+//	mysql / sqlite: JSON_EXTRACT(column, '$.a.b') = ?
+//	postgres:       column->'a'->>'b' = ?
+//	sqlserver:      JSON_VALUE(column, '$.a.b') = ?
+//
+// postgres 的 ->> 取出的是文本，value 为数值或布尔类型时会对取值表达式追加 ::numeric / ::boolean 转换，
+// 避免按字典序比较（如 '10' < '9'）或与 'true' 文本比较失败
+//
+// 参数:
+//
+//	column: JSON 列名
+//	path: 以 "." 分隔的嵌套键路径，如 "profile.city"
+//	value: 比较值
+func GormJSONFilter(column, path string, value any) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		keys := strings.Split(path, ".")
+		for _, key := range keys {
+			if key == "" {
+				_ = db.AddError(fmt.Errorf("%w: %q", ErrInvalidJSONPath, path))
+				return db
+			}
+		}
+
+		col := clause.Column{Name: column}
+		switch db.Dialector.Name() {
+		case "mysql", "sqlite":
+			return db.Where("JSON_EXTRACT(?, ?) = ?", col, "$."+path, value)
+		case "postgres":
+			// 中间层级使用 -> 保持 JSON 类型，最后一层使用 ->> 取文本值
+			expr := strings.Repeat(" -> ?", len(keys)-1) + " ->> ?"
+			vars := make([]any, 0, len(keys)+2)
+			vars = append(vars, col)
+			for _, key := range keys {
+				vars = append(vars, key)
+			}
+			vars = append(vars, value)
+			if cast := postgresJSONCast(value); cast != "" {
+				return db.Where("(?"+expr+")::"+cast+" = ?", vars...)
+			}
+			return db.Where("?"+expr+" = ?", vars...)
+		case "sqlserver":
+			return db.Where("JSON_VALUE(?, ?) = ?", col, "$."+path, value)
+		default:
+			_ = db.AddError(fmt.Errorf("json filter is not supported for dialect %q", db.Dialector.Name()))
+			return db
+		}
+	}
+}
+
+// postgresJSONCast 按比较值的 Go 类型返回 ->> 文本结果需要转换的 postgres 类型，文本比较时返回空
+func postgresJSONCast(value any) string {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "numeric"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return ""
+	}
+}
+
+// GormContains 创建包含匹配的 GormScope：column LIKE '%term%'
+// term 中的 %、_ 及转义字符会按方言转义，用户搜索词不会被当作通配符
+func GormContains(column, term string) GormScope {
+	return gormLikeScope(column, term, true, true, false)
+}
+
+// GormHasPrefix 创建前缀匹配的 GormScope：column LIKE 'term%'
+func GormHasPrefix(column, term string) GormScope {
+	return gormLikeScope(column, term, false, true, false)
+}
+
+// GormHasSuffix 创建后缀匹配的 GormScope：column LIKE '%term'
+func GormHasSuffix(column, term string) GormScope {
+	return gormLikeScope(column, term, true, false, false)
+}
+
+// GormContainsFold 创建不区分大小写的包含匹配 GormScope，结果不受列排序规则（collation）影响
+// PostgreSQL 生成 column ILIKE '%term%'，其余方言生成 LOWER(column) LIKE LOWER('%term%')
+func GormContainsFold(column, term string) GormScope {
+	return gormLikeScope(column, term, true, true, true)
+}
+
+// GormHasPrefixFold 创建不区分大小写的前缀匹配 GormScope
+func GormHasPrefixFold(column, term string) GormScope {
+	return gormLikeScope(column, term, false, true, true)
+}
+
+// GormHasSuffixFold 创建不区分大小写的后缀匹配 GormScope
+func GormHasSuffixFold(column, term string) GormScope {
+	return gormLikeScope(column, term, true, false, true)
+}
+
+// gormLikeScope 按方言转义搜索词并拼接通配符与 ESCAPE 子句，fold 为 true 时按方言生成不区分大小写的匹配
+func gormLikeScope(column, term string, leading, trailing, fold bool) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		dialect := db.Dialector.Name()
+		pattern := escapeLikeForDialect(dialect, term)
+		if leading {
+			pattern = "%" + pattern
+		}
+		if trailing {
+			pattern += "%"
+		}
+		col := clause.Column{Name: column}
+		if !fold {
+			return db.Where("? LIKE ? "+likeEscapeClause, col, pattern)
+		}
+		if dialect == "postgres" {
+			return db.Where("? ILIKE ? "+likeEscapeClause, col, pattern)
+		}
+		return db.Where("LOWER(?) LIKE LOWER(?) "+likeEscapeClause, col, pattern)
+	}
+}
+
+// escapeLikeForDialect 按方言转义 LIKE 模式
+// SQL Server 的 LIKE 额外支持 [...] 字符集语法，需要同时转义 "["
+func escapeLikeForDialect(dialect, term string) string {
+	if dialect == "sqlserver" {
+		return util.EscapeLikeWith(term, likeEscapeChar, "[")
+	}
+	return util.EscapeLike(term, likeEscapeChar)
+}
+
+// GormOrderNullsFirst 创建 NULL 值排在最前的排序作用域，跨方言行为一致
+// PostgreSQL、SQLite、Oracle 生成 column [DESC] NULLS FIRST；
+// MySQL、SQL Server 等不支持该语法的方言先按 CASE WHEN column IS NULL 排序再按列本身排序
+func GormOrderNullsFirst(column string, desc bool) GormScope {
+	return gormNullsOrderScope(column, desc, true)
+}
+
+// GormOrderNullsLast 创建 NULL 值排在最后的排序作用域，跨方言行为一致
+func GormOrderNullsLast(column string, desc bool) GormScope {
+	return gormNullsOrderScope(column, desc, false)
+}
+
+// gormNullsOrderScope 按方言生成 NULL 排序位置确定的 ORDER BY 子句
+func gormNullsOrderScope(column string, desc, nullsFirst bool) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		quoted := db.Statement.Quote(column)
+		order := quoted
+		if desc {
+			order += " DESC"
+		}
+
+		switch db.Dialector.Name() {
+		case "postgres", "sqlite", "oracle":
+			if nullsFirst {
+				return db.Order(order + " NULLS FIRST")
+			}
+			return db.Order(order + " NULLS LAST")
+		default:
+			nullRank := "CASE WHEN " + quoted + " IS NULL THEN 1 ELSE 0 END"
+			if nullsFirst {
+				nullRank += " DESC"
+			}
+			return db.Order(nullRank).Order(order)
+		}
+	}
+}
+
+// GormFullText 创建 PostgreSQL 全文检索过滤作用域：column @@ plainto_tsquery(?)
+// column 为 tsvector 类型的列（或生成列），query 作为参数绑定而非拼接；query 去除空白后为空时不追加任何条件，
+// 非 PostgreSQL 方言返回错误
+func GormFullText(column, query string) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		if strings.TrimSpace(query) == "" {
+			return db
+		}
+		if db.Dialector.Name() != "postgres" {
+			_ = db.AddError(fmt.Errorf("full text search is not supported for dialect %q", db.Dialector.Name()))
+			return db
+		}
+		return db.Where("? @@ plainto_tsquery(?)", clause.Column{Name: column}, query)
+	}
+}
+
+// gormFullTextRankScope 将 ts_rank(column, plainto_tsquery(?)) DESC 置于已有排序之前，已有排序作为同分时的次级排序
+// GORM 合并 ORDER BY 时会丢弃带参数的排序表达式，因此需在所有排序作用域之后应用，并把已有排序列一并包装进表达式
+func gormFullTextRankScope(column, query string) GormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		rank := clause.Expr{
+			SQL:  "ts_rank(?, plainto_tsquery(?)) DESC",
+			Vars: []any{clause.Column{Name: column}, query},
+		}
+		if c, ok := db.Statement.Clauses["ORDER BY"]; ok {
+			if orderBy, ok := c.Expression.(clause.OrderBy); ok && orderBy.Expression == nil && len(orderBy.Columns) > 0 {
+				rank.SQL += ",?"
+				rank.Vars = append(rank.Vars, orderByColumns(orderBy.Columns))
+			}
+		}
+		return db.Order(clause.OrderBy{Expression: rank})
+	}
+}
+
+// orderByColumns 仅输出排序列列表（不含 ORDER BY 关键字），用于嵌入 clause.Expr
+type orderByColumns []clause.OrderByColumn
+
+// Build 实现 clause.Expression 接口
+func (c orderByColumns) Build(builder clause.Builder) {
+	clause.OrderBy{Columns: c}.Build(builder)
+}
@@ -0,0 +1,174 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fantasticbin/QueryBuilder/v2/util"
+	"gorm.io/gorm"
+)
+
+// TestEscapeLike 测试 LIKE 通配符转义
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		input    string
+		escape   byte
+		expected string
+	}{
+		{input: "plain", escape: '\\', expected: "plain"},
+		{input: "100%", escape: '\\', expected: `100\%`},
+		{input: "a_b", escape: '\\', expected: `a\_b`},
+		{input: `c:\dir`, escape: '\\', expected: `c:\\dir`},
+		{input: "50%!", escape: '!', expected: "50!%!!"},
+	}
+	for _, tt := range tests {
+		if got := util.EscapeLike(tt.input, tt.escape); got != tt.expected {
+			t.Errorf("EscapeLike(%q, %q) = %q, want %q", tt.input, tt.escape, got, tt.expected)
+		}
+	}
+
+	if got := util.EscapeLikeWith("[a]%", '\\', "["); got != `\[a]\%` {
+		t.Errorf("EscapeLikeWith = %q", got)
+	}
+}
+
+// TestGormLike_Dialects 测试各方言下 LIKE 条件与 ESCAPE 子句的生成
+func TestGormLike_Dialects(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		scope    GormScope
+		expected string
+		args     string
+	}{
+		{dialect: "mysql", scope: GormContains("name", "50%_off"), expected: `LIKE ? ESCAPE '!'`, args: `args: [%50!%!_off%]`},
+		{dialect: "postgres", scope: GormHasPrefix("name", "50%"), expected: `LIKE ? ESCAPE '!'`, args: `args: [50!%%]`},
+		{dialect: "sqlite", scope: GormHasSuffix("name", `a\b!`), expected: `LIKE ? ESCAPE '!'`, args: `args: [%a\b!!]`},
+		{dialect: "sqlserver", scope: GormContains("name", "[x]"), expected: `LIKE ? ESCAPE '!'`, args: `args: [%![x]%]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			sql, err := explainWithDialect(t, tt.dialect, func(b *GormBuilder[GormTestEntity]) {
+				b.AddFilter(tt.scope)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, "`name` "+tt.expected) || !strings.Contains(sql, tt.args) {
+				t.Errorf("expected %q with %q, got %q", tt.expected, tt.args, sql)
+			}
+		})
+	}
+}
+
+// TestGormLikeFold_Dialects 测试各方言下不区分大小写的 LIKE 条件生成
+func TestGormLikeFold_Dialects(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		scope    GormScope
+		expected string
+		args     string
+	}{
+		{dialect: "postgres", scope: GormContainsFold("name", "Abc%"), expected: "`name` ILIKE ? ESCAPE '!'", args: `args: [%Abc!%%]`},
+		{dialect: "mysql", scope: GormHasPrefixFold("name", "Abc"), expected: "LOWER(`name`) LIKE LOWER(?) ESCAPE '!'", args: `args: [Abc%]`},
+		{dialect: "sqlite", scope: GormHasSuffixFold("name", "Abc"), expected: "LOWER(`name`) LIKE LOWER(?) ESCAPE '!'", args: `args: [%Abc]`},
+		{dialect: "sqlserver", scope: GormContainsFold("name", "[x]"), expected: "LOWER(`name`) LIKE LOWER(?) ESCAPE '!'", args: `args: [%![x]%]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			sql, err := explainWithDialect(t, tt.dialect, func(b *GormBuilder[GormTestEntity]) {
+				b.AddFilter(tt.scope)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.expected) || !strings.Contains(sql, tt.args) {
+				t.Errorf("expected %q with %q, got %q", tt.expected, tt.args, sql)
+			}
+		})
+	}
+}
+
+// TestGormNullsOrder 测试 NULL 排序位置在不同方言下的生成结果
+func TestGormNullsOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  string
+		scope    GormScope
+		expected string
+	}{
+		{
+			name:     "postgres nulls last",
+			dialect:  "postgres",
+			scope:    GormOrderNullsLast("score", true),
+			expected: "ORDER BY `score` DESC NULLS LAST",
+		},
+		{
+			name:     "sqlite nulls first",
+			dialect:  "sqlite",
+			scope:    GormOrderNullsFirst("score", false),
+			expected: "ORDER BY `score` NULLS FIRST",
+		},
+		{
+			name:     "mysql nulls last",
+			dialect:  "mysql",
+			scope:    GormOrderNullsLast("score", false),
+			expected: "ORDER BY CASE WHEN `score` IS NULL THEN 1 ELSE 0 END,`score`",
+		},
+		{
+			name:     "sqlserver nulls first",
+			dialect:  "sqlserver",
+			scope:    GormOrderNullsFirst("t.score", true),
+			expected: "ORDER BY CASE WHEN `t`.`score` IS NULL THEN 1 ELSE 0 END DESC,`t`.`score` DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := explainWithDialect(t, tt.dialect, func(b *GormBuilder[GormTestEntity]) {
+				b.SetSort(tt.scope, func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.expected+",id") {
+				t.Errorf("expected %q followed by tiebreaker, got %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+// TestGormFullText 测试 PostgreSQL 全文检索的过滤、相关度排序与空检索词
+func TestGormFullText(t *testing.T) {
+	sql, err := explainWithDialect(t, "postgres", func(b *GormBuilder[GormTestEntity]) {
+		b.SetFullText("search_vector", "quick fox", true).
+			SetSort(func(db *gorm.DB) *gorm.DB { return db.Order("id DESC") })
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"WHERE `search_vector` @@ plainto_tsquery(?)",
+		"ORDER BY ts_rank(`search_vector`, plainto_tsquery(?)) DESC,id DESC",
+		"args: [quick fox, quick fox]",
+	} {
+		if !strings.Contains(sql, expected) {
+			t.Errorf("expected %q, got %s", expected, sql)
+		}
+	}
+
+	sql, err = explainWithDialect(t, "postgres", func(b *GormBuilder[GormTestEntity]) {
+		b.SetFullText("search_vector", "  ", true)
+	})
+	if err != nil || strings.Contains(sql, "plainto_tsquery") {
+		t.Errorf("expected empty query to be a no-op, got %q, %v", sql, err)
+	}
+
+	_, err = explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		b.SetFullText("search_vector", "fox", false)
+	})
+	if err == nil || !strings.Contains(err.Error(), "full text search is not supported") {
+		t.Errorf("expected unsupported dialect error, got %v", err)
+	}
+}
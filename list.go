@@ -2,12 +2,111 @@ package builder
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gorm.io/gorm"
 )
 
+var (
+	// ErrMandatoryFilterUnsupported 注入的自定义 Querier 无法应用强制过滤条件
+	ErrMandatoryFilterUnsupported = errors.New("mandatory filter requires a built-in builder")
+	// ErrMandatoryFilterInvalid 强制过滤条件为空或类型与数据源不匹配
+	ErrMandatoryFilterInvalid = errors.New("mandatory filter invalid")
+	// ErrDefaultFilterUnsupported 注入的自定义 Querier 无法应用默认过滤条件
+	ErrDefaultFilterUnsupported = errors.New("default filter requires a built-in builder")
+	// ErrDefaultFilterInvalid 默认过滤条件为空或类型与数据源不匹配
+	ErrDefaultFilterInvalid = errors.New("default filter invalid")
+	// ErrSortFieldsUnsupported 注入的自定义 Querier 无法应用 WithSortFields 排序字段
+	ErrSortFieldsUnsupported = errors.New("sort fields require a built-in builder")
+	// ErrConditionsUnsupported 注入的自定义 Querier 无法应用 WithCondition / WithOptional 过滤条件
+	ErrConditionsUnsupported = errors.New("filter conditions require a built-in builder")
+	// ErrResultValidatorUnsupported 注入的自定义 Querier 无法应用 WithResultValidator 结果校验
+	ErrResultValidatorUnsupported = errors.New("result validator requires a built-in builder")
+	// ErrResultValidatorInvalid WithResultValidator 的实体类型与 List 的实体类型不一致
+	ErrResultValidatorInvalid = errors.New("result validator invalid")
+	// ErrResultEnricherUnsupported 注入的自定义 Querier 无法应用 WithAfterAll 结果集处理
+	ErrResultEnricherUnsupported = errors.New("result enricher requires a built-in builder")
+	// ErrResultEnricherInvalid WithAfterAll 的实体类型与 List 的实体类型不一致
+	ErrResultEnricherInvalid = errors.New("result enricher invalid")
+	// ErrDedupByUnsupported 注入的自定义 Querier 无法应用 WithDedupBy 结果去重
+	ErrDedupByUnsupported = errors.New("result dedup requires a built-in builder")
+	// ErrDedupByInvalid WithDedupBy 的实体类型与 List 的实体类型不一致
+	ErrDedupByInvalid = errors.New("result dedup key invalid")
+	// ErrCounterUnsupported 注入的自定义 Querier 无法应用 WithCounter 总数统计实现
+	ErrCounterUnsupported = errors.New("counter requires a built-in builder")
+	// ErrCounterInvalid WithCounter 的实体类型与 List 的实体类型不一致
+	ErrCounterInvalid = errors.New("counter invalid")
+	// ErrMergeSortUnsupported 当前数据源不是 GORM，无法应用 WithMergeSort 分片结果排序
+	ErrMergeSortUnsupported = errors.New("merge sort requires the GORM data source")
+	// ErrMergeSortInvalid WithMergeSort 的 less 函数实体类型与 List 的实体类型不一致
+	ErrMergeSortInvalid = errors.New("merge sort comparator invalid")
+	// ErrPageTokenUnsupported 查询器未实现 PageTokenSetter，无法应用 WithPageToken 分页 token
+	ErrPageTokenUnsupported = errors.New("page token requires a querier implementing PageTokenSetter")
+	// ErrFindOneAndUpdateUnsupported 当前数据源不是 MongoDB，无法执行 QueryOneAndUpdate
+	ErrFindOneAndUpdateUnsupported = errors.New("find one and update requires the MongoDB data source")
+	// ErrNamedScopeUnsupported 当前数据源不是 GORM，无法应用 WithNamedScope
+	ErrNamedScopeUnsupported = errors.New("named scope requires the GORM data source")
+	// ErrNamedScopeNotFound WithNamedScope 引用的作用域未通过 RegisterScope 注册
+	ErrNamedScopeNotFound = errors.New("named scope not registered")
+	// ErrListFrozen 调用 Freeze 后仍修改 List 配置（以 panic 形式抛出，可通过 errors.Is 判断 recover 的值）
+	ErrListFrozen = errors.New("list is frozen")
+	// ErrNotFound QueryOne 未查询到记录
+	ErrNotFound = errors.New("record not found")
+	// ErrGormQueryUnsupported 当前数据源不是 GORM，无法返回 *gorm.DB
+	ErrGormQueryUnsupported = errors.New("gorm query requires the GORM data source")
+	// ErrPluckUnsupported 当前数据源不支持单列提取（仅支持 GORM 与 MongoDB）
+	ErrPluckUnsupported = errors.New("pluck requires the GORM or MongoDB data source")
+	// ErrMongoRawFilterUnsupported 当前数据源不是 MongoDB，无法应用 WithMongoRawFilter
+	ErrMongoRawFilterUnsupported = errors.New("raw mongo filter requires the MongoDB data source")
+	// ErrQueryValuesUnsupported 当前数据源不支持解码为值切片（仅支持非分片的 GORM 与 MongoDB）
+	ErrQueryValuesUnsupported = errors.New("query values requires the GORM or MongoDB data source")
+	// ErrExplainPlanUnsupported 当前数据源构建器不支持获取查询执行计划
+	ErrExplainPlanUnsupported = errors.New("explain plan is not supported by this builder")
+)
+
+// MandatoryFilter 强制过滤条件提供函数（WithDefaultFilter 的默认过滤条件同样使用该类型）
+// 每次查询时以查询 ctx 调用（例如从 ctx 中读取租户 ID），返回值需与数据源匹配：
+// GORM 为 GormScope，MongoDB 为 bson.D，ElasticSearch 为 elastic.Query
+type MandatoryFilter func(ctx context.Context) (any, error)
+
+// LimitCap 每页条数上限提供函数，每次查询时以查询 ctx 调用（例如按 ctx 中租户的套餐等级返回上限），返回 0 表示不限
+type LimitCap func(ctx context.Context) uint32
+
+// LimitFromContext 返回从 ctx.Value(key) 读取上限的 LimitCap，值需为 uint32、int 或 int64，缺失或非正数时不限
+func LimitFromContext(key any) LimitCap {
+	return func(ctx context.Context) uint32 {
+		var n int64
+		switch v := ctx.Value(key).(type) {
+		case uint32:
+			return v
+		case int:
+			n = int64(v)
+		case int64:
+			n = v
+		}
+		return uint32(max(0, min(n, math.MaxUint32)))
+	}
+}
+
+// ErrorMapper 错误映射函数，用于将数据源错误统一转换为业务错误（如 gorm.ErrRecordNotFound → ErrNotFound）
+type ErrorMapper func(err error) error
+
+// QueryRouter 查询路由函数，根据单次查询的元信息（分页、字段投影、游标等）选择数据源
+// 返回值需在 DBProxy 中已配置对应实例，例如大分页的分析型查询路由至 MongoDB 镜像、点查路由至主库
+type QueryRouter func(meta QueryMeta) DataSource
+
+// scopeRegistry 按名称注册的 GORM 作用域
+type scopeRegistry map[string]GormScope
+
 // List 查询列表功能结构
 // 泛型参数:
 //
@@ -21,6 +120,14 @@ type List[R any] struct {
 	afterHook   AfterQueryHook[R]  // 查询后置钩子
 	middlewares []Middleware[R]    // 中间件链
 	scope       ScopeConfigurer[R] // 可选：构建器配置回调，用于自动设置 filter/sort
+	mandatory   MandatoryFilter    // 可选：强制过滤条件，始终与用户 filter 以 AND 组合
+	errMappers  []ErrorMapper      // 错误映射链，作用于查询最终返回的错误
+	router      QueryRouter        // 可选：按查询特征选择数据源
+	limitCap    LimitCap           // 可选：每页条数上限，按查询 ctx 解析
+	namedScopes scopeRegistry      // 可选：按名称注册的 GORM 作用域，供 WithNamedScope 引用
+
+	metaMu sync.Mutex  // 保护 metaQuerier，并发查询时各自回填最近一次使用的构建器
+	frozen atomic.Bool // 是否已冻结配置，冻结后修改配置会 panic
 }
 
 func NewList[R any]() *List[R] {
@@ -43,9 +150,10 @@ func NewListWithData[R any](ds DataSource, data *DBProxy) *List[R] {
 // 支持不同数据源的查询实现，如 Gorm、MongoDB、ElasticSearch
 // 通过该方法指定数据源类型，查询时将自动创建对应的专属构建器
 func (l *List[R]) SetDataSource(ds DataSource) *List[R] {
+	l.mustBeMutable("SetDataSource")
 	l.dataSource = ds
 	if l.querier == nil {
-		l.metaQuerier = nil
+		l.setMetaQuerier(nil)
 	}
 	return l
 }
@@ -54,33 +162,133 @@ func (l *List[R]) SetDataSource(ds DataSource) *List[R] {
 // 用于测试场景或需要自定义查询逻辑的场景
 // 设置后将忽略 DataSource 配置，直接使用注入的 Querier
 func (l *List[R]) SetQuerier(querier Querier[R]) *List[R] {
+	l.mustBeMutable("SetQuerier")
 	l.querier = querier
-	l.metaQuerier = querier
+	l.setMetaQuerier(querier)
+	return l
+}
+
+// Freeze 冻结 List 配置，之后调用 Use、SetScope 等修改配置的方法会以 ErrListFrozen panic
+// List 的查询方法只读取配置、每次查询使用独立的构建器副本，配置完成后即可在多个 goroutine 间共享同一实例并发查询；
+// Freeze 用于在开发阶段尽早暴露"查询期间仍在修改配置"的误用。注意通过 SetQuerier 注入的自定义 Querier 不会被复制，需自行保证并发安全；
+// 并发查询时 GetQueryMeta 对应的可能是仍在执行中的查询，此时应改用中间件或钩子获取各次查询的元信息
+func (l *List[R]) Freeze() *List[R] {
+	l.frozen.Store(true)
 	return l
 }
 
+// mustBeMutable 已冻结时以 ErrListFrozen panic
+func (l *List[R]) mustBeMutable(method string) {
+	if l.frozen.Load() {
+		panic(fmt.Errorf("%w: %s called after Freeze", ErrListFrozen, method))
+	}
+}
+
+// setMetaQuerier 并发安全地回填最近一次使用的构建器
+func (l *List[R]) setMetaQuerier(querier Querier[R]) {
+	l.metaMu.Lock()
+	defer l.metaMu.Unlock()
+	l.metaQuerier = querier
+}
+
 // Use 添加查询中间件
 func (l *List[R]) Use(middlewares Middleware[R]) *List[R] {
+	l.mustBeMutable("Use")
 	l.middlewares = append(l.middlewares, middlewares)
 	return l
 }
 
+// UsePipeline 按顺序追加中间件组合中的全部中间件，等同于依次调用 Use
+func (l *List[R]) UsePipeline(pipeline Pipeline[R]) *List[R] {
+	l.mustBeMutable("UsePipeline")
+	for _, mw := range pipeline {
+		if mw != nil {
+			l.middlewares = append(l.middlewares, mw)
+		}
+	}
+	return l
+}
+
+// UseErrorMapper 添加错误映射函数，作用于 Query/QueryCursor/QueryPage/QueryPageWithPIT 最终返回的错误
+// 包括中间件、钩子返回的错误及 panic 恢复后的错误；多个映射函数按添加顺序依次执行，
+// 映射函数返回 nil 时保留原错误，避免错误被意外吞掉
+func (l *List[R]) UseErrorMapper(mapper ErrorMapper) *List[R] {
+	l.mustBeMutable("UseErrorMapper")
+	if mapper != nil {
+		l.errMappers = append(l.errMappers, mapper)
+	}
+	return l
+}
+
+// mapError 依次应用错误映射链
+func (l *List[R]) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, mapper := range l.errMappers {
+		if mapped := mapper(err); mapped != nil {
+			err = mapped
+		}
+	}
+	return err
+}
+
 // SetScope 设置构建器配置回调
 // 通过 NewGormScope / NewMongoScope / NewElasticSearchScope 创建 ScopeConfigurer
 // 在 Query 内部创建好构建器后自动调用，用于设置 filter/sort
 func (l *List[R]) SetScope(scope ScopeConfigurer[R]) *List[R] {
+	l.mustBeMutable("SetScope")
 	l.scope = scope
 	return l
 }
 
+// SetMandatoryFilter 设置强制过滤条件（如多租户隔离条件）
+// 该条件在每次查询时根据 ctx 解析，并同时作用于数据查询与总数统计，
+// 通过 AddFilter 追加，不会被 Scope 或 SetFilter 覆盖；解析失败时查询直接返回错误
+func (l *List[R]) SetMandatoryFilter(filter MandatoryFilter) *List[R] {
+	l.mustBeMutable("SetMandatoryFilter")
+	l.mandatory = filter
+	return l
+}
+
+// SetRouter 设置查询路由函数，每次查询创建构建器前调用以选择数据源，未设置时使用 SetDataSource 指定的数据源
+// 路由仅在未通过 SetQuerier 注入自定义 Querier 时生效；内置 Scope 会忽略不匹配的构建器，
+// 路由到多个数据源时需在同一 ScopeConfigurer 中按构建器类型分别设置 filter/sort
+func (l *List[R]) SetRouter(router QueryRouter) *List[R] {
+	l.mustBeMutable("SetRouter")
+	l.router = router
+	return l
+}
+
+// SetLimitCap 设置每页条数上限（如按租户套餐等级限制），每次查询时根据 ctx 解析，
+// 实际 limit 取请求 limit 与上限的较小值，在查询执行前生效；与 WithLimitCap 同时配置时取两者中更严格的上限
+func (l *List[R]) SetLimitCap(limitCap LimitCap) *List[R] {
+	l.mustBeMutable("SetLimitCap")
+	l.limitCap = limitCap
+	return l
+}
+
+// RegisterScope 按名称注册可复用的 GORM 作用域（如模型上定义的 Active、Published 等方法），
+// 查询时通过 WithNamedScope 按名称组合，便于由配置驱动过滤条件而无需逐个请求传入闭包；同名注册会覆盖
+func (l *List[R]) RegisterScope(name string, scope GormScope) *List[R] {
+	l.mustBeMutable("RegisterScope")
+	if l.namedScopes == nil {
+		l.namedScopes = make(scopeRegistry)
+	}
+	l.namedScopes[name] = scope
+	return l
+}
+
 // SetBeforeQueryHook 设置查询前置钩子
 func (l *List[R]) SetBeforeQueryHook(hook BeforeQueryHook) *List[R] {
+	l.mustBeMutable("SetBeforeQueryHook")
 	l.beforeHook = hook
 	return l
 }
 
 // SetAfterQueryHook 设置查询后置钩子
 func (l *List[R]) SetAfterQueryHook(hook AfterQueryHook[R]) *List[R] {
+	l.mustBeMutable("SetAfterQueryHook")
 	l.afterHook = hook
 	return l
 }
@@ -96,13 +304,34 @@ func (l *List[R]) buildQuerier(options BaseQueryListOptions) Querier[R] {
 		if data == nil {
 			data = l.data
 		}
-		querier = NewBuilder[R](l.dataSource, data)
+		querier = NewBuilder[R](l.route(options), data)
 	}
 	l.applyBackendOptions(querier, options)
-	l.metaQuerier = querier
+	l.setMetaQuerier(querier)
 	return querier
 }
 
+// route 返回本次查询使用的数据源，配置路由函数时以查询选项构造元信息交由其选择
+func (l *List[R]) route(options BaseQueryListOptions) DataSource {
+	if l.router == nil {
+		return l.dataSource
+	}
+	return l.router(QueryMeta{
+		DataSource:     l.dataSource,
+		QueryName:      options.queryName,
+		Start:          options.GetStart(),
+		Limit:          options.GetLimit(),
+		NeedTotal:      options.GetNeedTotal(),
+		TotalLimit:     options.GetTotalLimit(),
+		NeedPagination: options.GetNeedPagination(),
+		SkipData:       !options.needData,
+		Fields:         options.GetFields(),
+		CursorFields:   options.GetCursorFields(),
+		CursorValues:   options.GetCursorValues(),
+		PageToken:      options.pageToken,
+	})
+}
+
 // cloneQuerier 在已知内置构建器上创建查询状态副本。
 // 未知 Querier 没有通用复制协议，直接返回原实例。
 func cloneQuerier[R any](querier Querier[R]) Querier[R] {
@@ -118,8 +347,104 @@ func cloneQuerier[R any](querier Querier[R]) Querier[R] {
 	}
 }
 
-// applyBackendOptions 应用通用 QueryOption 中承载的后端专属配置。
+// applyBackendOptions 应用通用 QueryOption 中承载的构建器及后端专属配置。
 func (l *List[R]) applyBackendOptions(querier Querier[R], options BaseQueryListOptions) {
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		applyBuilderOptions(&q.builder, options)
+		for _, join := range options.gormJoins {
+			q.AddJoin(join.query, join.args...)
+		}
+		q.AddFilter(options.gormFilters...)
+		q.AddClauses(options.gormClauses...)
+		q.AddCountModifier(options.gormCountModifiers...)
+		if len(options.countPartitions) > 0 {
+			q.SetPartitionedCount(options.countPartitions...)
+		}
+		if options.softDeleteColumn != "" {
+			q.SetSoftDelete(options.softDeleteColumn, options.softDeleteValue)
+		}
+		if options.fromSubquery != nil {
+			q.SetFromSubquery(options.fromSubquery, options.fromAlias)
+		}
+		if options.fromFunction != "" {
+			q.SetFromFunction(options.fromFunction, options.fromAlias, options.fromFunctionArgs...)
+		}
+		if options.rawQuery != "" {
+			q.SetRawQuery(options.rawQuery, options.rawQueryArgs...)
+		}
+		if options.fullTextColumn != "" {
+			q.SetFullText(options.fullTextColumn, options.fullTextQuery, options.fullTextRank)
+		}
+		if options.rawTable != "" {
+			q.SetRawScan(options.rawTable)
+		}
+		if options.consistentRead {
+			q.SetConsistentRead(true)
+		}
+		if options.connTag != "" {
+			q.SetConnTag(options.connTag)
+		}
+		if options.deadlockRetries > 0 {
+			q.SetDeadlockRetry(options.deadlockRetries)
+		}
+	case *MongoBuilder[R]:
+		applyBuilderOptions(&q.builder, options)
+		if options.mongoBatchSize != nil {
+			q.SetBatchSize(*options.mongoBatchSize)
+		}
+		if options.bsonRegistry != nil {
+			q.SetRegistry(options.bsonRegistry)
+		}
+		if options.readConcern != nil {
+			q.SetReadConcern(options.readConcern)
+		}
+		if options.mongoSession != nil {
+			q.SetSession(options.mongoSession)
+		}
+		for _, slice := range options.arraySlices {
+			q.SetArraySlice(slice.field, slice.n)
+		}
+		if len(options.excludeFields) > 0 {
+			q.SetProjectExclude(options.excludeFields...)
+		}
+		if options.consistentRead {
+			q.SetConsistentRead(true)
+		}
+		if options.opTimeSink != nil {
+			q.SetOperationTimeSink(options.opTimeSink)
+		}
+		if options.decodeErrSink != nil {
+			q.SetSkipDecodeErrors(options.decodeErrSink)
+		}
+		if options.tailable {
+			q.SetTailable(true)
+		}
+		if options.maxAwaitTime > 0 {
+			q.SetMaxAwaitTime(options.maxAwaitTime)
+		}
+		if options.mongoLet != nil {
+			q.SetLet(options.mongoLet)
+		}
+		if options.arraySortAnyElem {
+			q.SetArraySortAnyElement(true)
+		}
+		if options.flattenProjection {
+			q.SetFlattenProjection()
+		}
+		if options.tsTimeField != "" {
+			q.SetTimeSeries(options.tsTimeField, options.tsMetaField)
+		}
+		if !options.tsFrom.IsZero() || !options.tsTo.IsZero() {
+			q.SetTimeRange(options.tsFrom, options.tsTo)
+		}
+		if g := options.geoNear; g != nil {
+			q.SetGeoNear(g.field, g.lng, g.lat, g.maxMeters, g.distanceField)
+		}
+	case *ElasticSearchBuilder[R]:
+		applyBuilderOptions(&q.builder, options)
+	}
+
 	if es, ok := querier.(*ElasticSearchBuilder[R]); ok {
 		if options.esIndex != "" {
 			es.SetESIndex(options.esIndex)
@@ -133,6 +458,435 @@ func (l *List[R]) applyBackendOptions(querier Querier[R], options BaseQueryListO
 	}
 }
 
+// applyBuilderOptions 应用内置构建器共享、但未纳入 Querier 接口的配置
+func applyBuilderOptions[B queryBuilder[B, R], R any](b *builder[B, R], options BaseQueryListOptions) {
+	b.SetNeedData(options.needData)
+	if options.queryName != "" {
+		b.SetQueryName(options.queryName)
+	}
+	if options.resultCapacity > 0 {
+		b.SetResultCapacity(options.resultCapacity)
+	}
+	if len(options.cursorSigningKey) > 0 {
+		b.SetCursorSigningKey(options.cursorSigningKey)
+	}
+	if options.cursorToken != "" {
+		b.SetCursorToken(options.cursorToken)
+	}
+	if options.timingSink != nil {
+		b.SetTimingSink(options.timingSink)
+	}
+	if options.dbCallHook != nil {
+		b.SetDBCallHook(options.dbCallHook)
+	}
+	if options.countCtx != nil {
+		b.SetCountContext(options.countCtx)
+	}
+	if options.countData != nil {
+		b.SetCountProxy(options.countData)
+	}
+	if options.reusePointers {
+		b.SetResultPointerReuse(true)
+	}
+	if options.peekNext {
+		b.SetPeekNext(true)
+	}
+	if options.timeout > 0 {
+		b.SetTimeout(options.timeout)
+	}
+	if options.countTimeout > 0 {
+		b.SetBranchTimeouts(0, options.countTimeout)
+	}
+	if options.stableSortKey != "" {
+		b.SetStableSort(options.stableSortKey)
+	}
+	if options.parallelism > 0 {
+		b.SetParallelism(options.parallelism)
+	}
+	if options.limiter != nil {
+		b.SetPriority(options.limiter, options.priority)
+	}
+	if options.requireFilter {
+		b.SetRequireFilter(true)
+	}
+	if options.totalOverride != nil {
+		b.SetTotalOverride(*options.totalOverride)
+	}
+}
+
+// applyRequestOptions 应用需要在查询前校验的请求级配置（选项校验、排序字段、过滤条件、JSON 过滤条件、默认及强制过滤条件、结果校验、总数统计实现、分片排序、分页 token、条数上限与必须过滤条件），任一失败时查询直接返回错误
+func (l *List[R]) applyRequestOptions(ctx context.Context, querier Querier[R], options BaseQueryListOptions) error {
+	if err := options.Err(); err != nil {
+		return err
+	}
+	if err := l.applySortFields(querier, options); err != nil {
+		return err
+	}
+	// 调用处传入的条件属于用户过滤条件，需先于默认过滤条件应用
+	if err := l.applyConditions(querier, options); err != nil {
+		return err
+	}
+	if err := l.applyMongoRawFilter(querier, options); err != nil {
+		return err
+	}
+	if err := l.applyNamedScopes(querier, options); err != nil {
+		return err
+	}
+	if err := l.applyDefaultFilter(ctx, querier, options); err != nil {
+		return err
+	}
+	if err := applyResultHook[DedupKey[R]](querier, options.dedupKey, ErrDedupByInvalid, ErrDedupByUnsupported); err != nil {
+		return err
+	}
+	if err := applyResultHook[ResultValidator[R]](querier, options.resultValidator, ErrResultValidatorInvalid, ErrResultValidatorUnsupported); err != nil {
+		return err
+	}
+	if err := applyResultHook[ResultEnricher[R]](querier, options.resultEnricher, ErrResultEnricherInvalid, ErrResultEnricherUnsupported); err != nil {
+		return err
+	}
+	if err := applyResultHook[Counter[R]](querier, options.counter, ErrCounterInvalid, ErrCounterUnsupported); err != nil {
+		return err
+	}
+	if err := l.applyMergeSort(querier, options); err != nil {
+		return err
+	}
+	if err := l.applyPageToken(querier, options); err != nil {
+		return err
+	}
+	l.applyLimitCap(ctx, querier, options)
+	if err := l.checkRequireFilter(querier); err != nil {
+		return err
+	}
+	return l.applyMandatoryFilter(ctx, querier)
+}
+
+// checkRequireFilter 在追加强制过滤条件之前校验 SetRequireFilter / WithRequireFilter，
+// 强制条件对每个查询都会追加，若计入过滤条件则同一租户内的全表扫描无法被拦截
+func (l *List[R]) checkRequireFilter(querier Querier[R]) error {
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		return q.builder.checkRequireFilter()
+	case *MongoBuilder[R]:
+		return q.builder.checkRequireFilter()
+	case *ElasticSearchBuilder[R]:
+		return q.builder.checkRequireFilter()
+	default:
+		return nil
+	}
+}
+
+// applyConditions 将 WithCondition / WithOptional 收集的条件编译为对应数据源的过滤条件并追加到构建器
+func (l *List[R]) applyConditions(querier Querier[R], options BaseQueryListOptions) error {
+	if len(options.conditions) == 0 {
+		return nil
+	}
+	filter, err := NewConditionFilter(options.conditions...)
+	if err != nil {
+		return err
+	}
+	// 条件必然不匹配任何记录时跳过数据源访问，直接返回空结果
+	empty := filter.Unsatisfiable()
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		q.AddFilter(filter.Gorm()).SetEmptyResult(q.builder.emptyResult || empty)
+	case *MongoBuilder[R]:
+		q.AddFilter(filter.Mongo()).SetEmptyResult(q.builder.emptyResult || empty)
+	case *ElasticSearchBuilder[R]:
+		q.AddFilter(filter.ElasticSearch()).SetEmptyResult(q.builder.emptyResult || empty)
+	default:
+		return ErrConditionsUnsupported
+	}
+	return nil
+}
+
+// applyMongoRawFilter 解析 WithMongoRawFilter 的 JSON 过滤条件并替换构建器的 filter（Scope 设置的 filter 一并被替换），
+// 追加的过滤条件与强制过滤条件仍然生效
+func (l *List[R]) applyMongoRawFilter(querier Querier[R], options BaseQueryListOptions) error {
+	if options.mongoRawFilter == nil {
+		return nil
+	}
+	q, ok := querier.(*MongoBuilder[R])
+	if !ok {
+		return ErrMongoRawFilterUnsupported
+	}
+	filter, err := ParseMongoFilter(*options.mongoRawFilter, options.mongoRawOperators...)
+	if err != nil {
+		return err
+	}
+	q.SetFilter(filter)
+	return nil
+}
+
+// applyNamedScopes 按名称查找 WithNamedScope 引用的作用域并追加为 GORM 过滤条件，任一名称未注册时返回错误
+func (l *List[R]) applyNamedScopes(querier Querier[R], options BaseQueryListOptions) error {
+	if len(options.namedScopes) == 0 {
+		return nil
+	}
+	q, ok := querier.(*GormBuilder[R])
+	if !ok {
+		return ErrNamedScopeUnsupported
+	}
+	for _, name := range options.namedScopes {
+		scope, ok := l.namedScopes[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrNamedScopeNotFound, name)
+		}
+		q.AddFilter(scope)
+	}
+	return nil
+}
+
+// resultHookSetter 内置构建器共享的结果处理函数设置入口，由嵌入的 builder 实现
+type resultHookSetter interface {
+	setResultHook(hook any)
+}
+
+// assertOption 将以 any 保存的选项值断言为 T，类型不匹配时返回包装 invalid 的错误
+func assertOption[T any](value any, invalid error) (T, error) {
+	v, ok := value.(T)
+	if !ok {
+		return v, fmt.Errorf("%w: got %T, want %T", invalid, value, v)
+	}
+	return v, nil
+}
+
+// applyResultHook 将 WithDedupBy、WithResultValidator、WithAfterAll 或 WithCounter 的选项值断言为 T 后设置到内置构建器
+// 这些函数关乎结果正确性与数据隔离，类型不匹配时返回 invalid，自定义 Querier 无法应用时返回 unsupported，而不是静默跳过
+func applyResultHook[T, R any](querier Querier[R], value any, invalid, unsupported error) error {
+	if value == nil {
+		return nil
+	}
+	hook, err := assertOption[T](value, invalid)
+	if err != nil {
+		return err
+	}
+	setter, ok := querier.(resultHookSetter)
+	if !ok {
+		return unsupported
+	}
+	setter.setResultHook(hook)
+	return nil
+}
+
+// applyMergeSort 应用 WithMergeSort 指定的分片结果排序比较函数，类型不匹配或数据源不是 GORM 时返回错误
+func (l *List[R]) applyMergeSort(querier Querier[R], options BaseQueryListOptions) error {
+	if options.mergeLess == nil {
+		return nil
+	}
+	less, err := assertOption[func(a, b *R) bool](options.mergeLess, ErrMergeSortInvalid)
+	if err != nil {
+		return err
+	}
+	q, ok := querier.(*GormBuilder[R])
+	if !ok {
+		return ErrMergeSortUnsupported
+	}
+	// SetShardCompare 使用三路比较函数，由 less 推导
+	q.SetShardCompare(func(a, b *R) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+	return nil
+}
+
+// applyPageToken 将 WithPageToken 传入的不透明分页 token 交给实现 PageTokenSetter 的查询器，
+// 未传入时以 nil 清除上一次查询的 token；内置构建器按偏移或游标值分页，不支持该 token
+func (l *List[R]) applyPageToken(querier Querier[R], options BaseQueryListOptions) error {
+	setter, ok := querier.(PageTokenSetter)
+	if !ok {
+		if len(options.pageToken) > 0 {
+			return ErrPageTokenUnsupported
+		}
+		return nil
+	}
+	setter.SetPageToken(options.pageToken)
+	return nil
+}
+
+// applySortFields 按白名单校验并映射 WithSortFields 指定的排序字段，覆盖 Scope 设置的排序条件
+func (l *List[R]) applySortFields(querier Querier[R], options BaseQueryListOptions) error {
+	if len(options.sortFields) == 0 {
+		return nil
+	}
+	mapping := options.sortMapping
+	if options.sortSnakeCase {
+		mapping = mapping.SnakeCase()
+	}
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		// WithFields 中以 AS 声明的别名与 WithSortAliases 注册的别名一并允许排序
+		mapping = mapping.WithAliases(slices.Concat(options.sortAliases, selectAliases(options.fields))...)
+		sort, err := mapping.Gorm(options.sortFields...)
+		if err != nil {
+			return err
+		}
+		q.SetSort(sort)
+	case *MongoBuilder[R]:
+		sort, err := mapping.Mongo(options.sortFields...)
+		if err != nil {
+			return err
+		}
+		q.SetSort(sort)
+	case *ElasticSearchBuilder[R]:
+		sort, err := mapping.ElasticSearch(options.sortFields...)
+		if err != nil {
+			return err
+		}
+		q.SetSort(sort...)
+	default:
+		return ErrSortFieldsUnsupported
+	}
+	return nil
+}
+
+// applyLimitCap 按 List 与请求配置的条数上限收紧构建器的 limit，limit 为 0（未设置）时直接取上限
+// 解析出上限时强制开启分页，避免 WithNeedPagination(false) 绕过上限返回全部数据
+func (l *List[R]) applyLimitCap(ctx context.Context, querier Querier[R], options BaseQueryListOptions) {
+	if l.limitCap == nil && options.limitCap == nil {
+		return
+	}
+	meta := querier.GetQueryMeta()
+	limit, capped := meta.Limit, false
+	for _, limitCap := range []LimitCap{l.limitCap, options.limitCap} {
+		if limitCap == nil {
+			continue
+		}
+		if n := limitCap(ctx); n > 0 {
+			capped = true
+			if limit == 0 || limit > n {
+				limit = n
+			}
+		}
+	}
+	if capped && !meta.NeedPagination {
+		querier.SetNeedPagination(true)
+	}
+	if limit == meta.Limit {
+		return
+	}
+	// 内置构建器保留请求的 limit，供 Pagination.RequestedLimit 反映服务端的调整
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		q.builder.capLimit(limit)
+	case *MongoBuilder[R]:
+		q.builder.capLimit(limit)
+	case *ElasticSearchBuilder[R]:
+		q.builder.capLimit(limit)
+	default:
+		querier.SetLimit(limit)
+	}
+}
+
+// applyMandatoryFilter 解析强制过滤条件并追加到构建器
+func (l *List[R]) applyMandatoryFilter(ctx context.Context, querier Querier[R]) error {
+	if l.mandatory == nil {
+		return nil
+	}
+	switch querier.(type) {
+	case *GormBuilder[R], *MongoBuilder[R], *ElasticSearchBuilder[R]:
+	default:
+		return ErrMandatoryFilterUnsupported
+	}
+
+	filter, err := l.mandatory(ctx)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		return fmt.Errorf("%w: nil filter", ErrMandatoryFilterInvalid)
+	}
+	if !addMandatoryFilter(querier, filter) {
+		return fmt.Errorf("%w: unexpected type %T", ErrMandatoryFilterInvalid, filter)
+	}
+	return nil
+}
+
+// applyDefaultFilter 构建器未设置任何过滤条件时，解析默认过滤条件并追加到构建器
+// 需在强制过滤条件之前调用，避免强制条件被视为调用方已设置的过滤条件
+func (l *List[R]) applyDefaultFilter(ctx context.Context, querier Querier[R], options BaseQueryListOptions) error {
+	if options.defaultFilter == nil {
+		return nil
+	}
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		if q.hasFilter() {
+			return nil
+		}
+	case *MongoBuilder[R]:
+		if q.hasFilter() {
+			return nil
+		}
+	case *ElasticSearchBuilder[R]:
+		if q.hasFilter() {
+			return nil
+		}
+	default:
+		return ErrDefaultFilterUnsupported
+	}
+
+	filter, err := options.defaultFilter(ctx)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		return fmt.Errorf("%w: nil filter", ErrDefaultFilterInvalid)
+	}
+	if !addProvidedFilter(querier, filter) {
+		return fmt.Errorf("%w: unexpected type %T", ErrDefaultFilterInvalid, filter)
+	}
+	return nil
+}
+
+// addProvidedFilter 按数据源类型将过滤条件追加到内置构建器，类型不匹配时返回 false
+func addProvidedFilter[R any](querier Querier[R], filter any) bool {
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		if f, ok := filter.(GormScope); ok {
+			q.AddFilter(f)
+			return true
+		}
+	case *MongoBuilder[R]:
+		if f, ok := filter.(bson.D); ok {
+			q.AddFilter(f)
+			return true
+		}
+	case *ElasticSearchBuilder[R]:
+		if f, ok := filter.(elastic.Query); ok {
+			q.AddFilter(f)
+			return true
+		}
+	}
+	return false
+}
+
+// addMandatoryFilter 追加强制过滤条件，GORM 构建器单独保存以便在用户过滤条件分组之外组合，类型不匹配时返回 false
+func addMandatoryFilter[R any](querier Querier[R], filter any) bool {
+	q, ok := querier.(*GormBuilder[R])
+	if !ok {
+		return addProvidedFilter(querier, filter)
+	}
+	f, ok := filter.(GormScope)
+	if ok {
+		q.addMandatoryFilter(f)
+	}
+	return ok
+}
+
+// recoveredError 将查询过程中 recover 得到的值转换为 error
+// panic 值本身为 error 时以 %w 包装，保留驱动错误链供调用方 errors.As 判断
+func recoveredError(msg string, r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	return fmt.Errorf("%s: %v", msg, r)
+}
+
 // passQueryOption 传递查询选项
 func (l *List[R]) passQueryOption(querier Querier[R], options BaseQueryListOptions, cursorMode, handleHookAndMiddleware bool) {
 	// 配置通用参数
@@ -189,15 +943,19 @@ func (l *List[R]) passQueryOption(querier Querier[R], options BaseQueryListOptio
 // 该方法会根据传入的 QueryOption 选项执行查询
 // 通过 DataSource 枚举值自动创建对应的专属查询构建器
 // 调用方需在获取具体构建器后自行设置 filter/sort
+// 配置 WithErrorOnEmpty 时，本页结果为空（仅统计总数时为总数为 0）则返回指定的错误（同样经过错误映射链）
 func (l *List[R]) Query(
 	ctx context.Context,
 	opts ...QueryOption,
 ) (result *core.ListResult[R], err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
 	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
 	defer func() {
 		if r := recover(); r != nil {
 			result = nil
-			err = fmt.Errorf("query panic recovered: %v", r)
+			err = recoveredError("query panic recovered", r)
 		}
 	}()
 
@@ -205,7 +963,79 @@ func (l *List[R]) Query(
 
 	querier := l.buildQuerier(options)
 	l.passQueryOption(querier, options, false, true)
-	return querier.QueryList(ctx)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, err
+	}
+	result, err = querier.QueryList(ctx)
+	if err == nil && options.errOnEmpty != nil && isEmptyListResult(result, options.needData) {
+		return nil, options.errOnEmpty
+	}
+	return result, err
+}
+
+// isEmptyListResult 判断列表结果是否为空，仅统计总数（needData 为 false）时以总数为准
+func isEmptyListResult[R any](result *core.ListResult[R], needData bool) bool {
+	if result == nil {
+		return true
+	}
+	if !needData {
+		return result.Total == 0
+	}
+	return len(result.Items) == 0
+}
+
+// QueryOne 查询单条记录，固定 limit 为 1 且不统计总数，其余选项与 Query 一致
+// 未查询到记录时返回 ErrNotFound（同样经过错误映射链）；配置 WithIgnoreNotFound 后返回 (nil, nil)
+func (l *List[R]) QueryOne(ctx context.Context, opts ...QueryOption) (*R, error) {
+	result, err := l.Query(ctx, slices.Concat(opts, []QueryOption{
+		WithLimit(1),
+		WithNeedTotal(false),
+		WithNeedPagination(true),
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) > 0 {
+		return result.Items[0], nil
+	}
+	if LoadQueryOptions(opts...).ignoreNotFound {
+		return nil, nil
+	}
+	return nil, l.mapError(ErrNotFound)
+}
+
+// QueryOneAndUpdate 按 filter 与 sort 原子地查找一条文档并应用 update，返回更新前的文档，
+// 配置 WithReturnAfterUpdate 时返回更新后的文档；分页与总数选项被忽略，不会执行钩子与中间件，但 WithTimeout 与 WithQueryPriority 生效；
+// 配置 WithResultValidator 或 WithAfterAll 时返回 ErrUpdateResultChecksUnsupported。
+// 未匹配到文档时返回 ErrNotFound（配置 WithIgnoreNotFound 后返回 (nil, nil)），非 MongoDB 数据源返回 ErrFindOneAndUpdateUnsupported
+func (l *List[R]) QueryOneAndUpdate(ctx context.Context, update bson.M, opts ...QueryOption) (item *R, err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
+	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
+	defer func() {
+		if r := recover(); r != nil {
+			item = nil
+			err = recoveredError("query one and update panic recovered", r)
+		}
+	}()
+
+	options := LoadQueryOptions(opts...)
+	querier := l.buildQuerier(options)
+	l.passQueryOption(querier, options, false, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, err
+	}
+
+	q, ok := querier.(*MongoBuilder[R])
+	if !ok {
+		return nil, ErrFindOneAndUpdateUnsupported
+	}
+	item, err = q.FindOneAndUpdate(ctx, update, options.returnAfterUpdate)
+	if errors.Is(err, ErrNotFound) && options.ignoreNotFound {
+		return nil, nil
+	}
+	return item, err
 }
 
 // QueryCursor 执行游标分页查询，返回 iter.Seq2 迭代器
@@ -215,11 +1045,14 @@ func (l *List[R]) QueryCursor(
 	ctx context.Context,
 	opts ...QueryOption,
 ) (seq iter.Seq2[*R, error]) {
+	defer func() {
+		seq = l.mapCursorErrors(seq)
+	}()
 	// 捕获 NewBuilder 等可能产生的 panic，转换为返回错误的迭代器
 	defer func() {
 		if r := recover(); r != nil {
 			seq = func(yield func(*R, error) bool) {
-				yield(nil, fmt.Errorf("query cursor panic recovered: %v", r))
+				yield(nil, recoveredError("query cursor panic recovered", r))
 			}
 		}
 	}()
@@ -228,9 +1061,54 @@ func (l *List[R]) QueryCursor(
 
 	querier := l.buildQuerier(options)
 	l.passQueryOption(querier, options, true, true)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return func(yield func(*R, error) bool) {
+			yield(nil, err)
+		}
+	}
 	return querier.QueryCursor(ctx)
 }
 
+// mapCursorErrors 对游标迭代器产出的错误应用错误映射链
+func (l *List[R]) mapCursorErrors(seq iter.Seq2[*R, error]) iter.Seq2[*R, error] {
+	if len(l.errMappers) == 0 {
+		return seq
+	}
+	return func(yield func(*R, error) bool) {
+		for item, err := range seq {
+			if !yield(item, l.mapError(err)) {
+				return
+			}
+		}
+	}
+}
+
+// QueryStreamAggregate 以游标流式遍历查询结果，每行先交给 sink 处理（如写入 CSV），再由 acc 累加到聚合值，
+// 遍历结束后返回最终聚合值，避免为汇总统计再次遍历大数据集；Go 方法不支持类型参数，因此以包级函数提供，
+// 调用示例：summary, err := QueryStreamAggregate(ctx, list, writeRow, addOrder, opts...)
+// 基于 QueryCursor 实现，每批次照常经过中间件链；sink 为 nil 时仅聚合，
+// 查询或 sink 返回错误时停止遍历，返回此前已累加的聚合值与该错误
+func QueryStreamAggregate[A, R any](
+	ctx context.Context,
+	l *List[R],
+	sink func(item *R) error,
+	acc func(agg A, item *R) A,
+	opts ...QueryOption,
+) (agg A, err error) {
+	for item, err := range l.QueryCursor(ctx, opts...) {
+		if err != nil {
+			return agg, err
+		}
+		if sink != nil {
+			if err := sink(item); err != nil {
+				return agg, err
+			}
+		}
+		agg = acc(agg, item)
+	}
+	return agg, nil
+}
+
 // QueryPage 执行单批次游标分页查询，返回结构化的分页结果
 // 该方法会根据传入的 QueryOption 选项执行单批次游标分页查询
 // 返回当前页数据、是否有下一页、下一页游标值等信息
@@ -238,11 +1116,14 @@ func (l *List[R]) QueryPage(
 	ctx context.Context,
 	opts ...QueryOption,
 ) (result *core.CursorPageResult[R], err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
 	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
 	defer func() {
 		if r := recover(); r != nil {
 			result = nil
-			err = fmt.Errorf("query page panic recovered: %v", r)
+			err = recoveredError("query page panic recovered", r)
 		}
 	}()
 
@@ -250,6 +1131,9 @@ func (l *List[R]) QueryPage(
 
 	querier := l.buildQuerier(options)
 	l.passQueryOption(querier, options, true, true)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, err
+	}
 	return querier.QueryPage(ctx)
 }
 
@@ -259,10 +1143,13 @@ func (l *List[R]) QueryPageWithPIT(
 	ctx context.Context,
 	opts ...QueryOption,
 ) (result *core.ESPITPageResult[R], err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			result = nil
-			err = fmt.Errorf("query page with pit panic recovered: %v", r)
+			err = recoveredError("query page with pit panic recovered", r)
 		}
 	}()
 
@@ -274,6 +1161,9 @@ func (l *List[R]) QueryPageWithPIT(
 	}
 
 	l.passQueryOption(es, options, true, true)
+	if err := l.applyRequestOptions(ctx, es, options); err != nil {
+		return nil, err
+	}
 	return es.QueryPageWithPIT(ctx)
 }
 
@@ -284,7 +1174,7 @@ func (l *List[R]) Explain(ctx context.Context, opts ...QueryOption) (result stri
 	defer func() {
 		if r := recover(); r != nil {
 			result = ""
-			err = fmt.Errorf("explain panic recovered: %v", r)
+			err = recoveredError("explain panic recovered", r)
 		}
 	}()
 
@@ -297,10 +1187,158 @@ func (l *List[R]) Explain(ctx context.Context, opts ...QueryOption) (result stri
 		cursorMode = true
 	}
 	l.passQueryOption(querier, options, cursorMode, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return "", err
+	}
 
 	return querier.Explain(ctx)
 }
 
+// planExplainer 支持返回数据库查询执行计划的构建器（GormBuilder、MongoBuilder）
+type planExplainer interface {
+	ExplainPlan(ctx context.Context) (string, error)
+}
+
+// ExplainPlan 返回数据库对最终查询的执行计划，会实际访问数据库
+// 仅 GORM 与 MongoDB 构建器支持，其余构建器返回 ErrExplainPlanUnsupported
+func (l *List[R]) ExplainPlan(ctx context.Context, opts ...QueryOption) (result string, err error) {
+	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
+	defer func() {
+		if r := recover(); r != nil {
+			result = ""
+			err = recoveredError("explain plan panic recovered", r)
+		}
+	}()
+
+	options := LoadQueryOptions(opts...)
+	querier := l.buildQuerier(options)
+	explainer, ok := querier.(planExplainer)
+	if !ok {
+		return "", ErrExplainPlanUnsupported
+	}
+
+	var cursorMode bool
+	if len(options.GetCursorFields()) > 0 {
+		cursorMode = true
+	}
+	l.passQueryOption(querier, options, cursorMode, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return "", err
+	}
+
+	return explainer.ExplainPlan(ctx)
+}
+
+// BuildGormQuery 返回已应用 filter、sort、分页等全部配置但尚未执行的 *gorm.DB
+// 作为构建器未覆盖场景的扩展入口，调用方可继续链式调用 Pluck、Update 或自定义 Scan；
+// 不会执行钩子与中间件，非 GORM 数据源返回 ErrGormQueryUnsupported
+func (l *List[R]) BuildGormQuery(ctx context.Context, opts ...QueryOption) (query *gorm.DB, err error) {
+	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
+	defer func() {
+		if r := recover(); r != nil {
+			query = nil
+			err = recoveredError("build gorm query panic recovered", r)
+		}
+	}()
+
+	options := LoadQueryOptions(opts...)
+	querier := l.buildQuerier(options)
+	gormBuilder, ok := querier.(*GormBuilder[R])
+	if !ok {
+		return nil, ErrGormQueryUnsupported
+	}
+
+	l.passQueryOption(querier, options, false, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, err
+	}
+
+	return gormBuilder.BuildQuery(ctx)
+}
+
+// Pluck 按 List 的 filter、sort 与分页配置只查询单列，返回该列值的切片，避免加载完整实体
+// Go 方法不支持类型参数，因此以包级函数提供，调用示例：ids, err := Pluck[uint32](ctx, list, "id", opts...)
+// GORM 使用 Pluck(column, &dest)，MongoDB 使用单字段投影（column 支持 "a.b" 嵌套路径）；
+// 两者均使用请求的 limit，不受 peekNext 影响；不会执行钩子与中间件，ElasticSearch 与自定义 Querier 返回 ErrPluckUnsupported
+func Pluck[T, R any](ctx context.Context, l *List[R], column string, opts ...QueryOption) (values []T, err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
+	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
+	defer func() {
+		if r := recover(); r != nil {
+			values = nil
+			err = recoveredError("pluck panic recovered", r)
+		}
+	}()
+
+	options := LoadQueryOptions(opts...)
+	querier := l.buildQuerier(options)
+	l.passQueryOption(querier, options, false, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, err
+	}
+
+	values = []T{}
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		query, err := q.BuildQuery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := query.Pluck(column, &values).Error; err != nil {
+			return nil, err
+		}
+	case *MongoBuilder[R]:
+		if err := q.pluck(ctx, column, func(raw bson.RawValue) error {
+			var value T
+			if err := q.decodeValue(raw, &value); err != nil {
+				return err
+			}
+			values = append(values, value)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrPluckUnsupported
+	}
+	return values, nil
+}
+
+// QueryValues 按 List 的 filter、sort 与分页配置查询并直接解码为值切片 []R，同时返回总数（未开启 needTotal 时为 0）
+// 适用于字段较少的值类型结果，省去逐条分配指针与 nil 判断；GORM 使用 Find(&[]R{})，MongoDB 使用 cursor.All(&[]R{})；
+// 与 Pluck 相同，不会执行钩子与中间件，但遵循 SetTimeout 与 SetPriority，并对结果执行 DedupBy、ResultValidator 与 ResultEnricher；
+// ElasticSearch、分片 GORM 与自定义 Querier 返回 ErrQueryValuesUnsupported
+func (l *List[R]) QueryValues(ctx context.Context, opts ...QueryOption) (values []R, total int64, err error) {
+	defer func() {
+		err = l.mapError(err)
+	}()
+	// 捕获 NewBuilder 等可能产生的 panic，转换为 error 返回
+	defer func() {
+		if r := recover(); r != nil {
+			values, total = nil, 0
+			err = recoveredError("query values panic recovered", r)
+		}
+	}()
+
+	options := LoadQueryOptions(opts...)
+	querier := l.buildQuerier(options)
+	l.passQueryOption(querier, options, false, false)
+	if err := l.applyRequestOptions(ctx, querier, options); err != nil {
+		return nil, 0, err
+	}
+
+	switch q := querier.(type) {
+	case *GormBuilder[R]:
+		return executeValuesQuery(ctx, newMiddlewareContext[R](&q.builder), q.queryValues)
+	case *MongoBuilder[R]:
+		return executeValuesQuery(ctx, newMiddlewareContext[R](&q.builder), q.queryValues)
+	default:
+		return nil, 0, ErrQueryValuesUnsupported
+	}
+}
+
 // GetQueryMeta 返回当前内部构建器的查询元信息快照
 // 支持以下场景：
 //   - 通过 NewListWithData 创建时，内部预先持有构建器实例
@@ -309,8 +1347,11 @@ func (l *List[R]) Explain(ctx context.Context, opts ...QueryOption) (result stri
 //
 // 仅在首次调用 Query/QueryCursor 之前且未设置 Querier 时返回零值
 func (l *List[R]) GetQueryMeta() QueryMeta {
-	if l.metaQuerier != nil {
-		return l.metaQuerier.GetQueryMeta()
+	l.metaMu.Lock()
+	querier := l.metaQuerier
+	l.metaMu.Unlock()
+	if querier != nil {
+		return querier.GetQueryMeta()
 	}
 	return QueryMeta{}
 }
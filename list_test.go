@@ -4,13 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
 	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.uber.org/mock/gomock"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils/tests"
 )
 
 type TestEntity struct {
@@ -468,6 +476,25 @@ func TestExplainPanicRecovery(t *testing.T) {
 	}
 }
 
+// TestExplainPlanUnsupported 测试不支持执行计划的构建器返回 ErrExplainPlanUnsupported
+func TestExplainPlanUnsupported(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	list := NewList[TestEntity]()
+	list.SetQuerier(NewMockQuerier[TestEntity](ctrl))
+	if _, err := list.ExplainPlan(ctx); !errors.Is(err, ErrExplainPlanUnsupported) {
+		t.Errorf("expected ErrExplainPlanUnsupported for mock querier, got %v", err)
+	}
+
+	esList := NewList[TestEntity]()
+	esList.SetDataSource(ElasticSearch)
+	if _, err := esList.ExplainPlan(ctx, WithData(NewDBProxy(nil, nil, &elastic.Client{}))); !errors.Is(err, ErrExplainPlanUnsupported) {
+		t.Errorf("expected ErrExplainPlanUnsupported for ElasticSearch, got %v", err)
+	}
+}
+
 // TestGetQueryMeta 测试 GetQueryMeta 方法
 func TestGetQueryMeta(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -1412,3 +1439,1407 @@ func TestListQueryPageWithPITRejectsCursorWithoutPITID(t *testing.T) {
 		t.Fatalf("expected ErrPITCursorWithoutPITID, got %v", err)
 	}
 }
+
+type tenantCtxKey struct{}
+
+// TestListMandatoryFilter_Gorm 测试强制过滤条件同时作用于 GORM 数据查询与总数统计，且不会被 Scope 覆盖
+func TestListMandatoryFilter_Gorm(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.WithValue(context.Background(), tenantCtxKey{}, 42)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	}, nil))
+	list.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		tenantID, ok := ctx.Value(tenantCtxKey{}).(int)
+		if !ok {
+			return nil, errors.New("tenant missing")
+		}
+		return GormScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("tenant_id = ?", tenantID)
+		}), nil
+	})
+
+	if _, err := list.Query(ctx, WithData(proxy)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "name = ?") || !strings.Contains(sql, "tenant_id = ?") {
+			t.Errorf("expected user and mandatory filters in %q", sql)
+		}
+	}
+
+	// 缺少租户信息时查询直接失败
+	if _, err := list.Query(context.Background(), WithData(proxy)); err == nil || err.Error() != "tenant missing" {
+		t.Errorf("expected provider error, got %v", err)
+	}
+	if _, err := list.QueryPage(context.Background(), WithData(proxy), WithCursorField("id")); err == nil {
+		t.Error("expected provider error from QueryPage, got nil")
+	}
+	for _, err := range list.QueryCursor(context.Background(), WithData(proxy), WithCursorField("id")) {
+		if err == nil {
+			t.Error("expected provider error from QueryCursor, got nil")
+		}
+	}
+}
+
+// TestListMandatoryFilter_GormOrScope 测试含 Or 的用户过滤条件整体加括号，强制过滤条件不会被 Or 绕过
+func TestListMandatoryFilter_GormOrScope(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice").Or("name = ?", "bob")
+	}, nil))
+	list.SetMandatoryFilter(func(context.Context) (any, error) {
+		return GormScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("tenant_id = ?", 42)
+		}), nil
+	})
+
+	if _, err := list.Query(context.Background(), WithData(proxy)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 2 {
+		t.Fatalf("expected find and count statements, got %v", sqls)
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(sql, "WHERE (name = ? OR name = ?) AND tenant_id = ?") {
+			t.Errorf("expected grouped user filter before the mandatory filter, got %q", sql)
+		}
+	}
+}
+
+// TestListMandatoryFilter_MongoAndES 测试强制过滤条件在 MongoDB 与 ElasticSearch 中的组合方式
+func TestListMandatoryFilter_MongoAndES(t *testing.T) {
+	ctx := context.Background()
+
+	mongoList := NewList[TestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	mongoList.SetScope(NewMongoScope[TestEntity](bson.D{{Key: "status", Value: "active"}}, nil))
+	mongoList.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		return bson.D{{Key: "tenant_id", Value: 42}}, nil
+	})
+	explain, err := mongoList.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, "$and") || !strings.Contains(explain, "tenant_id") || !strings.Contains(explain, "status") {
+		t.Errorf("expected $and of user and mandatory filters, got %s", explain)
+	}
+
+	esList := NewList[TestEntity]()
+	esList.SetDataSource(ElasticSearch)
+	esList.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		return elastic.NewTermQuery("tenant_id", 42), nil
+	})
+	explain, err = esList.Explain(ctx, WithData(NewDBProxy(nil, nil, &elastic.Client{})), WithESIndex("docs"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, `"filter"`) || !strings.Contains(explain, "tenant_id") {
+		t.Errorf("expected bool filter with tenant_id, got %s", explain)
+	}
+}
+
+// TestListMongoRawFilter 测试 JSON 过滤条件替换 Scope 的 filter，强制过滤条件仍然生效
+func TestListMongoRawFilter(t *testing.T) {
+	ctx := context.Background()
+
+	list := NewList[TestEntity]()
+	list.SetDataSource(MongoDB)
+	list.SetScope(NewMongoScope[TestEntity](bson.D{{Key: "status", Value: "active"}}, nil))
+	list.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		return bson.D{{Key: "tenant_id", Value: 42}}, nil
+	})
+	data := WithData(NewDBProxy(nil, &mongo.Collection{}, nil))
+
+	explain, err := list.Explain(ctx, data, WithMongoRawFilter(`{"city": {"$in": ["Paris", "Rome"]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, "city") || !strings.Contains(explain, "tenant_id") || strings.Contains(explain, "status") {
+		t.Errorf("expected raw filter to replace scope filter and keep mandatory filter, got %s", explain)
+	}
+
+	if _, err := list.Explain(ctx, data, WithMongoRawFilter(`{"$where": "sleep(1000)"}`)); !errors.Is(err, ErrMongoOperatorNotAllowed) {
+		t.Errorf("expected ErrMongoOperatorNotAllowed, got %v", err)
+	}
+
+	gormList := NewList[GormTestEntity]()
+	gormList.SetDataSource(Gorm)
+	if _, err := gormList.Query(ctx, WithData(NewDBProxy(&gorm.DB{}, nil, nil)), WithMongoRawFilter(`{}`)); !errors.Is(err, ErrMongoRawFilterUnsupported) {
+		t.Errorf("expected ErrMongoRawFilterUnsupported, got %v", err)
+	}
+}
+
+// TestListMandatoryFilter_Errors 测试强制过滤条件类型不匹配与自定义 Querier 场景
+func TestListMandatoryFilter_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		return bson.D{{Key: "tenant_id", Value: 42}}, nil
+	})
+	if _, err := list.Query(ctx, WithData(NewDBProxy(&gorm.DB{}, nil, nil))); !errors.Is(err, ErrMandatoryFilterInvalid) {
+		t.Errorf("expected ErrMandatoryFilterInvalid, got %v", err)
+	}
+
+	list.SetMandatoryFilter(func(ctx context.Context) (any, error) { return nil, nil })
+	if _, err := list.Query(ctx, WithData(NewDBProxy(&gorm.DB{}, nil, nil))); !errors.Is(err, ErrMandatoryFilterInvalid) {
+		t.Errorf("expected ErrMandatoryFilterInvalid for nil filter, got %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockQuerier := NewMockQuerier[GormTestEntity](ctrl)
+	mockQuerier.EXPECT().SetStart(gomock.Any()).Return(mockQuerier)
+	mockQuerier.EXPECT().SetLimit(gomock.Any()).Return(mockQuerier)
+	mockQuerier.EXPECT().SetNeedTotal(gomock.Any()).Return(mockQuerier)
+	mockQuerier.EXPECT().SetNeedPagination(gomock.Any()).Return(mockQuerier)
+
+	list.SetQuerier(mockQuerier)
+	if _, err := list.Query(ctx); !errors.Is(err, ErrMandatoryFilterUnsupported) {
+		t.Errorf("expected ErrMandatoryFilterUnsupported, got %v", err)
+	}
+}
+
+// TestUseErrorMapper 测试错误映射链作用于查询最终返回的错误
+func TestUseErrorMapper(t *testing.T) {
+	ctx := context.Background()
+	errNotFound := errors.New("not found")
+	newList := func(t *testing.T) (*List[TestEntity], *MockQuerier[TestEntity]) {
+		ctrl := gomock.NewController(t)
+		mockQuerier := NewMockQuerier[TestEntity](ctrl)
+		mockQuerier.EXPECT().SetStart(gomock.Any()).Return(mockQuerier).AnyTimes()
+		mockQuerier.EXPECT().SetLimit(gomock.Any()).Return(mockQuerier).AnyTimes()
+		mockQuerier.EXPECT().SetNeedTotal(gomock.Any()).Return(mockQuerier).AnyTimes()
+		mockQuerier.EXPECT().SetNeedPagination(gomock.Any()).Return(mockQuerier).AnyTimes()
+		mockQuerier.EXPECT().SetCursorField(gomock.Any()).Return(mockQuerier).AnyTimes()
+
+		list := NewList[TestEntity]()
+		list.SetQuerier(mockQuerier)
+		list.UseErrorMapper(func(err error) error {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errNotFound
+			}
+			return nil
+		})
+		return list, mockQuerier
+	}
+
+	t.Run("Query 映射错误", func(t *testing.T) {
+		list, mockQuerier := newList(t)
+		mockQuerier.EXPECT().QueryList(ctx).Return(nil, gorm.ErrRecordNotFound)
+
+		if _, err := list.Query(ctx); !errors.Is(err, errNotFound) {
+			t.Errorf("expected mapped error, got %v", err)
+		}
+	})
+
+	t.Run("映射函数返回 nil 时保留原错误", func(t *testing.T) {
+		list, mockQuerier := newList(t)
+		original := errors.New("connection refused")
+		mockQuerier.EXPECT().QueryPage(ctx).Return(nil, original)
+
+		if _, err := list.QueryPage(ctx, WithCursorField("id")); !errors.Is(err, original) {
+			t.Errorf("expected original error, got %v", err)
+		}
+	})
+
+	t.Run("QueryCursor 映射迭代器错误", func(t *testing.T) {
+		list, mockQuerier := newList(t)
+		mockQuerier.EXPECT().QueryCursor(ctx).Return(func(yield func(*TestEntity, error) bool) {
+			if !yield(&TestEntity{ID: 1}, nil) {
+				return
+			}
+			yield(nil, gorm.ErrRecordNotFound)
+		})
+
+		var errs []error
+		for _, err := range list.QueryCursor(ctx, WithCursorField("id")) {
+			errs = append(errs, err)
+		}
+		if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], errNotFound) {
+			t.Errorf("expected [nil, errNotFound], got %v", errs)
+		}
+	})
+
+	t.Run("panic 恢复后的错误同样被映射", func(t *testing.T) {
+		list := NewList[TestEntity]()
+		list.SetDataSource(DataSource(99))
+		list.UseErrorMapper(func(err error) error {
+			return fmt.Errorf("domain: %w", err)
+		})
+
+		_, err := list.Query(ctx, WithData(NewDBProxy(&gorm.DB{}, nil, nil)))
+		if err == nil || !strings.HasPrefix(err.Error(), "domain: query panic recovered:") {
+			t.Errorf("expected mapped panic error, got %v", err)
+		}
+	})
+}
+
+// TestWithQueryName 测试 WithQueryName 通过 QueryMeta 传递给中间件
+func TestWithQueryName(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+
+	var got string
+	list := NewListWithData[GormTestEntity](Gorm, proxy)
+	list.Use(func(
+		ctx context.Context,
+		b Querier[GormTestEntity],
+		next func(context.Context) (core.Result[GormTestEntity], error),
+	) (core.Result[GormTestEntity], error) {
+		got = b.GetQueryMeta().QueryName
+		return next(ctx)
+	})
+
+	if _, err := list.Query(context.Background(), WithQueryName("users.list")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "users.list" {
+		t.Errorf("expected query name users.list, got %q", got)
+	}
+	if name := list.GetQueryMeta().QueryName; name != "users.list" {
+		t.Errorf("expected GetQueryMeta to report query name, got %q", name)
+	}
+}
+
+// TestListQueryOne 测试单条查询的 limit 固定、ErrNotFound 与 WithIgnoreNotFound
+func TestListQueryOne(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	db, recorder := newFakeShard(t, 0, 7, 8)
+	item, err := list.QueryOne(ctx, WithData(NewDBProxy(db, nil, nil)), WithLimit(50))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item == nil || item.ID != 7 {
+		t.Errorf("expected first item, got %+v", item)
+	}
+	sqls := recorder.all()
+	if len(sqls) != 1 || !strings.Contains(sqls[0], "LIMIT ?") {
+		t.Errorf("expected a single limited find without count, got %v", sqls)
+	}
+
+	proxy, _ := newDryRunGormProxy(t)
+	if _, err := list.QueryOne(ctx, WithData(proxy)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	item, err = list.QueryOne(ctx, WithData(proxy), WithIgnoreNotFound())
+	if item != nil || err != nil {
+		t.Errorf("expected (nil, nil) with WithIgnoreNotFound, got (%v, %v)", item, err)
+	}
+
+	// ErrNotFound 同样经过错误映射链
+	errUserMissing := errors.New("user missing")
+	list.UseErrorMapper(func(err error) error {
+		if errors.Is(err, ErrNotFound) {
+			return errUserMissing
+		}
+		return nil
+	})
+	if _, err := list.QueryOne(ctx, WithData(proxy)); !errors.Is(err, errUserMissing) {
+		t.Errorf("expected mapped error, got %v", err)
+	}
+}
+
+// driverTestError 模拟驱动专属错误类型（如 *mysql.MySQLError）
+type driverTestError struct {
+	Code int
+}
+
+func (e *driverTestError) Error() string {
+	return fmt.Sprintf("driver error %d", e.Code)
+}
+
+// TestListDriverErrorChain 测试驱动错误在查询与 panic 恢复路径中保留错误链，可通过 errors.As 获取
+func TestListDriverErrorChain(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:driver_error", func(db *gorm.DB) {
+		_ = db.AddError(&driverTestError{Code: 1062})
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	_, err = list.Query(ctx, WithData(NewDBProxy(db, nil, nil)))
+	var driverErr *driverTestError
+	if !errors.As(err, &driverErr) || driverErr.Code != 1062 {
+		t.Errorf("expected driver error through errors.As, got %v", err)
+	}
+
+	// 以 error 值 panic 时同样保留错误链
+	proxy, _ := newDryRunGormProxy(t)
+	list.SetScope(func(Querier[GormTestEntity]) {
+		panic(&driverTestError{Code: 1213})
+	})
+	_, err = list.Query(ctx, WithData(proxy))
+	if !errors.As(err, &driverErr) || driverErr.Code != 1213 {
+		t.Errorf("expected wrapped panic error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "query panic recovered") {
+		t.Errorf("expected panic context in message, got %v", err)
+	}
+}
+
+// TestListDefaultFilter 测试默认过滤条件仅在未设置过滤条件时生效，且强制过滤条件不影响判断
+func TestListDefaultFilter(t *testing.T) {
+	ctx := context.Background()
+	defaultFilter := WithDefaultFilter(func(ctx context.Context) (any, error) {
+		return GormScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", "active")
+		}), nil
+	})
+	findSQL := func(recorder *sqlRecorder) string {
+		for _, sql := range recorder.all() {
+			if strings.HasPrefix(sql, "SELECT * ") {
+				return sql
+			}
+		}
+		return ""
+	}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetMandatoryFilter(func(ctx context.Context) (any, error) {
+		return GormScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("tenant_id = ?", 42)
+		}), nil
+	})
+	proxy, recorder := newDryRunGormProxy(t)
+	if _, err := list.Query(ctx, WithData(proxy), defaultFilter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql := findSQL(recorder); !strings.Contains(sql, "status = ?") || !strings.Contains(sql, "tenant_id = ?") {
+		t.Errorf("expected default and mandatory filters, got %s", sql)
+	}
+
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	}, nil))
+	proxy, recorder = newDryRunGormProxy(t)
+	if _, err := list.Query(ctx, WithData(proxy), defaultFilter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql := findSQL(recorder); strings.Contains(sql, "status = ?") || !strings.Contains(sql, "name = ?") {
+		t.Errorf("expected default filter to be skipped when a filter is set, got %s", sql)
+	}
+
+	// 类型不匹配
+	mongoList := NewList[TestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	if _, err := mongoList.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)), defaultFilter); !errors.Is(err, ErrDefaultFilterInvalid) {
+		t.Errorf("expected ErrDefaultFilterInvalid, got %v", err)
+	}
+	explain, err := mongoList.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithDefaultFilter(func(ctx context.Context) (any, error) {
+			return bson.D{{Key: "status", Value: "active"}}, nil
+		}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, "status") {
+		t.Errorf("expected default mongo filter, got %s", explain)
+	}
+}
+
+// TestListBuildGormQuery 测试返回已应用全部配置的 *gorm.DB，可继续链式调用
+func TestListBuildGormQuery(t *testing.T) {
+	ctx := context.Background()
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}))
+
+	query, err := list.BuildGormQuery(ctx, WithData(proxy), WithLimit(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.all()) != 0 {
+		t.Fatalf("expected no statement before execution, got %v", recorder.all())
+	}
+	var names []string
+	if err := query.Pluck("name", &names).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	expected := "SELECT `name` FROM `gorm_test_entities` WHERE name = ? ORDER BY id LIMIT ?"
+	if len(sqls) != 1 || sqls[0] != expected {
+		t.Errorf("expected %q, got %v", expected, sqls)
+	}
+
+	// 探测下一页多取的一条只用于内部查询，返回的查询保持请求的 limit
+	query, err = list.BuildGormQuery(ctx, WithData(proxy), WithLimit(5), WithPeekNext())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limit, ok := query.Statement.Clauses["LIMIT"].Expression.(clause.Limit); !ok || limit.Limit == nil || *limit.Limit != 5 {
+		t.Errorf("expected LIMIT 5 with peek next, got %#v", query.Statement.Clauses["LIMIT"].Expression)
+	}
+
+	mongoList := NewList[TestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	if _, err := mongoList.BuildGormQuery(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil))); !errors.Is(err, ErrGormQueryUnsupported) {
+		t.Errorf("expected ErrGormQueryUnsupported, got %v", err)
+	}
+}
+
+// TestPluck 测试按 List 配置只提取单列，非 GORM/MongoDB 数据源返回 ErrPluckUnsupported
+func TestPluck(t *testing.T) {
+	ctx := context.Background()
+	proxy, recorder := newDryRunGormProxy(t)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "alice")
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}))
+
+	ids, err := Pluck[uint32](ctx, list, "id", WithData(proxy), WithLimit(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids == nil || len(ids) != 0 {
+		t.Errorf("expected empty non-nil slice in dry run, got %v", ids)
+	}
+	sqls := recorder.all()
+	expected := "SELECT `id` FROM `gorm_test_entities` WHERE name = ? ORDER BY id LIMIT ?"
+	if len(sqls) != 1 || sqls[0] != expected {
+		t.Errorf("expected %q, got %v", expected, sqls)
+	}
+
+	// 开启 peekNext 时与 MongoDB 一致使用请求的 limit，Pluck 不裁剪结果，不能多取探测下一页的一条
+	peekDB, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	var vars []any
+	if err := peekDB.Callback().Query().After("gorm:query").Register("test:record_vars", func(db *gorm.DB) {
+		vars = slices.Clone(db.Statement.Vars)
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+	if _, err := Pluck[uint32](ctx, list, "id", WithData(NewDBProxy(peekDB, nil, nil)), WithLimit(5), WithPeekNext()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vars) == 0 || vars[len(vars)-1] != 5 {
+		t.Errorf("expected LIMIT 5 with peek next, got vars %v", vars)
+	}
+
+	esList := NewList[TestEntity]()
+	esList.SetDataSource(ElasticSearch)
+	if _, err := Pluck[string](ctx, esList, "name", WithData(NewDBProxy(nil, nil, &elastic.Client{}))); !errors.Is(err, ErrPluckUnsupported) {
+		t.Errorf("expected ErrPluckUnsupported, got %v", err)
+	}
+}
+
+// TestListQueryValues 测试直接解码为值切片、peekNext 多取记录的裁剪与不支持的数据源
+func TestListQueryValues(t *testing.T) {
+	ctx := context.Background()
+	db, recorder := newFakeShard(t, 42, 1, 2, 3)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	values, total, err := list.QueryValues(ctx,
+		WithData(NewDBProxy(db, nil, nil)), WithLimit(2), WithPeekNext(), WithNeedTotal(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values[0].ID != 1 || values[1].ID != 2 {
+		t.Errorf("expected first two values, got %+v", values)
+	}
+	if total != 42 {
+		t.Errorf("expected total 42, got %d", total)
+	}
+	if sqls := recorder.all(); len(sqls) != 2 {
+		t.Errorf("expected find and count statements, got %v", sqls)
+	}
+
+	esList := NewList[TestEntity]()
+	esList.SetDataSource(ElasticSearch)
+	if _, _, err := esList.QueryValues(ctx, WithData(NewDBProxy(nil, nil, &elastic.Client{}))); !errors.Is(err, ErrQueryValuesUnsupported) {
+		t.Errorf("expected ErrQueryValuesUnsupported, got %v", err)
+	}
+}
+
+// TestListQueryValues_ResultChecks 测试值切片查询执行去重、结果校验与批量处理，校验失败时返回 ErrResultRejected
+func TestListQueryValues_ResultChecks(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newFakeShard(t, 0, 1, 1, 2)
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	values, _, err := list.QueryValues(ctx,
+		WithData(NewDBProxy(db, nil, nil)),
+		WithDedupBy(func(item *GormTestEntity) any { return item.ID }),
+		WithAfterAll(func(_ context.Context, items []*GormTestEntity) error {
+			for _, item := range items {
+				item.Name = fmt.Sprintf("user-%d", item.ID)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values[0].Name != "user-1" || values[1].Name != "user-2" {
+		t.Errorf("expected deduplicated and enriched values, got %+v", values)
+	}
+
+	_, _, err = list.QueryValues(ctx,
+		WithData(NewDBProxy(db, nil, nil)),
+		WithResultValidator(func(_ context.Context, item *GormTestEntity) error {
+			if item.ID == 2 {
+				return errors.New("tenant mismatch")
+			}
+			return nil
+		}),
+	)
+	if !errors.Is(err, ErrResultRejected) {
+		t.Errorf("expected ErrResultRejected, got %v", err)
+	}
+}
+
+// TestListQuery_PeekNext 测试多取一条探测下一页并裁剪结果
+func TestListQuery_PeekNext(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	testCases := []struct {
+		ids     []uint32
+		items   int
+		hasMore bool
+	}{
+		{ids: []uint32{1, 2, 3}, items: 2, hasMore: true},
+		{ids: []uint32{1, 2}, items: 2, hasMore: false},
+	}
+	for _, tc := range testCases {
+		db, _ := newFakeShard(t, 0, tc.ids...)
+		result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithLimit(2), WithNeedTotal(false), WithPeekNext())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Items) != tc.items || result.HasMore != tc.hasMore || result.GetHasMore() != tc.hasMore {
+			t.Errorf("ids=%v: expected %d items hasMore=%v, got %d items hasMore=%v",
+				tc.ids, tc.items, tc.hasMore, len(result.Items), result.HasMore)
+		}
+	}
+
+	sql, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		b.SetPeekNext(true)
+		b.SetLimit(2).SetNeedPagination(true)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "args: [3]") {
+		t.Errorf("expected limit+1 in SQL, got %s", sql)
+	}
+}
+
+// TestListQuery_ResultValidator 测试结果行校验：任一行校验失败时查询返回 ErrResultRejected，实体类型不匹配时直接报错
+func TestListQuery_ResultValidator(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	errTenant := errors.New("tenant mismatch")
+	validator := WithResultValidator(func(ctx context.Context, item *GormTestEntity) error {
+		if item.ID > 2 {
+			return errTenant
+		}
+		return nil
+	})
+
+	db, _ := newFakeShard(t, 0, 1, 2)
+	result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), validator)
+	if err != nil || len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %v, %v", result, err)
+	}
+
+	db, _ = newFakeShard(t, 0, 1, 3)
+	result, err = list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), validator)
+	if !errors.Is(err, ErrResultRejected) || !errors.Is(err, errTenant) || result != nil {
+		t.Errorf("expected ErrResultRejected wrapping errTenant, got %v, %v", result, err)
+	}
+
+	mismatch := WithResultValidator(func(context.Context, *TestEntity) error { return nil })
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), mismatch); !errors.Is(err, ErrResultValidatorInvalid) {
+		t.Errorf("expected ErrResultValidatorInvalid, got %v", err)
+	}
+}
+
+// TestListQuery_ResultEnricher 测试结果集批量处理函数以整批结果调用一次并可原地修改，返回错误时查询失败
+func TestListQuery_ResultEnricher(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	var calls int
+	enricher := WithAfterAll(func(ctx context.Context, items []*GormTestEntity) error {
+		calls++
+		for _, item := range items {
+			item.Name = fmt.Sprintf("user-%d", item.ID)
+		}
+		return nil
+	})
+
+	db, _ := newFakeShard(t, 0, 1, 2)
+	result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), enricher)
+	if err != nil || len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %v, %v", result, err)
+	}
+	if calls != 1 || result.Items[0].Name != "user-1" || result.Items[1].Name != "user-2" {
+		t.Errorf("expected one enrichment call mutating rows, got %d calls, %+v", calls, result.Items)
+	}
+
+	db, _ = newFakeShard(t, 0)
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), enricher); err != nil || calls != 1 {
+		t.Errorf("expected empty result to skip enrichment, got %d calls, %v", calls, err)
+	}
+
+	errLoad := errors.New("load profiles failed")
+	failing := WithAfterAll(func(context.Context, []*GormTestEntity) error { return errLoad })
+	db, _ = newFakeShard(t, 0, 1)
+	if result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), failing); !errors.Is(err, errLoad) || result != nil {
+		t.Errorf("expected enrichment error, got %v, %v", result, err)
+	}
+
+	mismatch := WithAfterAll(func(context.Context, []*TestEntity) error { return nil })
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), mismatch); !errors.Is(err, ErrResultEnricherInvalid) {
+		t.Errorf("expected ErrResultEnricherInvalid, got %v", err)
+	}
+}
+
+// TestListQuery_DedupBy 测试按键去除重复行并保留首次出现的行，needTotal 时总数保持去重前的行数
+func TestListQuery_DedupBy(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	dedup := WithDedupBy(func(item *GormTestEntity) any { return item.ID })
+
+	db, _ := newFakeShard(t, 5, 1, 2, 1, 3, 2)
+	result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(false), dedup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := make([]uint32, 0, len(result.Items))
+	for _, item := range result.Items {
+		ids = append(ids, item.ID)
+	}
+	if !slices.Equal(ids, []uint32{1, 2, 3}) || result.Total != 3 {
+		t.Errorf("expected deduplicated ids [1 2 3] with total 3, got %v, total %d", ids, result.Total)
+	}
+
+	result, err = list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithNeedTotal(true), dedup)
+	if err != nil || len(result.Items) != 3 || result.Total != 5 {
+		t.Errorf("expected 3 items with pre-dedup total 5, got %v, %v", result, err)
+	}
+
+	mismatch := WithDedupBy(func(*TestEntity) any { return nil })
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), mismatch); !errors.Is(err, ErrDedupByInvalid) {
+		t.Errorf("expected ErrDedupByInvalid, got %v", err)
+	}
+}
+
+// TestList_Freeze 测试冻结后修改配置会 panic，且冻结的 List 可在多个 goroutine 间并发查询
+func TestList_Freeze(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy).Freeze()
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, ErrListFrozen) {
+				t.Errorf("expected ErrListFrozen panic, got %v", err)
+			}
+		}()
+		list.Use(nil)
+	}()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if _, err := list.Query(context.Background(), WithLimit(5)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// TestSetDefaultNeedTotal 测试进程级默认总数统计开关，单次查询的 WithNeedTotal 优先
+func TestSetDefaultNeedTotal(t *testing.T) {
+	SetDefaultNeedTotal(false)
+	t.Cleanup(func() {
+		SetDefaultNeedTotal(true)
+	})
+
+	if options := LoadQueryOptions(); options.GetNeedTotal() {
+		t.Error("expected needTotal to default to false")
+	}
+	if options := LoadQueryOptions(WithNeedTotal(true)); !options.GetNeedTotal() {
+		t.Error("expected WithNeedTotal(true) to override the default")
+	}
+
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy)
+	if _, err := list.Query(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 1 {
+		t.Errorf("expected only the data query, got %v", sqls)
+	}
+}
+
+// TestList_SetRouter 测试查询路由按单次查询的元信息选择数据源
+func TestList_SetRouter(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	proxy.Mongodb = &mongo.Collection{}
+
+	errRouted := errors.New("routed to mongo")
+	list := NewListWithData[GormTestEntity](Gorm, proxy).SetRouter(func(meta QueryMeta) DataSource {
+		if meta.Limit > 100 {
+			return MongoDB
+		}
+		return meta.DataSource
+	})
+	list.Use(func(
+		ctx context.Context,
+		b Querier[GormTestEntity],
+		next func(context.Context) (core.Result[GormTestEntity], error),
+	) (core.Result[GormTestEntity], error) {
+		if b.GetQueryMeta().DataSource == MongoDB {
+			return nil, errRouted
+		}
+		return next(ctx)
+	})
+
+	if _, err := list.Query(context.Background(), WithLimit(10)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 2 {
+		t.Errorf("expected point read on gorm, got %v", sqls)
+	}
+
+	if _, err := list.Query(context.Background(), WithLimit(500)); !errors.Is(err, errRouted) {
+		t.Errorf("expected heavy query routed to mongo, got %v", err)
+	}
+	if ds := list.GetQueryMeta().DataSource; ds != MongoDB {
+		t.Errorf("expected GetQueryMeta to report MongoDB, got %v", ds)
+	}
+}
+
+// TestWithPage 测试页码换算、页码 0 视为首页，以及起始位置溢出或下溢回绕时归零并返回 ErrStartOverflow
+func TestWithPage(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []QueryOption
+		wantStart uint32
+		wantErr   bool
+	}{
+		{name: "page 3", opts: []QueryOption{WithPage(3, 20)}, wantStart: 40},
+		{name: "page 0 is first page", opts: []QueryOption{WithPage(0, 20)}, wantStart: 0},
+		{name: "page overflow", opts: []QueryOption{WithPage(math.MaxUint32, 5000)}, wantErr: true},
+		{name: "unsigned underflow", opts: []QueryOption{WithStart(math.MaxUint32 - 19)}, wantErr: true},
+		{name: "large legal start", opts: []QueryOption{WithStart(1 << 30)}, wantStart: 1 << 30},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			options := LoadQueryOptions(tc.opts...)
+			if got := options.GetStart(); got != tc.wantStart {
+				t.Errorf("expected start %d, got %d", tc.wantStart, got)
+			}
+			if err := options.Err(); errors.Is(err, ErrStartOverflow) != tc.wantErr {
+				t.Errorf("expected overflow error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy)
+	var page uint32
+	if _, err := list.Query(context.Background(), WithStart((page-1)*20), WithLimit(20)); !errors.Is(err, ErrStartOverflow) {
+		t.Errorf("expected ErrStartOverflow, got %v", err)
+	}
+	if sqls := recorder.all(); len(sqls) != 0 {
+		t.Errorf("expected no query to run, got %v", sqls)
+	}
+}
+
+// TestListQuery_Counter 测试自定义总数统计实现替代默认统计，可回退到精确统计，非精确时标记 TotalEstimated
+func TestListQuery_Counter(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	estimated := WithCounter[GormTestEntity](CounterFunc[GormTestEntity](func(
+		ctx context.Context,
+		querier Querier[GormTestEntity],
+		exact func(context.Context) (int64, error),
+	) (int64, bool, error) {
+		return 1000, false, nil
+	}))
+	db, recorder := newFakeShard(t, 7, 1, 2)
+	result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), estimated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Total != 1000 || !result.TotalEstimated || len(result.Items) != 2 {
+		t.Errorf("expected estimated total 1000 with 2 items, got %+v", result)
+	}
+	for _, sql := range recorder.all() {
+		if strings.Contains(sql, "count(") {
+			t.Errorf("expected default count to be skipped, got %s", sql)
+		}
+	}
+
+	var fallback int
+	cached := WithCounter[GormTestEntity](CounterFunc[GormTestEntity](func(
+		ctx context.Context,
+		querier Querier[GormTestEntity],
+		exact func(context.Context) (int64, error),
+	) (int64, bool, error) {
+		fallback++
+		total, err := exact(ctx)
+		return total, true, err
+	}))
+	db, _ = newFakeShard(t, 7, 1, 2)
+	result, err = list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), cached)
+	if err != nil || result.Total != 7 || result.TotalEstimated || fallback != 1 {
+		t.Errorf("expected exact fallback total 7, got %+v, %v, %d calls", result, err, fallback)
+	}
+
+	mismatch := WithCounter[TestEntity](CounterFunc[TestEntity](func(
+		context.Context, Querier[TestEntity], func(context.Context) (int64, error),
+	) (int64, bool, error) {
+		return 0, true, nil
+	}))
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), mismatch); !errors.Is(err, ErrCounterInvalid) {
+		t.Errorf("expected ErrCounterInvalid, got %v", err)
+	}
+}
+
+// TestListQuery_BranchTimeouts 测试总数统计超出独立超时后放弃总数并返回数据，其他统计错误仍使查询失败
+func TestListQuery_BranchTimeouts(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	slow := WithCounter[GormTestEntity](CounterFunc[GormTestEntity](func(
+		ctx context.Context, _ Querier[GormTestEntity], _ func(context.Context) (int64, error),
+	) (int64, bool, error) {
+		<-ctx.Done()
+		return 0, true, ctx.Err()
+	}))
+	db, _ := newFakeShard(t, 7, 1, 2)
+	result, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), slow, WithBranchTimeouts(time.Second, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("expected count timeout to degrade, got %v", err)
+	}
+	if len(result.Items) != 2 || result.HasTotal {
+		t.Errorf("expected 2 items without total, got %+v", result)
+	}
+
+	result, err = list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), WithBranchTimeouts(0, time.Second))
+	if err != nil || !result.HasTotal || result.Total != 7 {
+		t.Errorf("expected exact total 7 within budget, got %+v, %v", result, err)
+	}
+
+	errCount := errors.New("count failed")
+	failing := WithCounter[GormTestEntity](CounterFunc[GormTestEntity](func(
+		context.Context, Querier[GormTestEntity], func(context.Context) (int64, error),
+	) (int64, bool, error) {
+		return 0, true, errCount
+	}))
+	if _, err := list.Query(ctx, WithData(NewDBProxy(db, nil, nil)), failing, WithBranchTimeouts(0, time.Second)); !errors.Is(err, errCount) {
+		t.Errorf("expected count error, got %v", err)
+	}
+}
+
+// TestList_LimitCap 测试按 ctx 解析的条数上限收紧 limit，List 级与请求级上限取更严格者
+func TestList_LimitCap(t *testing.T) {
+	type tierKey struct{}
+	proxy, _ := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy).SetLimitCap(LimitFromContext(tierKey{}))
+
+	for _, tc := range []struct {
+		name  string
+		ctx   context.Context
+		opts  []QueryOption
+		limit uint32
+	}{
+		{name: "no cap in ctx", ctx: context.Background(), opts: []QueryOption{WithLimit(500)}, limit: 500},
+		{name: "capped by tier", ctx: context.WithValue(context.Background(), tierKey{}, 100), opts: []QueryOption{WithLimit(500)}, limit: 100},
+		{name: "below cap", ctx: context.WithValue(context.Background(), tierKey{}, int64(100)), opts: []QueryOption{WithLimit(20)}, limit: 20},
+		{name: "stricter request cap", ctx: context.WithValue(context.Background(), tierKey{}, uint32(100)), opts: []QueryOption{
+			WithLimit(500),
+			WithLimitCap(func(context.Context) uint32 { return 50 }),
+		}, limit: 50},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := list.Query(tc.ctx, tc.opts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if limit := list.GetQueryMeta().Limit; limit != tc.limit {
+				t.Errorf("expected limit %d, got %d", tc.limit, limit)
+			}
+		})
+	}
+}
+
+// TestList_LimitCapForcesPagination 测试解析出上限时 WithNeedPagination(false) 不能绕过上限
+func TestList_LimitCapForcesPagination(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy).SetLimitCap(func(context.Context) uint32 { return 100 })
+
+	if _, err := list.Query(context.Background(), WithLimit(500), WithNeedPagination(false), WithNeedTotal(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls := recorder.all()
+	if len(sqls) == 0 || !strings.Contains(sqls[len(sqls)-1], "LIMIT ?") || !list.GetQueryMeta().NeedPagination {
+		t.Errorf("expected capped query to stay paginated, got %v", sqls)
+	}
+
+	// 上限提供函数返回 0 表示不限，不强制分页
+	unlimited := NewListWithData[GormTestEntity](Gorm, proxy).SetLimitCap(func(context.Context) uint32 { return 0 })
+	if _, err := unlimited.Query(context.Background(), WithNeedPagination(false), WithNeedTotal(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqls = recorder.all()
+	if strings.Contains(sqls[len(sqls)-1], "LIMIT") {
+		t.Errorf("expected query without LIMIT when no cap resolves, got %v", sqls[len(sqls)-1])
+	}
+}
+
+// TestListQuery_PaginationAdjusted 测试 Pagination 报告实际生效的分页窗口与请求的 limit
+func TestListQuery_PaginationAdjusted(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	list := NewListWithData[GormTestEntity](Gorm, proxy)
+	capped := WithLimitCap(func(context.Context) uint32 { return 50 })
+
+	for _, tc := range []struct {
+		name      string
+		opts      []QueryOption
+		limit     uint32
+		requested uint32
+		adjusted  bool
+	}{
+		{name: "as requested", opts: []QueryOption{WithStart(40), WithLimit(20), capped}, limit: 20, requested: 20},
+		{name: "capped", opts: []QueryOption{WithStart(40), WithLimit(500), capped}, limit: 50, requested: 500, adjusted: true},
+		{name: "default limit", opts: []QueryOption{WithStart(40), WithLimit(0)}, limit: defaultLimit, requested: 0, adjusted: true},
+		{name: "default limit without data query", opts: []QueryOption{WithStart(40), WithLimit(0), WithCondition("id", OpIn, []int{})}, limit: defaultLimit, adjusted: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := list.Query(context.Background(), tc.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p := result.Pagination
+			if p == nil || p.Start != 40 || p.Limit != tc.limit || p.RequestedLimit != tc.requested || p.Adjusted() != tc.adjusted {
+				t.Errorf("expected start 40, limit %d, requested %d, adjusted %v, got %+v", tc.limit, tc.requested, tc.adjusted, p)
+			}
+		})
+	}
+}
+
+// TestQueryStreamAggregate 测试流式遍历时逐行输出并累加聚合值，sink 出错时返回已累加的结果
+func TestQueryStreamAggregate(t *testing.T) {
+	db, _ := newFakeShard(t, 0, 1, 2, 3)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	type summary struct {
+		Count int
+		SumID uint32
+	}
+	addRow := func(s summary, item *GormTestEntity) summary {
+		s.Count++
+		s.SumID += item.ID
+		return s
+	}
+
+	var streamed []uint32
+	got, err := QueryStreamAggregate(context.Background(), list, func(item *GormTestEntity) error {
+		streamed = append(streamed, item.ID)
+		return nil
+	}, addRow, WithData(NewDBProxy(db, nil, nil)), WithCursorField("id"), WithLimit(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(streamed, []uint32{1, 2, 3}) || got != (summary{Count: 3, SumID: 6}) {
+		t.Errorf("expected rows [1 2 3] and summary {3 6}, got %v and %+v", streamed, got)
+	}
+
+	errSink := errors.New("write failed")
+	got, err = QueryStreamAggregate(context.Background(), list, func(item *GormTestEntity) error {
+		if item.ID == 3 {
+			return errSink
+		}
+		return nil
+	}, addRow, WithData(NewDBProxy(db, nil, nil)), WithCursorField("id"), WithLimit(10))
+	if !errors.Is(err, errSink) || got != (summary{Count: 2, SumID: 3}) {
+		t.Errorf("expected sink error with partial summary {2 3}, got %v and %+v", err, got)
+	}
+}
+
+// TestListQuery_NamedScope 测试按名称组合已注册的 GORM 作用域，未注册名称与非 GORM 数据源返回错误
+func TestListQuery_NamedScope(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.RegisterScope("active", func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_deleted = ?", false)
+	}).RegisterScope("named", func(db *gorm.DB) *gorm.DB {
+		return db.Where("name <> ?", "")
+	})
+
+	if _, err := list.Query(ctx, WithData(proxy), WithNamedScope("active", "named")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var found bool
+	for _, sql := range recorder.all() {
+		if strings.HasPrefix(sql, "SELECT * ") {
+			found = strings.Contains(sql, "WHERE is_deleted = ? AND name <> ?")
+		}
+	}
+	if !found {
+		t.Errorf("expected both named scopes applied, got %v", recorder.all())
+	}
+
+	if _, err := list.Query(ctx, WithData(proxy), WithNamedScope("archived")); !errors.Is(err, ErrNamedScopeNotFound) {
+		t.Errorf("expected ErrNamedScopeNotFound, got %v", err)
+	}
+
+	mongoList := NewList[TestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	if _, err := mongoList.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithNamedScope("active")); !errors.Is(err, ErrNamedScopeUnsupported) {
+		t.Errorf("expected ErrNamedScopeUnsupported, got %v", err)
+	}
+}
+
+// TestListUsePipeline 测试中间件组合的顺序、Then 组合与合并为单个中间件
+func TestListUsePipeline(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware[GormTestEntity] {
+		return func(ctx context.Context, b Querier[GormTestEntity], next func(context.Context) (core.Result[GormTestEntity], error)) (core.Result[GormTestEntity], error) {
+			order = append(order, name)
+			return next(ctx)
+		}
+	}
+	observability := NewPipeline(trace("log"), nil, trace("metrics"))
+	resilience := NewPipeline(trace("retry"))
+	stack := observability.Then(resilience)
+	if len(observability) != 2 || len(stack) != 3 {
+		t.Fatalf("expected Then to leave the original pipeline unchanged, got %d and %d", len(observability), len(stack))
+	}
+
+	proxy, _ := newDryRunGormProxy(t)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.UsePipeline(stack).Use(trace("cache"))
+	if _, err := list.Query(context.Background(), WithData(proxy)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"log", "metrics", "retry", "cache"}; !slices.Equal(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+
+	order = nil
+	result, err := stack.Middleware()(context.Background(), nil, func(context.Context) (core.Result[GormTestEntity], error) {
+		order = append(order, "query")
+		return &core.ListResult[GormTestEntity]{Total: 7}, nil
+	})
+	if err != nil || result.GetTotal() != 7 {
+		t.Fatalf("expected wrapped query result, got %v, %v", result, err)
+	}
+	if expected := []string{"log", "metrics", "retry", "query"}; !slices.Equal(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+// TestListQuery_ErrorOnEmpty 测试结果为空时返回指定错误并经过错误映射链，非空结果与默认行为不受影响
+func TestListQuery_ErrorOnEmpty(t *testing.T) {
+	ctx := context.Background()
+	errNoOrders := errors.New("no orders for customer")
+	errMapped := errors.New("mapped")
+	proxy, _ := newDryRunGormProxy(t)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	if _, err := list.Query(ctx, WithData(proxy)); err != nil {
+		t.Fatalf("expected empty result to be fine by default, got %v", err)
+	}
+	if result, err := list.Query(ctx, WithData(proxy), WithErrorOnEmpty(errNoOrders)); !errors.Is(err, errNoOrders) || result != nil {
+		t.Errorf("expected errNoOrders, got %v, %v", result, err)
+	}
+
+	db, _ := newFakeShard(t, 2, 1, 2)
+	rows := NewDBProxy(db, nil, nil)
+	if result, err := list.Query(ctx, WithData(rows), WithErrorOnEmpty(errNoOrders)); err != nil || len(result.Items) != 2 {
+		t.Errorf("expected rows without error, got %v, %v", result, err)
+	}
+	if _, err := list.Query(ctx, WithData(rows), WithNeedData(false), WithNeedTotal(true), WithErrorOnEmpty(errNoOrders)); err != nil {
+		t.Errorf("expected count-only query with total to pass, got %v", err)
+	}
+
+	list.UseErrorMapper(func(err error) error {
+		if errors.Is(err, errNoOrders) {
+			return errMapped
+		}
+		return nil
+	})
+	if _, err := list.QueryOne(ctx, WithData(proxy), WithErrorOnEmpty(errNoOrders)); !errors.Is(err, errMapped) {
+		t.Errorf("expected mapped error from QueryOne, got %v", err)
+	}
+}
+
+// TestListQuery_RequireFilter 测试 WithRequireFilter 拒绝未设置过滤条件的查询
+func TestListQuery_RequireFilter(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	if _, err := list.Query(ctx, WithData(proxy), WithRequireFilter()); !errors.Is(err, ErrMissingFilter) {
+		t.Fatalf("expected ErrMissingFilter, got %v", err)
+	}
+	if len(recorder.all()) != 0 {
+		t.Errorf("expected no statements for rejected query, got %v", recorder.all())
+	}
+	// 不产生 WHERE 条件的作用域不视为过滤条件
+	list.RegisterScope("ordered", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+	if _, err := list.Query(ctx, WithData(proxy), WithRequireFilter(), WithNamedScope("ordered")); !errors.Is(err, ErrMissingFilter) {
+		t.Errorf("expected ErrMissingFilter for scope without WHERE, got %v", err)
+	}
+	if _, err := list.Query(ctx, WithData(proxy), WithRequireFilter(), WithCondition("name", OpEq, "a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.all()) == 0 {
+		t.Error("expected filtered query to run")
+	}
+
+	// 拒绝时以 rejected 回调 DBCallHook
+	var ops []string
+	hook := WithDBCallHook(func(op string, _ time.Duration) {
+		ops = append(ops, op)
+	})
+	if _, err := list.Query(ctx, WithData(proxy), WithRequireFilter(), hook); !errors.Is(err, ErrMissingFilter) {
+		t.Errorf("expected ErrMissingFilter, got %v", err)
+	}
+	if !slices.Equal(ops, []string{DBCallRejected}) {
+		t.Errorf("expected a single rejected call, got %v", ops)
+	}
+
+	// 强制过滤条件不计入调用方的过滤条件
+	tenantList := NewList[GormTestEntity]()
+	tenantList.SetDataSource(Gorm)
+	tenantList.SetMandatoryFilter(func(context.Context) (any, error) {
+		return GormScope(func(db *gorm.DB) *gorm.DB { return db.Where("tenant_id = ?", 1) }), nil
+	})
+	if _, err := tenantList.Query(ctx, WithData(proxy), WithRequireFilter()); !errors.Is(err, ErrMissingFilter) {
+		t.Errorf("expected ErrMissingFilter with only the mandatory filter, got %v", err)
+	}
+	if _, err := tenantList.Query(ctx, WithData(proxy), WithRequireFilter(), WithCondition("name", OpEq, "a")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mongoBuilder := NewMongoBuilder[TestEntity](NewDBProxy(nil, &mongo.Collection{}, nil)).SetRequireFilter(true)
+	if err := mongoBuilder.builder.prepareAndValidate(); !errors.Is(err, ErrMissingFilter) {
+		t.Errorf("expected ErrMissingFilter for empty mongo filter, got %v", err)
+	}
+	mongoBuilder.SetFilter(bson.D{{Key: "status", Value: 1}})
+	if err := mongoBuilder.builder.prepareAndValidate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	esBuilder := NewElasticSearchBuilder[TestEntity](NewDBProxy(nil, nil, &elastic.Client{}), "index").
+		SetRequireFilter(true).SetFilter(elastic.NewMatchAllQuery())
+	if err := esBuilder.builder.prepareAndValidate(); !errors.Is(err, ErrMissingFilter) {
+		t.Errorf("expected ErrMissingFilter for match_all query, got %v", err)
+	}
+	esBuilder.SetFilter(elastic.NewTermQuery("status", 1))
+	if err := esBuilder.builder.prepareAndValidate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestListQuery_TotalOverride 测试 WithTotalOverride 跳过总数统计并优先于 needTotal 返回指定总数
+func TestListQuery_TotalOverride(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	for _, needTotal := range []bool{true, false} {
+		result, err := list.Query(ctx, WithData(proxy), WithNeedTotal(needTotal), WithTotalOverride(42))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Total != 42 || !result.HasTotal {
+			t.Errorf("needTotal=%v: expected overridden total 42, got total=%d hasTotal=%v", needTotal, result.Total, result.HasTotal)
+		}
+	}
+	for _, sql := range recorder.all() {
+		if strings.HasPrefix(sql, "SELECT count(*)") {
+			t.Errorf("expected count skipped, got %q", sql)
+		}
+	}
+}
+
+// TestListQuery_CountProxy 测试总数统计在独立的数据实例上执行，数据查询仍使用原数据实例
+func TestListQuery_CountProxy(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	countProxy, countRecorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	if _, err := list.Query(ctx, WithData(proxy), WithNeedTotal(true), WithCountProxy(countProxy)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, sql := range recorder.all() {
+		if strings.HasPrefix(sql, "SELECT count(*)") {
+			t.Errorf("expected count not to run on the data proxy, got %q", sql)
+		}
+	}
+	counts := countRecorder.all()
+	if len(counts) != 1 || !strings.HasPrefix(counts[0], "SELECT count(*)") {
+		t.Errorf("expected a single count on the count proxy, got %v", counts)
+	}
+
+	if _, err := list.Query(ctx, WithData(proxy), WithCountProxy(NewDBProxy(nil, nil, nil))); !errors.Is(err, ErrDataNotConfigured) {
+		t.Errorf("expected ErrDataNotConfigured for unconfigured count proxy, got %v", err)
+	}
+}
+
+// pageTokenQuerier 模拟以不透明 token 分页的自定义 Querier
+type pageTokenQuerier struct {
+	*MockQuerier[TestEntity]
+	token []byte
+}
+
+func (q *pageTokenQuerier) SetPageToken(token []byte) {
+	q.token = token
+}
+
+// TestListQueryPage_PageToken 测试分页 token 传给实现 PageTokenSetter 的查询器，内置构建器返回 ErrPageTokenUnsupported
+func TestListQueryPage_PageToken(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuerier := NewMockQuerier[TestEntity](ctrl)
+	querier := &pageTokenQuerier{MockQuerier: mockQuerier}
+	list := NewList[TestEntity]()
+	list.SetQuerier(querier)
+
+	mockQuerier.EXPECT().SetStart(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().SetLimit(uint32(2)).Return(querier)
+	mockQuerier.EXPECT().SetNeedPagination(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().SetNeedTotal(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().QueryPage(ctx).DoAndReturn(func(context.Context) (*core.CursorPageResult[TestEntity], error) {
+		if string(querier.token) != "key-1" {
+			t.Errorf("expected page token key-1 before the query, got %q", querier.token)
+		}
+		return &core.CursorPageResult[TestEntity]{HasMore: true, NextPageToken: []byte("key-2")}, nil
+	})
+
+	result, err := list.QueryPage(ctx, WithLimit(2), WithPageToken([]byte("key-1")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.NextPageToken) != "key-2" {
+		t.Errorf("expected next page token key-2, got %q", result.NextPageToken)
+	}
+
+	// 列表结果同样携带下一页 token；未传入 token 的查询清除上一次查询的 token
+	mockQuerier.EXPECT().SetStart(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().SetLimit(uint32(2)).Return(querier)
+	mockQuerier.EXPECT().SetNeedPagination(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().SetNeedTotal(gomock.Any()).Return(querier)
+	mockQuerier.EXPECT().QueryList(ctx).DoAndReturn(func(context.Context) (*core.ListResult[TestEntity], error) {
+		if querier.token != nil {
+			t.Errorf("expected page token cleared for a query without one, got %q", querier.token)
+		}
+		return &core.ListResult[TestEntity]{NextPageToken: []byte("key-3")}, nil
+	})
+	listResult, err := list.Query(ctx, WithLimit(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(listResult.NextPageToken) != "key-3" {
+		t.Errorf("expected list next page token key-3, got %q", listResult.NextPageToken)
+	}
+
+	gormList := NewList[GormTestEntity]()
+	gormList.SetDataSource(Gorm)
+	proxy, _ := newDryRunGormProxy(t)
+	if _, err := gormList.QueryPage(ctx, WithData(proxy), WithCursorField("id"), WithPageToken([]byte("key-1"))); !errors.Is(err, ErrPageTokenUnsupported) {
+		t.Errorf("expected ErrPageTokenUnsupported for built-in builder, got %v", err)
+	}
+}
+
+// TestListQuery_MergeSort 测试 WithMergeSort 在截取分页窗口前对合并后的分片结果重新排序
+func TestListQuery_MergeSort(t *testing.T) {
+	shard0, _ := newFakeShard(t, 3, 1, 4, 7)
+	shard1, _ := newFakeShard(t, 2, 2, 5)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	byID := func(a, b *GormTestEntity) bool { return a.ID < b.ID }
+	result, err := list.Query(ctx,
+		WithData(NewShardedDBProxy(shard0, shard1)),
+		WithLimit(3),
+		WithMergeSort(byID),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []uint32
+	for _, item := range result.Items {
+		ids = append(ids, item.ID)
+	}
+	if !slices.Equal(ids, []uint32{1, 2, 4}) {
+		t.Errorf("expected merge-sorted page [1 2 4], got %v", ids)
+	}
+
+	if _, err := list.Query(ctx, WithData(NewShardedDBProxy(shard0, shard1)), WithMergeSort(func(a, b *TestEntity) bool { return false })); !errors.Is(err, ErrMergeSortInvalid) {
+		t.Errorf("expected ErrMergeSortInvalid, got %v", err)
+	}
+
+	mongoList := NewList[GormTestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	if _, err := mongoList.Query(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)), WithMergeSort(byID)); !errors.Is(err, ErrMergeSortUnsupported) {
+		t.Errorf("expected ErrMergeSortUnsupported, got %v", err)
+	}
+}
@@ -2,7 +2,10 @@ package builder
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"slices"
 	"time"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
@@ -25,6 +28,42 @@ type Middleware[R any] func(
 	next func(context.Context) (core.Result[R], error),
 ) (core.Result[R], error)
 
+// Pipeline 有序的中间件组合，用于将可观测性、容错等常用中间件栈封装为可复用、可单独测试的整体
+// 通过 List.UsePipeline 一次性挂载，或通过 Middleware 合并为单个中间件供构建器的 Use 使用；
+// 先加入的中间件位于外层，与逐个调用 Use 的顺序一致
+type Pipeline[R any] []Middleware[R]
+
+// NewPipeline 按顺序创建中间件组合，nil 中间件会被忽略
+func NewPipeline[R any](middlewares ...Middleware[R]) Pipeline[R] {
+	pipeline := make(Pipeline[R], 0, len(middlewares))
+	for _, mw := range middlewares {
+		if mw != nil {
+			pipeline = append(pipeline, mw)
+		}
+	}
+	return pipeline
+}
+
+// Then 返回在当前组合之后追加 next 的新组合，不修改原组合
+func (p Pipeline[R]) Then(next ...Pipeline[R]) Pipeline[R] {
+	return slices.Concat(append([]Pipeline[R]{p}, next...)...)
+}
+
+// Middleware 将组合合并为单个中间件，执行顺序与逐个挂载相同；空组合直接调用 next
+func (p Pipeline[R]) Middleware() Middleware[R] {
+	middlewares := slices.Clone(p)
+	return func(ctx context.Context, builder Querier[R], next func(context.Context) (core.Result[R], error)) (core.Result[R], error) {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = func(mw Middleware[R], fn func(context.Context) (core.Result[R], error)) func(context.Context) (core.Result[R], error) {
+				return func(ctx context.Context) (core.Result[R], error) {
+					return mw(ctx, builder, fn)
+				}
+			}(middlewares[i], next)
+		}
+		return next(ctx)
+	}
+}
+
 // BeforeQueryHook 查询前置钩子函数类型
 // 参数:
 //
@@ -47,6 +86,36 @@ type BeforeQueryHook func(ctx context.Context) context.Context
 //	err: 错误信息
 type AfterQueryHook[R any] func(ctx context.Context, result core.Result[R], err error)
 
+// ResultValidator 结果行校验函数，在数据源返回后逐行调用，返回非 nil 错误时整个查询失败
+// 用于纵深防御，例如断言每行的租户 ID 与 ctx 中的租户一致，及时发现过滤条件缺陷导致的越权数据
+type ResultValidator[R any] func(ctx context.Context, item *R) error
+
+// ErrResultRejected 结果行未通过 ResultValidator 校验，可通过 errors.Is 判断，原始错误同样保留在错误链中
+var ErrResultRejected = errors.New("query result rejected")
+
+// ResultEnricher 结果集批量处理函数，在数据源返回（且通过 ResultValidator 校验）后以整批结果调用一次，
+// 可原地修改各行，适用于按本批全部行的 ID 一次性加载关联数据，避免逐行查询的 N+1 问题；返回非 nil 错误时查询失败
+type ResultEnricher[R any] func(ctx context.Context, items []*R) error
+
+// DedupKey 结果去重键函数，返回值需可比较（用作 map 键），键相同的行只保留首次出现的一行
+type DedupKey[R any] func(item *R) any
+
+// Counter 可替换的总数统计实现，用于按表或数据源选择精确、估算、缓存或封顶等不同的统计方式
+// exact 为数据源默认的精确统计（已应用 filter，配置 totalLimit 时最多统计到 totalLimit+1），可在缓存未命中等场景回退调用；
+// 返回值超出 totalLimit 时会被截断为上限并设置 ListResult.TotalCapped；
+// 返回的 isExact 为 false 时，ListResult.TotalEstimated 为 true
+type Counter[R any] interface {
+	Count(ctx context.Context, querier Querier[R], exact func(context.Context) (int64, error)) (total int64, isExact bool, err error)
+}
+
+// CounterFunc 允许使用函数快速实现 Counter
+type CounterFunc[R any] func(ctx context.Context, querier Querier[R], exact func(context.Context) (int64, error)) (int64, bool, error)
+
+// Count 实现 Counter
+func (f CounterFunc[R]) Count(ctx context.Context, querier Querier[R], exact func(context.Context) (int64, error)) (int64, bool, error) {
+	return f(ctx, querier, exact)
+}
+
 // middlewareRunner 中间件链执行器类型
 // 接收 ctx 和查询函数，返回经过中间件链处理后的结果
 type middlewareRunner[R any] func(ctx context.Context, queryFn func(context.Context) (core.Result[R], error)) (core.Result[R], error)
@@ -62,6 +131,15 @@ type middlewareProvider[R any] interface {
 	getQuerierRef() Querier[R]
 	getBeforeHook() BeforeQueryHook
 	getAfterHook() AfterQueryHook[R]
+	getCursorSigningKey() []byte
+	getTimingSink() *Timings
+	getTimeout() time.Duration
+	getLimiter() *PriorityLimiter
+	getPriority() QueryPriority
+	getResultValidator() ResultValidator[R]
+	getResultEnricher() ResultEnricher[R]
+	getDedupKey() DedupKey[R]
+	isEmptyResult() bool
 	setStartTime(t time.Time)
 }
 
@@ -71,16 +149,27 @@ type middlewareProvider[R any] interface {
 //
 //	R: 查询结果的实体类型
 type middlewareContext[R any] struct {
-	middlewares    []Middleware[R]   // 中间件链
-	querierRef     Querier[R]        // Querier 接口引用，传递给中间件
-	beforeHook     BeforeQueryHook   // 查询前置钩子
-	afterHook      AfterQueryHook[R] // 查询后置钩子
-	needTotal      bool              // 是否需要查询总数
-	needPagination bool              // 是否需要分页（游标查询时控制单批次/多批次）
-	limit          uint32            // 每页数据条数
-	cursorValues   []any             // 游标初始值
-	start          uint32            // 分页起始位置
-	onStartTime    func(time.Time)   // 回写查询开始时间
+	middlewares    []Middleware[R]    // 中间件链
+	querierRef     Querier[R]         // Querier 接口引用，传递给中间件
+	beforeHook     BeforeQueryHook    // 查询前置钩子
+	afterHook      AfterQueryHook[R]  // 查询后置钩子
+	needTotal      bool               // 是否需要查询总数
+	needPagination bool               // 是否需要分页（游标查询时控制单批次/多批次）
+	limit          uint32             // 每页数据条数
+	cursorValues   []any              // 游标初始值
+	start          uint32             // 分页起始位置
+	cursorKey      []byte             // 游标 token 签名密钥
+	dataSource     DataSource         // 数据源类型
+	queryMode      string             // 查询模式
+	timingSink     *Timings           // 查询耗时累加器
+	timeout        time.Duration      // 单次数据源访问的超时时间
+	limiter        *PriorityLimiter   // 按优先级调度的并发限制器
+	priority       QueryPriority      // 获取执行名额时使用的优先级
+	validator      ResultValidator[R] // 结果行校验函数
+	enricher       ResultEnricher[R]  // 结果集批量处理函数
+	dedupKey       DedupKey[R]        // 列表结果去重键函数
+	emptyResult    bool               // 过滤条件必然为空结果，跳过数据源访问
+	onStartTime    func(time.Time)    // 回写查询开始时间
 }
 
 // newMiddlewareContext 通过 middlewareProvider 接口提取中间件执行所需的状态快照
@@ -96,6 +185,17 @@ func newMiddlewareContext[R any](p middlewareProvider[R]) *middlewareContext[R]
 		limit:          meta.Limit,
 		cursorValues:   meta.CursorValues,
 		start:          meta.Start,
+		cursorKey:      p.getCursorSigningKey(),
+		dataSource:     meta.DataSource,
+		queryMode:      meta.QueryMode(),
+		timingSink:     p.getTimingSink(),
+		timeout:        p.getTimeout(),
+		limiter:        p.getLimiter(),
+		priority:       p.getPriority(),
+		validator:      p.getResultValidator(),
+		enricher:       p.getResultEnricher(),
+		dedupKey:       p.getDedupKey(),
+		emptyResult:    p.isEmptyResult(),
 		onStartTime:    p.setStartTime,
 	}
 }
@@ -137,7 +237,12 @@ func executeWithMiddlewares[R any](
 		ctx = mc.beforeHook(ctx)
 	}
 
-	result, err := buildRunner[R](mc)(ctx, queryFn)
+	if mc.emptyResult {
+		queryFn = func(context.Context) (core.Result[R], error) {
+			return &core.ListResult[R]{Items: []*R{}}, nil
+		}
+	}
+	result, err := buildRunner[R](mc)(ctx, enrichedQuery(mc, validatedQuery(mc, dedupedQuery(mc, limitedQuery(mc, timedQuery(mc, boundedQuery(mc, queryFn)))))))
 	invokeAfterHook[R](ctx, mc, result, err)
 	return result, err
 }
@@ -160,6 +265,7 @@ func executeCursorWithMiddlewares[R any](
 ) iter.Seq2[*R, error] {
 	// 游标字段默认值/合法性已在 prepareAndValidate 中统一处理。
 	ctx, batchSize, initialCursorValues, runChain := prepareCursorPipeline[R](ctx, mc)
+	cursorQueryFn = emptyResultFetch(mc, cursorQueryFn)
 
 	// 包装 fetchBatch，使每批次查询经过中间件链
 	wrappedFetch := func(ctx context.Context, cursorValues []any, isFirstBatch bool) ([]*R, []any, int64, bool, error) {
@@ -175,7 +281,7 @@ func executeCursorWithMiddlewares[R any](
 			}, err
 		}
 
-		result, err := runChain(ctx, queryFn)
+		result, err := runChain(ctx, enrichedQuery(mc, validatedQuery(mc, limitedQuery(mc, timedQuery(mc, boundedQuery(mc, queryFn))))))
 		if result == nil {
 			return nil, nextCursorValues, batchTotal, false, err
 		}
@@ -222,6 +328,16 @@ func executeCursorWithMiddlewares[R any](
 	}
 }
 
+// emptyResultFetch 过滤条件必然为空结果时，以不访问数据源的空批次替换游标分批查询函数
+func emptyResultFetch[R any](mc *middlewareContext[R], fetch cursorFetchBatch[R]) cursorFetchBatch[R] {
+	if !mc.emptyResult {
+		return fetch
+	}
+	return func(context.Context, []any, bool) ([]*R, []any, int64, bool, error) {
+		return []*R{}, nil, 0, false, nil
+	}
+}
+
 // executePageWithMiddlewares 执行单批次游标分页查询，返回结构化的分页结果
 // 封装"单批次游标查询 + 中间件链 + 前置/后置钩子 + HasMore 判断"的完整生命周期
 // 参数:
@@ -240,6 +356,7 @@ func executePageWithMiddlewares[R any](
 	pageFetchFn cursorFetchBatch[R],
 ) (*core.CursorPageResult[R], error) {
 	ctx, batchSize, initialCursorValues, runChain := prepareCursorPipeline[R](ctx, mc)
+	pageFetchFn = emptyResultFetch(mc, pageFetchFn)
 
 	// 单批次查询：先组装完整 CursorPageResult，再交给中间件链
 	queryFn := func(ctx context.Context) (core.Result[R], error) {
@@ -253,9 +370,12 @@ func executePageWithMiddlewares[R any](
 		return result, err
 	}
 
-	result, err := runChain(ctx, queryFn)
+	result, err := runChain(ctx, enrichedQuery(mc, validatedQuery(mc, limitedQuery(mc, timedQuery(mc, boundedQuery(mc, queryFn))))))
 	pageResult := cursorPageResultFromResult(result)
 	normalizeCursorPageResult(pageResult, batchSize)
+	if err == nil {
+		err = signCursorPageResult(pageResult, mc.cursorKey)
+	}
 	// 执行后置钩子
 	invokeAfterHook[R](ctx, mc, pageResult, err)
 	if err != nil {
@@ -265,6 +385,111 @@ func executePageWithMiddlewares[R any](
 	return pageResult, nil
 }
 
+// executeValuesQuery 执行值切片查询：不经过中间件链与钩子，但与列表查询一样受超时与优先级限制器约束，
+// 并依次执行去重、结果校验与批量处理；校验与批量处理直接作用于值切片中的元素，总数保持数据源统计值
+func executeValuesQuery[R any](
+	ctx context.Context,
+	mc *middlewareContext[R],
+	queryFn func(context.Context) ([]R, int64, error),
+) ([]R, int64, error) {
+	var (
+		values []R
+		total  int64
+	)
+	result, err := enrichedQuery(mc, validatedQuery(mc, dedupedQuery(mc, limitedQuery(mc, boundedQuery(mc,
+		func(ctx context.Context) (core.Result[R], error) {
+			var err error
+			if values, total, err = queryFn(ctx); err != nil {
+				return nil, err
+			}
+			items := make([]*R, len(values))
+			for i := range values {
+				items[i] = &values[i]
+			}
+			return &core.ListResult[R]{Items: items, Total: total}, nil
+		})))))(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 去重移除了部分行时按保留的行重建值切片
+	if items := result.GetItems(); len(items) != len(values) {
+		deduped := make([]R, len(items))
+		for i, item := range items {
+			deduped[i] = *item
+		}
+		values = deduped
+	}
+	return values, total, nil
+}
+
+// validatedQuery 包装最终查询函数，在配置了 ResultValidator 时逐行校验数据源返回的结果
+// 校验位于中间件链内侧，未通过校验的结果不会被缓存等中间件看到
+func validatedQuery[R any](mc *middlewareContext[R], queryFn func(context.Context) (core.Result[R], error)) func(context.Context) (core.Result[R], error) {
+	if mc.validator == nil {
+		return queryFn
+	}
+	return func(ctx context.Context) (core.Result[R], error) {
+		result, err := queryFn(ctx)
+		if err != nil || result == nil {
+			return result, err
+		}
+		for i, item := range result.GetItems() {
+			if err := mc.validator(ctx, item); err != nil {
+				return nil, fmt.Errorf("%w: row %d: %w", ErrResultRejected, i, err)
+			}
+		}
+		return result, nil
+	}
+}
+
+// dedupedQuery 包装列表查询函数，在配置了 DedupKey 时按键去除重复行并保留首次出现的行
+// 仅作用于 ListResult；needTotal 时 Total 为数据源统计的去重前行数，否则随去重后的条数调整
+func dedupedQuery[R any](mc *middlewareContext[R], queryFn func(context.Context) (core.Result[R], error)) func(context.Context) (core.Result[R], error) {
+	if mc.dedupKey == nil {
+		return queryFn
+	}
+	return func(ctx context.Context) (core.Result[R], error) {
+		result, err := queryFn(ctx)
+		list, ok := result.(*core.ListResult[R])
+		if err != nil || !ok || list == nil {
+			return result, err
+		}
+		seen := make(map[any]struct{}, len(list.Items))
+		items := list.Items[:0]
+		for _, item := range list.Items {
+			key := mc.dedupKey(item)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			items = append(items, item)
+		}
+		clear(list.Items[len(items):])
+		list.Items = items
+		if !mc.needTotal {
+			list.Total = int64(len(items))
+		}
+		return list, nil
+	}
+}
+
+// enrichedQuery 包装最终查询函数，在配置了 ResultEnricher 时以整批结果调用一次，空结果不调用
+func enrichedQuery[R any](mc *middlewareContext[R], queryFn func(context.Context) (core.Result[R], error)) func(context.Context) (core.Result[R], error) {
+	if mc.enricher == nil {
+		return queryFn
+	}
+	return func(ctx context.Context) (core.Result[R], error) {
+		result, err := queryFn(ctx)
+		if err != nil || result == nil || len(result.GetItems()) == 0 {
+			return result, err
+		}
+		if err := mc.enricher(ctx, result.GetItems()); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
 // invokeAfterHook 执行后置钩子的统一逻辑
 func invokeAfterHook[R any](ctx context.Context, mc *middlewareContext[R], result core.Result[R], err error) {
 	if mc.afterHook == nil {
@@ -293,6 +518,19 @@ func normalizeCursorPageResult[R any](result *core.CursorPageResult[R], batchSiz
 	}
 }
 
+// signCursorPageResult 在配置签名密钥时为下一页游标值生成签名 token
+func signCursorPageResult[R any](result *core.CursorPageResult[R], key []byte) error {
+	if result == nil || len(key) == 0 || len(result.NextCursorValues) == 0 {
+		return nil
+	}
+	token, err := EncodeCursor(key, result.NextCursorValues)
+	if err != nil {
+		return err
+	}
+	result.NextCursorToken = token
+	return nil
+}
+
 // cursorPageResultFromResult 根据通用 Result[R] 组装 *CursorPageResult[R]
 func cursorPageResultFromResult[R any](result core.Result[R]) *core.CursorPageResult[R] {
 	if result == nil {
@@ -306,13 +544,34 @@ func cursorPageResultFromResult[R any](result core.Result[R]) *core.CursorPageRe
 	}
 }
 
+// finishListResult 根据查询配置补充列表结果的总数状态与分页信息
+// 在中间件链之后执行，因此缓存命中等短路返回的结果同样带有完整信息
+func (b *builder[B, R]) finishListResult(result *core.ListResult[R]) *core.ListResult[R] {
+	if result == nil {
+		return nil
+	}
+	if b.totalOverride != nil {
+		result.Total = *b.totalOverride
+	}
+	hasTotal := b.needTotal || b.totalOverride != nil
+	result.HasTotal = hasTotal && !b.totalTimedOut
+	// 覆盖的总数为精确值；统计结果恰好等于上限时同样是精确值，仅在统计确实超出上限被截断时标记
+	result.TotalCapped = b.needTotal && b.totalOverride == nil && b.totalCapped
+	result.TotalEstimated = b.needTotal && b.totalEstimated
+	if b.needPagination {
+		result.Pagination = &core.Pagination{Start: b.start, Limit: b.effectiveLimit(), RequestedLimit: b.requestedLimit}
+	}
+	return result
+}
+
 // listResultFromResult 根据通用 Result[R] 组装 *ListResult[R]
 func listResultFromResult[R any](result core.Result[R]) *core.ListResult[R] {
 	if result == nil {
 		return nil
 	}
 	return &core.ListResult[R]{
-		Items: result.GetItems(),
-		Total: result.GetTotal(),
+		Items:   result.GetItems(),
+		Total:   result.GetTotal(),
+		HasMore: result.GetHasMore(),
 	}
 }
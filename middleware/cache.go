@@ -25,14 +25,16 @@ type cacheResult[R any] struct {
 	Total            int64           `json:"total"`
 	HasMore          bool            `json:"has_more"`
 	NextCursorValues []any           `json:"next_cursor_values"`
+	NextPageToken    []byte          `json:"next_page_token,omitempty"`
 }
 
 // toResult 将 cacheResult 转换为 core.Result[R]，根据 Kind 字段区分 CursorPageResult 和 ListResult
 func (r cacheResult[R]) toResult() core.Result[R] {
 	if r.Kind == core.ResultKindList {
 		return &core.ListResult[R]{
-			Items: r.Items,
-			Total: r.Total,
+			Items:         r.Items,
+			Total:         r.Total,
+			NextPageToken: r.NextPageToken,
 		}
 	}
 	return &core.CursorPageResult[R]{
@@ -40,6 +42,7 @@ func (r cacheResult[R]) toResult() core.Result[R] {
 		Total:            r.Total,
 		HasMore:          r.HasMore,
 		NextCursorValues: r.NextCursorValues,
+		NextPageToken:    r.NextPageToken,
 	}
 }
 
@@ -48,13 +51,24 @@ func cacheResultFromResult[R any](result core.Result[R]) cacheResult[R] {
 	if result == nil {
 		return cacheResult[R]{Kind: core.ResultKindList}
 	}
-	return cacheResult[R]{
+	cached := cacheResult[R]{
 		Kind:             result.GetResultKind(),
 		Items:            result.GetItems(),
 		Total:            result.GetTotal(),
 		HasMore:          result.GetHasMore(),
 		NextCursorValues: result.GetNextCursorValues(),
 	}
+	switch r := result.(type) {
+	case *core.CursorPageResult[R]:
+		if r != nil {
+			cached.NextPageToken = r.NextPageToken
+		}
+	case *core.ListResult[R]:
+		if r != nil {
+			cached.NextPageToken = r.NextPageToken
+		}
+	}
+	return cached
 }
 
 // CacheMiddlewareWithKeyBuilder 使用 CacheKeyBuilder 构建缓存键。
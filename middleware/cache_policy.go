@@ -44,7 +44,7 @@ func (b DefaultCacheKeyBuilder) Build(ctx context.Context, meta core.QueryMeta)
 	payload := map[string]any{"prefix": b.Prefix}
 	payload["datasource"] = meta.DataSource
 	payload["fields"] = append([]string(nil), meta.Fields...)
-	payload["pagination"] = map[string]any{
+	pagination := map[string]any{
 		"start":          meta.Start,
 		"limit":          meta.Limit,
 		"needTotal":      meta.NeedTotal,
@@ -54,6 +54,15 @@ func (b DefaultCacheKeyBuilder) Build(ctx context.Context, meta core.QueryMeta)
 		"isPITQuery":     meta.IsPITQuery,
 		"cursorFields":   append([]string(nil), meta.CursorFields...),
 	}
+	// 仅统计总数的查询不能与完整列表共享缓存；未启用时不写入，保持既有缓存键不变
+	if meta.SkipData {
+		pagination["skipData"] = true
+	}
+	// 不同分页 token 对应不同的页；未设置时不写入，保持既有缓存键不变
+	if len(meta.PageToken) > 0 {
+		pagination["pageToken"] = meta.PageToken
+	}
+	payload["pagination"] = pagination
 
 	// 确定 hints：优先使用静态 Hints，为空时尝试 HintsProvider
 	hints := b.Hints
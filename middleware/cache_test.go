@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"iter"
 	"testing"
 	"time"
@@ -106,6 +107,43 @@ func TestDefaultCacheKeyBuilderPrefixIsolation(t *testing.T) {
 	}
 }
 
+func TestDefaultCacheKeyBuilderSkipDataIsolation(t *testing.T) {
+	ctx := context.Background()
+	meta := baseMeta()
+	countOnly := baseMeta()
+	countOnly.SkipData = true
+	k1 := DefaultCacheKeyBuilder{Prefix: "users"}.Build(ctx, meta)
+	k2 := DefaultCacheKeyBuilder{Prefix: "users"}.Build(ctx, countOnly)
+	if k1 == k2 {
+		t.Fatalf("expected keys to differ for count-only query")
+	}
+}
+
+func TestDefaultCacheKeyBuilderPageTokenIsolation(t *testing.T) {
+	ctx := context.Background()
+	first := baseMeta()
+	next := baseMeta()
+	next.PageToken = []byte(`{"pk":"user#1"}`)
+	k1 := DefaultCacheKeyBuilder{Prefix: "users"}.Build(ctx, first)
+	k2 := DefaultCacheKeyBuilder{Prefix: "users"}.Build(ctx, next)
+	if k1 == k2 {
+		t.Fatalf("expected keys to differ for different page tokens")
+	}
+}
+
+func TestCacheResultKeepsNextPageToken(t *testing.T) {
+	cached := cacheResultFromResult[testUser](&core.CursorPageResult[testUser]{HasMore: true, NextPageToken: []byte("key-2")})
+	page, ok := cached.toResult().(*core.CursorPageResult[testUser])
+	if !ok || string(page.NextPageToken) != "key-2" {
+		t.Fatalf("expected cached page to keep next page token, got %+v", cached.toResult())
+	}
+	cached = cacheResultFromResult[testUser](&core.ListResult[testUser]{NextPageToken: []byte("key-3")})
+	list, ok := cached.toResult().(*core.ListResult[testUser])
+	if !ok || string(list.NextPageToken) != "key-3" {
+		t.Fatalf("expected cached list to keep next page token, got %+v", cached.toResult())
+	}
+}
+
 func TestDefaultCacheKeyBuilderWithoutHints(t *testing.T) {
 	ctx := context.Background()
 	meta := baseMeta()
@@ -274,3 +312,117 @@ func TestCloneCacheIsolation(t *testing.T) {
 		t.Fatalf("cache for k1 should not be accessible via k2")
 	}
 }
+
+// countingQuerier 记录 QueryList/QueryPage 调用次数，Explain 返回可配置的查询签名
+type countingQuerier struct {
+	mockQuerier[testUser]
+	statement string
+	calls     int
+}
+
+func (c *countingQuerier) Explain(_ context.Context) (string, error) { return c.statement, nil }
+func (c *countingQuerier) QueryList(_ context.Context) (*core.ListResult[testUser], error) {
+	c.calls++
+	return &core.ListResult[testUser]{Items: []*testUser{{ID: 1, Name: "alice"}}, Total: 1, HasTotal: true}, nil
+}
+func (c *countingQuerier) QueryPage(_ context.Context) (*core.CursorPageResult[testUser], error) {
+	c.calls++
+	return &core.CursorPageResult[testUser]{Items: []*testUser{{ID: 2}}, HasMore: true}, nil
+}
+
+// tokenQuerier 接收分页 token 但不在 QueryMeta 中回填的自定义 Querier
+type tokenQuerier struct {
+	countingQuerier
+	token []byte
+}
+
+func (q *tokenQuerier) SetPageToken(token []byte) { q.token = token }
+
+type recordingCodec struct{ marshals, unmarshals int }
+
+func (r *recordingCodec) Marshal(v any) ([]byte, error) {
+	r.marshals++
+	return json.Marshal(v)
+}
+func (r *recordingCodec) Unmarshal(data []byte, v any) error {
+	r.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestCachedQuerier_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	cache := newMockCache()
+	codec := &recordingCodec{}
+	inner := &countingQuerier{mockQuerier: mockQuerier[testUser]{meta: baseMeta()}, statement: "SELECT * FROM users WHERE age > 18"}
+	cached := NewCachedQuerier[testUser](inner, cache, time.Minute).SetKeyPrefix("users").SetCodec(codec)
+
+	for range 2 {
+		result, err := cached.QueryList(ctx)
+		if err != nil || len(result.Items) != 1 || result.Items[0].Name != "alice" || !result.HasTotal {
+			t.Fatalf("unexpected result: %+v, %v", result, err)
+		}
+	}
+	if inner.calls != 1 || codec.marshals != 1 || codec.unmarshals != 1 {
+		t.Fatalf("expected one miss then one hit, got %d calls, codec %+v", inner.calls, codec)
+	}
+
+	// 列表与单批次分页、不同查询签名均不共享缓存键
+	if page, err := cached.QueryPage(ctx); err != nil || !page.HasMore || inner.calls != 2 {
+		t.Fatalf("expected page query to miss, got %+v, %v, %d calls", page, err, inner.calls)
+	}
+	inner.statement = "SELECT * FROM users WHERE age > 30"
+	if _, err := cached.QueryList(ctx); err != nil || inner.calls != 3 {
+		t.Fatalf("expected different signature to miss, got %v, %d calls", err, inner.calls)
+	}
+	if len(cache.store) != 3 {
+		t.Fatalf("expected 3 cache entries, got %d", len(cache.store))
+	}
+}
+
+func TestCachedQuerier_PageTokenIsolation(t *testing.T) {
+	ctx := context.Background()
+	cache := newMockCache()
+	inner := &countingQuerier{mockQuerier: mockQuerier[testUser]{meta: baseMeta()}, statement: "SCAN users"}
+	cached := NewCachedQuerier[testUser](inner, cache, time.Minute)
+
+	// Explain 不包含 token 时，不同 token 的页仍使用不同的缓存键
+	for _, token := range []string{"", "key-1", "key-2", "key-1"} {
+		inner.meta.PageToken = []byte(token)
+		if _, err := cached.QueryPage(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if inner.calls != 3 || len(cache.store) != 3 {
+		t.Fatalf("expected one miss per page token, got %d calls and %d entries", inner.calls, len(cache.store))
+	}
+}
+
+func TestCachedQuerier_SetPageToken(t *testing.T) {
+	ctx := context.Background()
+	cache := newMockCache()
+	inner := &tokenQuerier{countingQuerier: countingQuerier{mockQuerier: mockQuerier[testUser]{meta: baseMeta()}, statement: "SCAN users"}}
+	cached := NewCachedQuerier[testUser](inner, cache, time.Minute)
+
+	// 被包装的 Querier 未回填 QueryMeta.PageToken 时，缓存键仍按 SetPageToken 传入的 token 区分各页
+	for _, token := range []string{"", "key-1", "key-2", "key-1"} {
+		cached.SetPageToken([]byte(token))
+		if string(inner.token) != token {
+			t.Fatalf("expected token %q passed to the wrapped querier, got %q", token, inner.token)
+		}
+		if _, err := cached.QueryList(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if inner.calls != 3 || len(cache.store) != 3 {
+		t.Fatalf("expected one miss per page token, got %d calls and %d entries", inner.calls, len(cache.store))
+	}
+	if meta := cached.GetQueryMeta(); string(meta.PageToken) != "key-1" {
+		t.Errorf("expected query meta to carry the page token, got %q", meta.PageToken)
+	}
+
+	plain := NewCachedQuerier[testUser](&countingQuerier{mockQuerier: mockQuerier[testUser]{meta: baseMeta()}}, cache, time.Minute)
+	plain.SetPageToken([]byte("key-1"))
+	if _, err := plain.QueryPage(ctx); !errors.Is(err, builder.ErrPageTokenUnsupported) {
+		t.Errorf("expected ErrPageTokenUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+)
+
+// CacheCodec 缓存结果的序列化接口，可替换为 msgpack、protobuf 等实现，默认使用 JSON
+type CacheCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonCodec 默认的 JSON 序列化实现
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// CachedQuerier 带读穿缓存的组合 Querier，包装任意数据源的 Querier
+// QueryList/QueryPage 先按构建器签名（Explain 生成的查询语句与分页元信息）查缓存，命中时直接返回，
+// 未命中时执行被包装的 Querier 并回填缓存；QueryCursor 等其余方法直接透传。
+// 与 CacheMiddleware 不同，命中缓存时不会执行被包装 Querier 的钩子与中间件链；
+// 配置方法返回被包装的 Querier，需在包装前完成 filter/sort/分页等配置；
+// List 经 SetPageToken 传入的分页 token 由 CachedQuerier 自行记录并计入缓存键，不依赖被包装 Querier 回填 QueryMeta
+type CachedQuerier[R any] struct {
+	builder.Querier[R]
+	cache     CacheProvider
+	ttl       time.Duration
+	codec     CacheCodec
+	prefix    string
+	pageToken []byte // List 经 SetPageToken 传入的分页 token
+}
+
+// NewCachedQuerier 创建带读穿缓存的组合 Querier
+func NewCachedQuerier[R any](querier builder.Querier[R], cache CacheProvider, ttl time.Duration) *CachedQuerier[R] {
+	return &CachedQuerier[R]{
+		Querier: querier,
+		cache:   cache,
+		ttl:     ttl,
+		codec:   jsonCodec{},
+	}
+}
+
+// SetCodec 设置缓存结果的序列化实现，nil 时恢复默认的 JSON
+func (c *CachedQuerier[R]) SetCodec(codec CacheCodec) *CachedQuerier[R] {
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+	c.codec = codec
+	return c
+}
+
+// SetKeyPrefix 设置缓存键前缀，建议使用业务资源名（如 "users"），避免不同实体类型共享 key 空间
+func (c *CachedQuerier[R]) SetKeyPrefix(prefix string) *CachedQuerier[R] {
+	c.prefix = prefix
+	return c
+}
+
+// SetPageToken 记录分页 token 作为缓存键的一部分，并传给实现 builder.PageTokenSetter 的被包装 Querier；
+// 被包装的 Querier 不支持时，携带 token 的 QueryList/QueryPage 返回 builder.ErrPageTokenUnsupported
+func (c *CachedQuerier[R]) SetPageToken(token []byte) {
+	c.pageToken = token
+	if setter, ok := c.Querier.(builder.PageTokenSetter); ok {
+		setter.SetPageToken(token)
+	}
+}
+
+// GetQueryMeta 返回被包装 Querier 的查询元信息，PageToken 取 SetPageToken 记录的 token
+func (c *CachedQuerier[R]) GetQueryMeta() builder.QueryMeta {
+	meta := c.Querier.GetQueryMeta()
+	if len(c.pageToken) > 0 {
+		meta.PageToken = c.pageToken
+	}
+	return meta
+}
+
+// QueryList 先查缓存，未命中时执行被包装 Querier 的 QueryList 并回填缓存
+func (c *CachedQuerier[R]) QueryList(ctx context.Context) (*core.ListResult[R], error) {
+	return readThrough(ctx, c, core.ResultKindList, c.Querier.QueryList)
+}
+
+// QueryPage 先查缓存，未命中时执行被包装 Querier 的 QueryPage 并回填缓存
+func (c *CachedQuerier[R]) QueryPage(ctx context.Context) (*core.CursorPageResult[R], error) {
+	return readThrough(ctx, c, core.ResultKindCursorPage, c.Querier.QueryPage)
+}
+
+// readThrough 读穿缓存的统一逻辑：签名生成失败时不使用缓存，缓存数据无法反序列化时视为未命中
+func readThrough[R any, T any](
+	ctx context.Context,
+	c *CachedQuerier[R],
+	kind core.ResultKind,
+	query func(context.Context) (*T, error),
+) (*T, error) {
+	if _, ok := c.Querier.(builder.PageTokenSetter); !ok && len(c.pageToken) > 0 {
+		return nil, builder.ErrPageTokenUnsupported
+	}
+	key, err := c.cacheKey(ctx, kind)
+	if err != nil {
+		return query(ctx)
+	}
+	if data, ok := c.cache.Get(ctx, key); ok {
+		var cached T
+		if err := c.codec.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	result, err := query(ctx)
+	if err != nil || result == nil {
+		return result, err
+	}
+	if data, err := c.codec.Marshal(result); err == nil {
+		c.cache.Set(ctx, key, data, c.ttl)
+	}
+	return result, nil
+}
+
+// cacheKey 根据构建器签名生成缓存键，格式为 "qb:cache:<hex>"
+// 签名由 Explain 生成的完整查询语句（含过滤、排序与参数）与分页元信息组成，相同查询在不同实例间得到相同的 key
+func (c *CachedQuerier[R]) cacheKey(ctx context.Context, kind core.ResultKind) (string, error) {
+	statement, err := c.Querier.Explain(ctx)
+	if err != nil {
+		return "", err
+	}
+	meta := c.GetQueryMeta()
+	signature := map[string]any{
+		"prefix":         c.prefix,
+		"kind":           kind.String(),
+		"datasource":     meta.DataSource,
+		"statement":      statement,
+		"fields":         meta.Fields,
+		"start":          meta.Start,
+		"limit":          meta.Limit,
+		"needTotal":      meta.NeedTotal,
+		"totalLimit":     meta.TotalLimit,
+		"needPagination": meta.NeedPagination,
+		"skipData":       meta.SkipData,
+		"cursorFields":   meta.CursorFields,
+		"cursorValues":   meta.CursorValues,
+	}
+	// 不同分页 token 对应不同的页；未设置时不写入，保持既有缓存键不变
+	if len(meta.PageToken) > 0 {
+		signature["pageToken"] = meta.PageToken
+	}
+	canonical, err := canonicalJSON(signature)
+	if err != nil {
+		return "", fmt.Errorf("build cache key: %w", err)
+	}
+	h := sha1.Sum([]byte(canonical))
+	return "qb:cache:" + hex.EncodeToString(h[:]), nil
+}
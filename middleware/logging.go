@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+)
+
+// LoggingMiddlewareFromContext 创建从查询 context 中提取 *slog.Logger 写日志的中间件。
+// 许多框架会把携带请求 ID、用户等字段的请求级 logger 存入 context，
+// 中间件按 key 取出该 logger，使每条查询日志自动带上这些字段；context 中没有时使用 fallback，fallback 为 nil 时使用 slog.Default()。
+// 需要同时记录指标或链路时，可将 SlogQueryLogger 作为 ObservabilityOptions.Logger 使用。
+func LoggingMiddlewareFromContext[R any](key any, fallback *slog.Logger) builder.Middleware[R] {
+	return ObservabilityMiddleware[R](ObservabilityOptions{Logger: SlogQueryLogger(key, fallback)})
+}
+
+// SlogQueryLogger 返回写入 slog 的 QueryLogger，logger 的选取规则同 LoggingMiddlewareFromContext。
+// 查询成功时以 Info 级别记录，失败时以 Error 级别记录并附加 error 字段，事件属性逐一转换为日志字段。
+func SlogQueryLogger(key any, fallback *slog.Logger) QueryLogger {
+	return QueryLoggerFunc(func(ctx context.Context, event QueryEvent) {
+		logger := fallback
+		if l, ok := ctx.Value(key).(*slog.Logger); ok && l != nil {
+			logger = l
+		}
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		level := slog.LevelInfo
+		attrs := make([]slog.Attr, 0, len(event.Attributes)+3)
+		attrs = append(attrs,
+			slog.String("querybuilder.operation", event.Operation),
+			slog.Duration("querybuilder.duration", event.Duration),
+		)
+		if event.Error != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.Any("error", event.Error))
+		}
+		for _, attr := range event.Attributes {
+			attrs = append(attrs, slog.Any(attr.Key, attr.Value))
+		}
+		logger.LogAttrs(ctx, level, "querybuilder query", attrs...)
+	})
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+)
+
+type loggerKey struct{}
+
+func TestLoggingMiddlewareFromContext(t *testing.T) {
+	var requestBuf, fallbackBuf bytes.Buffer
+	requestLogger := slog.New(slog.NewTextHandler(&requestBuf, nil)).With("request_id", "req-1")
+	fallback := slog.New(slog.NewTextHandler(&fallbackBuf, nil))
+	mq := &mockQuerier[testUser]{meta: baseMeta()}
+	mw := LoggingMiddlewareFromContext[testUser](loggerKey{}, fallback)
+
+	ctx := context.WithValue(context.Background(), loggerKey{}, requestLogger)
+	_, err := mw(ctx, mq, func(ctx context.Context) (core.Result[testUser], error) {
+		return &core.ListResult[testUser]{Items: []*testUser{{ID: 1}}, Total: 1}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	line := requestBuf.String()
+	for _, want := range []string{"level=INFO", "request_id=req-1", "querybuilder.operation=querybuilder.Gorm.list", "querybuilder.item_count=1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in request log, got %q", want, line)
+		}
+	}
+	if fallbackBuf.Len() != 0 {
+		t.Errorf("expected fallback logger unused, got %q", fallbackBuf.String())
+	}
+
+	expectedErr := errors.New("boom")
+	_, err = mw(context.Background(), mq, func(ctx context.Context) (core.Result[testUser], error) {
+		return nil, expectedErr
+	})
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected original error, got %v", err)
+	}
+	line = fallbackBuf.String()
+	if !strings.Contains(line, "level=ERROR") || !strings.Contains(line, "error=boom") {
+		t.Errorf("expected error logged to fallback logger, got %q", line)
+	}
+	if strings.Contains(line, "request_id") {
+		t.Errorf("expected no request-scoped fields without context logger, got %q", line)
+	}
+}
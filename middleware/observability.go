@@ -155,6 +155,8 @@ type ObservabilityOptions struct {
 	MetricsFilter QueryEventFilter
 	// TraceFilter 控制单次查询是否创建链路 span；为 nil 时只要 Tracer 非 nil 就创建 span。
 	TraceFilter QueryMetaFilter
+	// RecordStatement 为 true 时，在 span 启动属性中附加脱敏后的 db.statement（仅 GORM 构建器，字面量替换为 ?）。
+	RecordStatement bool
 	// SignalOrder 控制查询完成后的信号分发顺序；为空时使用 trace -> metrics -> logger。
 	SignalOrder []ObservabilitySignal
 	// OperationNameBuilder 自定义 operation 名称；为 nil 或返回空字符串时使用 DefaultOperationName。
@@ -209,11 +211,15 @@ func ObservabilityMiddleware[R any](opts ObservabilityOptions) builder.Middlewar
 
 		var span QuerySpan
 		if traceEnabled {
+			spanAttrs := cloneAttributes(attrs)
+			if opts.RecordStatement {
+				spanAttrs = append(spanAttrs, statementAttributes(ctx, b)...)
+			}
 			ctx, span = safeStartQuery(ctx, opts.Tracer, QuerySpanStart{
 				Operation:  operation,
 				Meta:       meta,
 				StartTime:  startTime,
-				Attributes: cloneAttributes(attrs),
+				Attributes: spanAttrs,
 			})
 		}
 		if span == nil && !hasPostSignals {
@@ -375,7 +381,7 @@ func buildQueryEvent[R any](input queryEventBuildInput[R]) (event QueryEvent) {
 
 // defaultQueryAttributes 返回官方默认低敏属性集合。
 func defaultQueryAttributes(meta core.QueryMeta) []Attribute {
-	return []Attribute{
+	attrs := []Attribute{
 		{Key: "querybuilder.datasource", Value: meta.DataSource.String()},
 		{Key: "querybuilder.mode", Value: meta.QueryMode()},
 		{Key: "querybuilder.pit", Value: meta.IsPITQuery},
@@ -385,6 +391,28 @@ func defaultQueryAttributes(meta core.QueryMeta) []Attribute {
 		{Key: "querybuilder.start", Value: meta.Start},
 		{Key: "querybuilder.limit", Value: meta.Limit},
 	}
+	if meta.QueryName != "" {
+		attrs = append(attrs, Attribute{Key: "querybuilder.query_name", Value: meta.QueryName})
+	}
+	return attrs
+}
+
+// statementRedactor 可生成脱敏 SQL 的构建器（如 GormBuilder）
+type statementRedactor interface {
+	RedactedStatement(ctx context.Context) (string, error)
+}
+
+// statementAttributes 返回 db.statement 属性，构建器不支持或生成失败时返回空
+func statementAttributes(ctx context.Context, querier any) []Attribute {
+	redactor, ok := querier.(statementRedactor)
+	if !ok {
+		return nil
+	}
+	statement, err := redactor.RedactedStatement(ctx)
+	if err != nil || statement == "" {
+		return nil
+	}
+	return []Attribute{{Key: "db.statement", Value: statement}}
 }
 
 // resultAttributes 返回与查询结果和错误状态相关的属性集合。
@@ -3,9 +3,13 @@ package middleware
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	builder "github.com/fantasticbin/QueryBuilder/v2"
 	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
 )
 
 type recordingLogger struct {
@@ -502,6 +506,36 @@ func TestObservabilityMiddlewarePITCursorEvent(t *testing.T) {
 	}
 }
 
+func TestObservabilityMiddlewareQueryNameAttribute(t *testing.T) {
+	metrics := &recordingMetrics{}
+	mw := ObservabilityMiddleware[testUser](ObservabilityOptions{Metrics: metrics})
+	next := func(ctx context.Context) (core.Result[testUser], error) {
+		return &core.ListResult[testUser]{}, nil
+	}
+
+	unnamed := &mockQuerier[testUser]{meta: baseMeta()}
+	if _, err := mw(context.Background(), unnamed, next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	meta := baseMeta()
+	meta.QueryName = "users.list"
+	named := &mockQuerier[testUser]{meta: meta}
+	if _, err := mw(context.Background(), named, next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metrics.events) != 2 {
+		t.Fatalf("expected two metric events, got %d", len(metrics.events))
+	}
+	if hasAttribute(metrics.events[0].Attributes, "querybuilder.query_name") {
+		t.Fatalf("unnamed query must not carry a query_name attribute: %+v", metrics.events[0].Attributes)
+	}
+	if attrValue(metrics.events[1].Attributes, "querybuilder.query_name") != "users.list" {
+		t.Fatalf("expected query_name attribute, got %+v", metrics.events[1].Attributes)
+	}
+}
+
 func TestObservabilityMiddlewareCursorPageAndSensitiveDefaults(t *testing.T) {
 	logger := &recordingLogger{}
 	meta := baseMeta()
@@ -541,6 +575,43 @@ func TestObservabilityMiddlewareCursorPageAndSensitiveDefaults(t *testing.T) {
 	}
 }
 
+func TestObservabilityMiddlewareRecordStatement(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	b := builder.NewGormBuilder[testUser](builder.NewDBProxy(db, nil, nil))
+	b.SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("email = 'a@example.com'")
+	})
+
+	tracer := &recordingTracer{}
+	mw := ObservabilityMiddleware[testUser](ObservabilityOptions{Tracer: tracer, RecordStatement: true})
+	if _, err := mw(context.Background(), b, func(ctx context.Context) (core.Result[testUser], error) {
+		return &core.ListResult[testUser]{}, nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statement, _ := attrValue(tracer.starts[0].Attributes, "db.statement").(string)
+	if !strings.Contains(statement, "email = ?") || strings.Contains(statement, "example.com") {
+		t.Fatalf("expected redacted db.statement, got %q", statement)
+	}
+	if hasAttribute(tracer.span.events[0].Attributes, "db.statement") {
+		t.Fatal("db.statement must only be attached to the span start")
+	}
+
+	tracer = &recordingTracer{}
+	mw = ObservabilityMiddleware[testUser](ObservabilityOptions{Tracer: tracer})
+	if _, err := mw(context.Background(), b, func(ctx context.Context) (core.Result[testUser], error) {
+		return &core.ListResult[testUser]{}, nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasAttribute(tracer.starts[0].Attributes, "db.statement") {
+		t.Fatal("db.statement must be opt-in")
+	}
+}
+
 func attrValue(attrs []Attribute, key string) any {
 	for _, attr := range attrs {
 		if attr.Key == key {
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+)
+
+// SlowQueryMiddleware 创建只记录慢查询的中间件：查询耗时达到 threshold 时向 logger 写入一条事件，
+// 事件属性包含默认属性（数据源、查询模式、WithQueryName 设置的查询名称等）与 querybuilder.slow_threshold，
+// GORM 构建器还会附加 db.statement（RedactedStatement 生成的脱敏 SQL，仅在判定为慢查询后生成）；
+// 相比记录全部查询更便于定位高开销查询，可搭配 SlogQueryLogger 写入专用的慢查询 logger。logger 为 nil 时不做任何记录。
+func SlowQueryMiddleware[R any](threshold time.Duration, logger QueryLogger) builder.Middleware[R] {
+	return func(
+		ctx context.Context,
+		b builder.Querier[R],
+		next func(context.Context) (core.Result[R], error),
+	) (core.Result[R], error) {
+		if logger == nil {
+			return next(ctx)
+		}
+		meta := b.GetQueryMeta()
+		startTime := time.Now()
+		result, err := next(ctx)
+		if time.Since(startTime) < threshold {
+			return result, err
+		}
+
+		attrs := append(defaultQueryAttributes(meta), Attribute{Key: "querybuilder.slow_threshold", Value: threshold})
+		attrs = append(attrs, statementAttributes(ctx, b)...)
+		event := buildQueryEvent(queryEventBuildInput[R]{
+			operation:       DefaultOperationName(meta),
+			meta:            meta,
+			startTime:       startTime,
+			result:          result,
+			err:             err,
+			errorClassifier: DefaultErrorClassifier,
+			baseAttrs:       attrs,
+		})
+		safeObserve(func() {
+			logger.LogQuery(ctx, event)
+		})
+		return result, err
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	builder "github.com/fantasticbin/QueryBuilder/v2"
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestSlowQueryMiddleware(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry run db failed: %v", err)
+	}
+	b := builder.NewGormBuilder[testUser](builder.NewDBProxy(db, nil, nil))
+	b.SetFilter(func(db *gorm.DB) *gorm.DB {
+		return db.Where("email = 'a@example.com'")
+	})
+
+	var events []QueryEvent
+	logger := QueryLoggerFunc(func(ctx context.Context, event QueryEvent) {
+		events = append(events, event)
+	})
+	mw := SlowQueryMiddleware[testUser](20*time.Millisecond, logger)
+
+	fast := func(ctx context.Context) (core.Result[testUser], error) {
+		return &core.ListResult[testUser]{}, nil
+	}
+	if _, err := mw(context.Background(), b, fast); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected fast query not logged, got %d events", len(events))
+	}
+
+	slow := func(ctx context.Context) (core.Result[testUser], error) {
+		time.Sleep(25 * time.Millisecond)
+		return &core.ListResult[testUser]{Items: []*testUser{{ID: 1}}, Total: 1}, nil
+	}
+	if _, err := mw(context.Background(), b, slow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected slow query logged once, got %d events", len(events))
+	}
+	event := events[0]
+	if event.Duration < 20*time.Millisecond || event.Operation != "querybuilder.Gorm.list" || event.ItemCount != 1 {
+		t.Errorf("unexpected slow query event: %+v", event)
+	}
+	if attrValue(event.Attributes, "querybuilder.slow_threshold") != 20*time.Millisecond {
+		t.Errorf("expected threshold attribute, got %+v", event.Attributes)
+	}
+	statement, _ := attrValue(event.Attributes, "db.statement").(string)
+	if !strings.Contains(statement, "email = ?") || strings.Contains(statement, "example.com") {
+		t.Errorf("expected redacted db.statement, got %q", statement)
+	}
+
+	// 非 GORM 构建器不附加 db.statement
+	events = nil
+	if _, err := mw(context.Background(), &mockQuerier[testUser]{meta: baseMeta()}, slow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || hasAttribute(events[0].Attributes, "db.statement") {
+		t.Errorf("expected slow event without db.statement, got %+v", events)
+	}
+}
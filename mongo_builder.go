@@ -3,14 +3,19 @@ package builder
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
-	"github.com/fantasticbin/QueryBuilder/v2/util"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
 )
 
 // MongoFilter MongoDB 过滤条件类型（bson.D 有序文档）
@@ -27,6 +32,31 @@ type MongoBuilder[R any] struct {
 	builder[*MongoBuilder[R], R]
 	filter MongoFilter // MongoDB 专属过滤条件
 	sort   MongoSort   // MongoDB 专属排序条件
+
+	extraFilters    []MongoFilter            // 通过 AddFilter 追加的过滤条件，以 $and 与 filter 组合
+	arrayProjection bson.D                   // 数组字段投影（$elemMatch / $slice），每个字段仅保留最后一次设置
+	excludeFields   []string                 // 排除投影字段（{field: 0}），不能与 SetFields 的包含投影混用
+	batchSize       int32                    // 游标每批返回的文档数，0 表示使用驱动默认值
+	batchSizeSet    bool                     // 是否显式设置过 batchSize，用于校验非正数
+	registry        *bson.Registry           // 自定义 BSON 编解码注册表，为 nil 时使用集合自身的注册表
+	readConcern     *readconcern.ReadConcern // 查询使用的读关注，为 nil 时使用集合自身的读关注
+	session         *mongo.Session           // 查询所属的会话（如多文档事务），为 nil 时直接使用调用方 ctx
+	consistentRead  bool                     // 未配置会话时，列表查询是否在快照读会话中执行
+	opTimeSink      *bson.Timestamp          // 列表查询结束后写入会话的 operationTime，为 nil 表示不记录
+	timeSeries      mongoTimeSeries          // 时序集合配置
+	geoNear         *mongoGeoNear            // $geoNear 距离排序配置，为 nil 表示使用 Find 查询
+	decodeErrSink   *MongoDecodeErrors       // 逐条解码时收集解码失败的文档，为 nil 表示整体解码
+	tailable        mongoTailable            // 可追加游标配置
+	let             bson.M                   // Find / Aggregate 的 let 变量，为 nil 表示不设置
+
+	arraySortAnyElement bool // 是否允许按以 $elemMatch 过滤的数组子字段排序
+	flattenProjection   bool // 未设置 SetFields 时是否按结果结构体的 bson 标签生成投影
+}
+
+// mongoArraySlice 通过 WithArraySlice 传入的单个 $slice 投影
+type mongoArraySlice struct {
+	field string
+	n     int
 }
 
 // self 返回自身引用，实现 builderInterface 接口
@@ -48,7 +78,17 @@ func NewMongoBuilder[R any](data *DBProxy) *MongoBuilder[R] {
 // 新实例与原实例状态隔离，修改互不影响，适用于并发分叉查询场景
 // 注意：原 MongoBuilder 非并发安全，请勿在多 goroutine 中共享同一实例进行写操作
 func (m *MongoBuilder[R]) Clone() *MongoBuilder[R] {
-	cloned := &MongoBuilder[R]{}
+	cloned := &MongoBuilder[R]{
+		batchSize:      m.batchSize,
+		batchSizeSet:   m.batchSizeSet,
+		registry:       m.registry,
+		readConcern:    m.readConcern,
+		session:        m.session,
+		consistentRead: m.consistentRead,
+		opTimeSink:     m.opTimeSink,
+		decodeErrSink:  m.decodeErrSink,
+		tailable:       m.tailable,
+	}
 	m.builder.cloneBase(&cloned.builder)
 	cloned.builder.setSelf(cloned, cloned)
 
@@ -61,6 +101,20 @@ func (m *MongoBuilder[R]) Clone() *MongoBuilder[R] {
 		cloned.sort = make(MongoSort, len(m.sort))
 		copy(cloned.sort, m.sort)
 	}
+	cloned.extraFilters = append([]MongoFilter(nil), m.extraFilters...)
+	if m.arrayProjection != nil {
+		cloned.arrayProjection = make(bson.D, len(m.arrayProjection))
+		copy(cloned.arrayProjection, m.arrayProjection)
+	}
+	cloned.excludeFields = slices.Clone(m.excludeFields)
+	cloned.let = maps.Clone(m.let)
+	cloned.arraySortAnyElement = m.arraySortAnyElement
+	cloned.flattenProjection = m.flattenProjection
+	cloned.timeSeries = m.timeSeries.clone()
+	if m.geoNear != nil {
+		geoNear := *m.geoNear
+		cloned.geoNear = &geoNear
+	}
 	return cloned
 }
 
@@ -70,12 +124,193 @@ func (m *MongoBuilder[R]) SetFilter(filter MongoFilter) *MongoBuilder[R] {
 	return m
 }
 
+// AddFilter 追加 MongoDB 过滤条件，以 $and 与 SetFilter 设置的条件组合
+func (m *MongoBuilder[R]) AddFilter(filters ...MongoFilter) *MongoBuilder[R] {
+	for _, filter := range filters {
+		if len(filter) > 0 {
+			m.extraFilters = append(m.extraFilters, filter)
+		}
+	}
+	return m
+}
+
+// SetElemMatchProjection 设置数组字段的 $elemMatch 投影，仅返回数组中第一个满足 cond 的元素
+// 注意：$elemMatch 投影属于包含式投影，未通过 SetFields 指定的其他字段（_id 除外）不会返回
+func (m *MongoBuilder[R]) SetElemMatchProjection(field string, cond MongoFilter) *MongoBuilder[R] {
+	return m.setArrayProjection(field, bson.D{{Key: "$elemMatch", Value: cond}})
+}
+
+// SetProjectExclude 设置排除投影字段，生成 {field: 0}，适用于只需去掉少数大字段的场景
+// MongoDB 不允许混用包含与排除投影（_id 除外），与 SetFields 同时使用时查询返回 ErrMixedProjection
+func (m *MongoBuilder[R]) SetProjectExclude(fields ...string) *MongoBuilder[R] {
+	m.excludeFields = fields
+	return m
+}
+
+// SetArraySlice 设置数组字段的 $slice 投影：{field: {$slice: n}}，用于限制大数组的返回元素数
+// n > 0 返回前 n 个元素，n < 0 返回最后 |n| 个元素，n 为 0 时返回空数组；
+// 与 $elemMatch 不同，未配合 SetFields 时其他字段照常返回；同一字段再次设置 $slice 或 $elemMatch 时覆盖之前的投影
+func (m *MongoBuilder[R]) SetArraySlice(field string, n int) *MongoBuilder[R] {
+	return m.setArrayProjection(field, bson.D{{Key: "$slice", Value: n}})
+}
+
+// setArrayProjection 设置单个数组字段的投影表达式，已存在时原位替换以保持字段顺序
+func (m *MongoBuilder[R]) setArrayProjection(field string, expr bson.D) *MongoBuilder[R] {
+	for i, e := range m.arrayProjection {
+		if e.Key == field {
+			m.arrayProjection[i].Value = expr
+			return m
+		}
+	}
+	m.arrayProjection = append(m.arrayProjection, bson.E{Key: field, Value: expr})
+	return m
+}
+
+// hasFilter 是否设置了任何非空过滤条件（filter、追加的过滤条件或时序集合的 metaField/时间区间条件）
+func (m *MongoBuilder[R]) hasFilter() bool {
+	return len(m.filter) > 0 || len(m.extraFilters) > 0 || len(m.timeSeries.filters()) > 0
+}
+
+// buildFilter 组合用户 filter 与追加的过滤条件，数据查询与总数统计共用
+func (m *MongoBuilder[R]) buildFilter() MongoFilter {
+	extraFilters := m.extraFilters
+	if tsFilters := m.timeSeries.filters(); len(tsFilters) > 0 {
+		extraFilters = slices.Concat(extraFilters, tsFilters)
+	}
+	if len(extraFilters) == 0 {
+		if m.filter == nil {
+			return bson.D{}
+		}
+		return m.filter
+	}
+
+	conditions := make(bson.A, 0, len(extraFilters)+1)
+	if len(m.filter) > 0 {
+		conditions = append(conditions, m.filter)
+	}
+	for _, filter := range extraFilters {
+		conditions = append(conditions, filter)
+	}
+	return bson.D{{Key: "$and", Value: conditions}}
+}
+
 // SetSort 设置 MongoDB 排序条件
 func (m *MongoBuilder[R]) SetSort(sort MongoSort) *MongoBuilder[R] {
 	m.sort = sort
 	return m
 }
 
+// SetBatchSize 设置 MongoDB 游标每批返回的文档数（Find 选项 batchSize）
+// 大文档或大分页场景下调优该值可减少与服务端的往返次数；必须为正数，否则查询时返回 ErrInvalidBatchSize
+func (m *MongoBuilder[R]) SetBatchSize(batchSize int32) *MongoBuilder[R] {
+	m.batchSize = batchSize
+	m.batchSizeSet = true
+	return m
+}
+
+// SetRegistry 设置查询使用的 BSON 编解码注册表
+// 结果解码与过滤条件编码都会使用该注册表，适用于 decimal128、自定义类型等需要专用 codec 的场景；
+// 传入 nil 表示恢复使用集合自身的注册表
+func (m *MongoBuilder[R]) SetRegistry(registry *bson.Registry) *MongoBuilder[R] {
+	m.registry = registry
+	return m
+}
+
+// SetReadConcern 设置数据查询与总数统计使用的读关注，如 readconcern.Majority() 只读取已被多数节点确认的写入，
+// readconcern.Linearizable() 还能读到所有在本次读取开始前已确认的写入（仅限主节点，适合按唯一键的单文档查询）；
+// 在事务或快照读会话中执行时以会话的读关注为准，传入 nil 表示恢复使用集合自身的读关注
+func (m *MongoBuilder[R]) SetReadConcern(readConcern *readconcern.ReadConcern) *MongoBuilder[R] {
+	m.readConcern = readConcern
+	return m
+}
+
+// SetSession 设置查询所属的会话，数据查询与总数统计都会在该会话中执行，从而读取到事务内尚未提交的写入
+// 会话不支持并发使用，配置后数据查询与总数统计改为顺序执行；传入 nil 表示取消会话
+func (m *MongoBuilder[R]) SetSession(session *mongo.Session) *MongoBuilder[R] {
+	m.session = session
+	return m
+}
+
+// SetConsistentRead 设置列表查询的数据查询与总数统计是否读取同一快照
+// 开启且未通过 SetSession 配置会话时，每次列表查询开启一个 snapshot 会话并在其中顺序执行两者，
+// 保证总数与返回的数据一致（需 MongoDB 5.0+ 副本集或分片集群）；已配置会话时沿用该会话的读关注
+func (m *MongoBuilder[R]) SetConsistentRead(consistent bool) *MongoBuilder[R] {
+	m.consistentRead = consistent
+	return m
+}
+
+// SetOperationTimeSink 设置列表查询结束后写入会话 operationTime 的位置，用于跨查询的因果一致性（read-your-writes）
+// 未配置会话时每次列表查询开启一个临时会话以获取 operationTime；后续查询可在自己的会话上调用
+// session.AdvanceOperationTime(&ts)，使读请求携带 afterClusterTime 读到不早于该时间点的数据
+func (m *MongoBuilder[R]) SetOperationTimeSink(sink *bson.Timestamp) *MongoBuilder[R] {
+	m.opTimeSink = sink
+	return m
+}
+
+// ephemeralSessionOptions 返回未配置会话时列表查询需要临时开启的会话选项，返回 nil 表示无需开启
+func (m *MongoBuilder[R]) ephemeralSessionOptions() *options.SessionOptionsBuilder {
+	switch {
+	case m.session != nil:
+		return nil
+	case m.consistentRead:
+		return options.Session().SetSnapshot(true)
+	case m.opTimeSink != nil:
+		return options.Session()
+	default:
+		return nil
+	}
+}
+
+// withSession 配置会话时返回绑定该会话的 ctx
+func (m *MongoBuilder[R]) withSession(ctx context.Context) context.Context {
+	if m.session == nil {
+		return ctx
+	}
+	return mongo.NewSessionContext(ctx, m.session)
+}
+
+// runBranches 执行数据查询与总数统计分支：未配置会话时并行执行，否则按顺序执行
+func (m *MongoBuilder[R]) runBranches(fns ...func() error) error {
+	if m.session == nil {
+		return m.builder.runParallel(fns...)
+	}
+	for _, fn := range fns {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collection 返回本次查询使用的集合，配置自定义注册表或读关注时返回携带对应设置的集合副本
+func (m *MongoBuilder[R]) collection() *mongo.Collection {
+	return m.scopedCollection(m.builder.data.Mongodb)
+}
+
+// countCollection 返回总数统计使用的集合，配置 SetCountProxy 时使用其中的集合；
+// 绑定会话（SetSession、一致性读或 operationTime 回写）时会话属于数据实例的客户端，仍使用数据实例的集合
+func (m *MongoBuilder[R]) countCollection() *mongo.Collection {
+	if m.session != nil {
+		return m.collection()
+	}
+	return m.scopedCollection(m.builder.countProxy().Mongodb)
+}
+
+// scopedCollection 为集合应用自定义注册表与读关注
+func (m *MongoBuilder[R]) scopedCollection(coll *mongo.Collection) *mongo.Collection {
+	if m.registry == nil && m.readConcern == nil {
+		return coll
+	}
+	opts := options.Collection()
+	if m.registry != nil {
+		opts.SetRegistry(m.registry)
+	}
+	if m.readConcern != nil {
+		opts.SetReadConcern(m.readConcern)
+	}
+	return coll.Clone(opts)
+}
+
 // Use 添加中间件（实现 Querier 接口）
 func (m *MongoBuilder[R]) Use(middleware Middleware[R]) Querier[R] {
 	m.builder.Use(middleware)
@@ -158,17 +393,21 @@ func (m *MongoBuilder[R]) QueryList(ctx context.Context) (*core.ListResult[R], e
 		newMiddlewareContext[R](&m.builder),
 		func(ctx context.Context) (core.Result[R], error) {
 			list, total, err := m.doQuery(ctx)
-			return &core.ListResult[R]{Items: list, Total: total}, err
+			list, hasMore := m.builder.trimPeek(list)
+			return &core.ListResult[R]{Items: list, Total: total, HasMore: hasMore}, err
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
-	return listResultFromResult(result), nil
+	return m.builder.finishListResult(listResultFromResult(result)), nil
 }
 
-// QueryCursor 执行 MongoDB 游标分页查询，返回迭代器（实现 Querier 接口）
+// QueryCursor 执行 MongoDB 游标分页查询，返回迭代器（实现 Querier 接口）；配置 SetTailable 时改为持续产出新文档的可追加游标
 func (m *MongoBuilder[R]) QueryCursor(ctx context.Context) iter.Seq2[*R, error] {
+	if m.tailable.enabled {
+		return m.queryTailable(ctx)
+	}
 	return executeBuilderCursorQuery(
 		ctx,
 		&m.builder,
@@ -196,31 +435,41 @@ func (m *MongoBuilder[R]) QueryPage(ctx context.Context) (*core.CursorPageResult
 
 // doQuery 执行实际的 MongoDB 查询逻辑
 func (m *MongoBuilder[R]) doQuery(ctx context.Context) (list []*R, total int64, err error) {
-	if m.filter == nil {
-		m.filter = bson.D{}
+	if opts := m.ephemeralSessionOptions(); opts != nil {
+		session, err := m.builder.data.Mongodb.Database().Client().StartSession(opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer session.EndSession(ctx)
+		// 仅在本次查询期间绑定临时会话，runBranches 随之改为顺序执行
+		m.session = session
+		defer func() {
+			m.session = nil
+		}()
 	}
 
-	// 使用 WaitAndGo 并行执行数据查询和总数统计操作
-	if err = util.WaitAndGo(func() error {
-		findOpt := options.Find().SetSort(m.sort)
+	filter := m.buildFilter()
+	ctx = m.withSession(ctx)
 
-		// 应用字段投影
-		if len(m.builder.fields) > 0 {
-			projection := bson.D{}
-			for _, f := range m.builder.fields {
-				projection = append(projection, bson.E{Key: f, Value: 1})
-			}
-			findOpt.SetProjection(projection)
+	// 使用 WaitAndGo 并行执行数据查询和总数统计操作（配置会话时顺序执行）
+	if err = m.runBranches(func() error {
+		if m.builder.skipData {
+			list = []*R{}
+			return nil
 		}
-
-		if m.builder.needPagination {
-			if m.builder.limit == 0 {
-				m.builder.limit = defaultLimit
-			}
-			findOpt.SetSkip(int64(m.builder.start)).SetLimit(int64(m.builder.limit))
+		list = make([]*R, 0, m.builder.resultCapacityHint())
+		if m.geoNear != nil {
+			return m.aggregateGeoNear(ctx, filter, func(cursor *mongo.Cursor) error {
+				return decodeAll(ctx, cursor, &list, m.decodeErrSink, m.unflattenFunc())
+			})
+		}
+		findOpt, err := m.listFindOptions()
+		if err != nil {
+			return err
 		}
 
-		cursor, err := m.builder.data.Mongodb.Find(ctx, m.filter, findOpt)
+		defer m.builder.observeDBCall(DBCallFind)()
+		cursor, err := m.collection().Find(ctx, filter, findOpt)
 		if err != nil {
 			return err
 		}
@@ -228,13 +477,13 @@ func (m *MongoBuilder[R]) doQuery(ctx context.Context) (list []*R, total int64,
 			_ = cursor.Close(ctx)
 		}(cursor, ctx)
 
-		return cursor.All(ctx, &list)
+		return decodeAll(ctx, cursor, &list, m.decodeErrSink, m.unflattenFunc())
 	}, func() error {
 		if !m.builder.needTotal {
 			return nil
 		}
 
-		total, err = m.countDocuments(ctx, m.filter)
+		total, err = m.countDocuments(m.withSession(m.builder.countContext(ctx)), m.listCountFilter(filter))
 		if err != nil {
 			return err
 		}
@@ -244,43 +493,219 @@ func (m *MongoBuilder[R]) doQuery(ctx context.Context) (list []*R, total int64,
 		return nil, 0, err
 	}
 
+	if m.opTimeSink != nil {
+		if ts := m.session.OperationTime(); ts != nil {
+			*m.opTimeSink = *ts
+		}
+	}
 	return list, total, nil
 }
 
-// countDocuments 执行 MongoDB 总数统计；配置 totalLimit 时使用 CountOptions.Limit 限制扫描数量。
+// listFindOptions 构建列表查询的 Find 选项：排序、批次大小、字段投影与分页
+func (m *MongoBuilder[R]) listFindOptions() (*options.FindOptionsBuilder, error) {
+	findOpt := m.findOptions().SetSort(m.listSort())
+	if err := m.applyBatchSize(findOpt); err != nil {
+		return nil, err
+	}
+
+	// 应用字段投影
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		findOpt.SetProjection(projection)
+	}
+
+	if m.builder.needPagination {
+		if m.builder.limit == 0 {
+			m.builder.limit = defaultLimit
+		}
+		findOpt.SetSkip(int64(m.builder.start)).SetLimit(int64(m.builder.pageFetchLimit()))
+	}
+	return findOpt, nil
+}
+
+// queryValues 执行数据查询并解码为值切片，needTotal 时随后统计总数；不经过钩子与中间件
+func (m *MongoBuilder[R]) queryValues(ctx context.Context) (values []R, total int64, err error) {
+	if err := m.builder.prepareAndValidate(); err != nil {
+		return nil, 0, err
+	}
+	if m.builder.emptyResult {
+		return []R{}, 0, nil
+	}
+	filter := m.buildFilter()
+	ctx = m.withSession(ctx)
+
+	values = make([]R, 0, m.builder.resultCapacityHint())
+	if !m.builder.skipData {
+		if m.geoNear != nil {
+			err = m.aggregateGeoNear(ctx, filter, func(cursor *mongo.Cursor) error {
+				return decodeAll(ctx, cursor, &values, m.decodeErrSink, m.unflattenFunc())
+			})
+		} else {
+			var findOpt *options.FindOptionsBuilder
+			if findOpt, err = m.listFindOptions(); err == nil {
+				err = m.findValues(ctx, filter, findOpt, &values)
+			}
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		values = trimPeekValues(&m.builder, values)
+	}
+	if m.builder.needTotal {
+		if total, err = m.countDocuments(m.withSession(m.builder.countContext(ctx)), m.listCountFilter(filter)); err != nil {
+			return nil, 0, err
+		}
+	}
+	return values, total, nil
+}
+
+// findValues 执行 Find 并解码到值切片
+func (m *MongoBuilder[R]) findValues(ctx context.Context, filter MongoFilter, findOpt *options.FindOptionsBuilder, values *[]R) error {
+	defer m.builder.observeDBCall(DBCallFind)()
+	cursor, err := m.collection().Find(ctx, filter, findOpt)
+	if err != nil {
+		return err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		_ = cursor.Close(ctx)
+	}(cursor, ctx)
+
+	return decodeAll(ctx, cursor, values, m.decodeErrSink, m.unflattenFunc())
+}
+
+// applyBatchSize 校验并应用游标批次大小
+func (m *MongoBuilder[R]) applyBatchSize(findOpt *options.FindOptionsBuilder) error {
+	if !m.batchSizeSet {
+		return nil
+	}
+	if m.batchSize <= 0 {
+		return ErrInvalidBatchSize
+	}
+	findOpt.SetBatchSize(m.batchSize)
+	return nil
+}
+
+// pluck 按当前 filter、sort 与分页配置只投影单个字段，逐条回调该字段的原始值
+// column 支持以 "." 分隔的嵌套路径，缺少该字段的文档会被跳过
+func (m *MongoBuilder[R]) pluck(ctx context.Context, column string, each func(bson.RawValue) error) error {
+	ctx = m.withSession(ctx)
+	projection := bson.D{{Key: column, Value: 1}}
+	if column != "_id" {
+		projection = append(projection, bson.E{Key: "_id", Value: 0})
+	}
+	findOpt := m.findOptions().SetSort(m.listSort()).SetProjection(projection)
+	if err := m.applyBatchSize(findOpt); err != nil {
+		return err
+	}
+	if m.builder.needPagination {
+		if m.builder.limit == 0 {
+			m.builder.limit = defaultLimit
+		}
+		findOpt.SetSkip(int64(m.builder.start)).SetLimit(int64(m.builder.limit))
+	}
+
+	defer m.builder.observeDBCall(DBCallFind)()
+	cursor, err := m.collection().Find(ctx, m.buildFilter(), findOpt)
+	if err != nil {
+		return err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		_ = cursor.Close(ctx)
+	}(cursor, ctx)
+
+	path := strings.Split(column, ".")
+	for cursor.Next(ctx) {
+		value, err := cursor.Current.LookupErr(path...)
+		if errors.Is(err, bsoncore.ErrElementNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := each(value); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// decodeValue 解码单个 BSON 值，配置自定义注册表时使用该注册表
+func (m *MongoBuilder[R]) decodeValue(value bson.RawValue, val any) error {
+	if m.registry == nil {
+		return value.Unmarshal(val)
+	}
+	return value.UnmarshalWithRegistry(m.registry, val)
+}
+
+// listCountFilter 返回列表查询总数统计使用的过滤条件，配置 $geoNear 时补充等价的距离范围
+func (m *MongoBuilder[R]) listCountFilter(filter MongoFilter) MongoFilter {
+	if m.geoNear == nil {
+		return filter
+	}
+	return m.geoNear.countFilter(filter)
+}
+
+// countDocuments 执行总数统计，配置 Counter 时交由其处理，否则执行 exactCount
 func (m *MongoBuilder[R]) countDocuments(ctx context.Context, filter MongoFilter) (int64, error) {
+	return m.builder.countWith(ctx, func(ctx context.Context) (int64, error) {
+		return m.exactCount(ctx, filter)
+	})
+}
+
+// exactCount 执行 MongoDB 精确总数统计；配置 totalLimit 时使用 CountOptions.Limit 限制扫描数量。
+// CountDocuments 不支持 $near，统计前会将其改写为等价范围的 $geoWithin，无法等价改写时返回 ErrNearCountUnsupported。
+func (m *MongoBuilder[R]) exactCount(ctx context.Context, filter MongoFilter) (int64, error) {
+	filter, err := geoCountFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	defer m.builder.observeDBCall(DBCallCount)()
 	if m.builder.totalLimit == 0 {
-		return m.builder.data.Mongodb.CountDocuments(ctx, filter)
+		return m.countCollection().CountDocuments(ctx, filter)
 	}
-	return m.builder.data.Mongodb.CountDocuments(ctx, filter, options.Count().SetLimit(int64(m.builder.totalLimit)))
+	return m.countCollection().CountDocuments(ctx, filter, options.Count().SetLimit(m.builder.countProbeLimit()))
 }
 
 // Explain 返回 MongoDB 构建器最终生成的查询条件（Dry Run 模式）
 // 用于调试场景，不会实际执行查询
 // 若已配置游标字段，将输出游标查询模式的首批查询 DSL
 func (m *MongoBuilder[R]) Explain(ctx context.Context) (string, error) {
+	if err := m.validateSort(); err != nil {
+		return "", err
+	}
 	// 如果配置了游标字段，展示游标查询模式的首批 DSL
 	if len(m.builder.cursorFields) > 0 {
 		return m.explainCursor(ctx)
 	}
 
-	if m.filter == nil {
-		m.filter = bson.D{}
+	if m.geoNear != nil {
+		pipeline, err := m.buildGeoNearPipeline(m.buildFilter())
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(map[string]any{"pipeline": pipeline}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
 	}
 
 	result := map[string]any{
-		"filter": m.filter,
+		"filter": m.buildFilter(),
 	}
 
-	if m.sort != nil {
-		result["sort"] = m.sort
+	if sort := m.listSort(); sort != nil {
+		result["sort"] = sort
 	}
 
-	if len(m.builder.fields) > 0 {
-		projection := bson.D{}
-		for _, f := range m.builder.fields {
-			projection = append(projection, bson.E{Key: f, Value: 1})
-		}
+	projection, err := m.buildProjection()
+	if err != nil {
+		return "", err
+	}
+	if projection != nil {
 		result["projection"] = projection
 	}
 
@@ -289,7 +714,11 @@ func (m *MongoBuilder[R]) Explain(ctx context.Context) (string, error) {
 			m.builder.limit = defaultLimit
 		}
 		result["skip"] = m.builder.start
-		result["limit"] = m.builder.limit
+		result["limit"] = m.builder.pageFetchLimit()
+	}
+
+	if m.batchSizeSet {
+		result["batch_size"] = m.batchSize
 	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
@@ -300,6 +729,91 @@ func (m *MongoBuilder[R]) Explain(ctx context.Context) (string, error) {
 	return string(data), nil
 }
 
+// ExplainPlan 通过 explain 命令返回 MongoDB 的查询执行计划（Extended JSON 格式）
+// 与 Explain 不同，该方法会实际访问数据库（verbosity 为 queryPlanner，不执行查询本身），
+// 用于开发阶段排查缺失索引等问题；若已配置游标字段，返回游标查询模式首批查询的执行计划
+func (m *MongoBuilder[R]) ExplainPlan(ctx context.Context) (string, error) {
+	if err := m.builder.prepareAndValidate(); err != nil {
+		return "", err
+	}
+
+	command, err := m.buildExplainCommand()
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := m.builder.data.Mongodb.Database().RunCommand(ctx, command).Raw()
+	if err != nil {
+		return "", err
+	}
+	return raw.String(), nil
+}
+
+// buildExplainCommand 构建 explain 命令，find 子命令与实际查询使用相同的过滤、排序、投影与分页参数
+func (m *MongoBuilder[R]) buildExplainCommand() (bson.D, error) {
+	find := bson.D{
+		{Key: "find", Value: m.builder.data.Mongodb.Name()},
+		{Key: "filter", Value: m.buildFilter()},
+	}
+
+	if len(m.builder.cursorFields) > 0 {
+		batchSize := int64(m.builder.limit)
+		if batchSize == 0 {
+			batchSize = defaultLimit
+		}
+		find = append(find, bson.E{Key: "sort", Value: m.buildCursorSort()}, bson.E{Key: "limit", Value: batchSize})
+	} else {
+		if sort := m.listSort(); sort != nil {
+			find = append(find, bson.E{Key: "sort", Value: sort})
+		}
+		if m.builder.needPagination {
+			if m.builder.limit == 0 {
+				m.builder.limit = defaultLimit
+			}
+			find = append(find,
+				bson.E{Key: "skip", Value: int64(m.builder.start)},
+				bson.E{Key: "limit", Value: int64(m.builder.pageFetchLimit())},
+			)
+		}
+	}
+
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		find = append(find, bson.E{Key: "projection", Value: projection})
+	}
+
+	if m.batchSizeSet {
+		if m.batchSize <= 0 {
+			return nil, ErrInvalidBatchSize
+		}
+		find = append(find, bson.E{Key: "batchSize", Value: m.batchSize})
+	}
+	if m.let != nil {
+		find = append(find, bson.E{Key: "let", Value: m.let})
+	}
+
+	return bson.D{
+		{Key: "explain", Value: find},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}, nil
+}
+
+// listSort 返回偏移分页使用的排序条件，未设置 sort 时使用时序集合的默认排序；配置稳定排序字段且 sort 未包含该字段时在末尾追加升序排序
+func (m *MongoBuilder[R]) listSort() MongoSort {
+	sort := m.sort
+	if len(sort) == 0 {
+		sort = m.timeSeries.defaultSort()
+	}
+	key := m.builder.stableSortKey
+	if key == "" || slices.ContainsFunc(sort, func(e bson.E) bool { return e.Key == key }) {
+		return sort
+	}
+	return append(slices.Clone(sort), bson.E{Key: key, Value: 1})
+}
+
 // buildCursorSort 构建游标查询的排序条件（游标字段排序为主，用户 sort 去重追加）
 func (m *MongoBuilder[R]) buildCursorSort() bson.D {
 	sortDoc := bson.D{}
@@ -326,16 +840,27 @@ func (m *MongoBuilder[R]) buildCursorSort() bson.D {
 	return sortDoc
 }
 
-// buildCursorProjection 构建游标查询的字段投影
-func (m *MongoBuilder[R]) buildCursorProjection() bson.D {
-	if len(m.builder.fields) == 0 {
-		return nil
+// buildProjection 构建字段投影，包含 SetFields 指定的字段与 $elemMatch / $slice 数组字段投影
+func (m *MongoBuilder[R]) buildProjection() (bson.D, error) {
+	fields := m.projectionFields()
+	if len(fields) == 0 && len(m.arrayProjection) == 0 && len(m.excludeFields) == 0 {
+		return nil, nil
+	}
+	// MongoDB 不允许混用包含与排除投影，_id 是唯一例外
+	included := slices.ContainsFunc(fields, func(f string) bool { return f != "_id" })
+	excluded := slices.ContainsFunc(m.excludeFields, func(f string) bool { return f != "_id" })
+	if included && excluded {
+		return nil, fmt.Errorf("%w: fields %v, excluded %v", ErrMixedProjection, fields, m.excludeFields)
 	}
+
 	projection := bson.D{}
-	for _, f := range m.builder.fields {
+	for _, f := range fields {
 		projection = append(projection, bson.E{Key: f, Value: 1})
 	}
-	return projection
+	for _, f := range m.excludeFields {
+		projection = append(projection, bson.E{Key: f, Value: 0})
+	}
+	return append(projection, m.arrayProjection...), nil
 }
 
 // explainCursor 返回游标查询模式的首批查询 DSL
@@ -345,10 +870,7 @@ func (m *MongoBuilder[R]) explainCursor(ctx context.Context) (string, error) {
 		batchSize = defaultLimit
 	}
 
-	filter := m.filter
-	if filter == nil {
-		filter = bson.D{}
-	}
+	filter := m.buildFilter()
 
 	result := map[string]any{
 		"mode":          "cursor",
@@ -358,10 +880,18 @@ func (m *MongoBuilder[R]) explainCursor(ctx context.Context) (string, error) {
 		"limit":         batchSize,
 	}
 
-	if projection := m.buildCursorProjection(); projection != nil {
+	projection, err := m.buildProjection()
+	if err != nil {
+		return "", err
+	}
+	if projection != nil {
 		result["projection"] = projection
 	}
 
+	if m.batchSizeSet {
+		result["batch_size"] = m.batchSize
+	}
+
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return "", err
@@ -375,16 +905,16 @@ func (m *MongoBuilder[R]) explainCursor(ctx context.Context) (string, error) {
 // probeHasMore 为 true 时，通过 limit+1 探测精确判断是否还有下一页
 // isFirstBatch 为 true 时，若 needTotal 也为 true，则并行执行 CountDocuments 查询
 func (m *MongoBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any, isFirstBatch bool, probeHasMore bool) ([]*R, []any, int64, bool, error) {
+	if m.geoNear != nil {
+		return nil, nil, 0, false, ErrGeoNearCursorUnsupported
+	}
 	batchSize := int(m.builder.limit)
 	if batchSize == 0 {
 		batchSize = defaultLimit
 	}
 
 	// 构建过滤条件
-	filter := m.filter
-	if filter == nil {
-		filter = bson.D{}
-	}
+	filter := m.buildFilter()
 
 	// 用于 Count 查询的基础过滤条件（不含游标条件）
 	baseFilter := filter
@@ -431,19 +961,28 @@ func (m *MongoBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 	if probeHasMore {
 		queryLimit = int64(batchSize + 1)
 	}
-	findOpt := options.Find().SetSort(m.buildCursorSort()).SetLimit(queryLimit)
+	findOpt := m.findOptions().SetSort(m.buildCursorSort()).SetLimit(queryLimit)
+	if err := m.applyBatchSize(findOpt); err != nil {
+		return nil, nil, 0, false, err
+	}
 
 	// 应用字段投影
-	if projection := m.buildCursorProjection(); projection != nil {
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	if projection != nil {
 		findOpt.SetProjection(projection)
 	}
 
-	var list []*R
+	list := make([]*R, 0, batchSize+1)
 	var total int64
 	var lastRaw bson.Raw
 
-	if err := util.WaitAndGo(func() error {
-		cursor, err := m.builder.data.Mongodb.Find(ctx, filter, findOpt)
+	ctx = m.withSession(ctx)
+	if err := m.runBranches(func() error {
+		defer m.builder.observeDBCall(DBCallFind)()
+		cursor, err := m.collection().Find(ctx, filter, findOpt)
 		if err != nil {
 			return err
 		}
@@ -453,11 +992,14 @@ func (m *MongoBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 
 		// 逐条遍历 cursor，保留前 batchSize 条的最后一条原始 BSON 用于提取游标值
 		for cursor.Next(ctx) {
-			var item R
-			if err := cursor.Decode(&item); err != nil {
+			item := m.builder.newResultItem()
+			if err := cursor.Decode(item); err != nil {
+				return err
+			}
+			if err := m.unflatten(cursor.Current, item); err != nil {
 				return err
 			}
-			list = append(list, &item)
+			list = append(list, item)
 			if len(list) <= batchSize {
 				lastRaw = cursor.Current
 			}
@@ -470,7 +1012,7 @@ func (m *MongoBuilder[R]) doCursorQuery(ctx context.Context, cursorValues []any,
 		}
 
 		var countErr error
-		total, countErr = m.countDocuments(ctx, baseFilter)
+		total, countErr = m.countDocuments(m.withSession(m.builder.countContext(ctx)), baseFilter)
 		return countErr
 	}); err != nil {
 		return nil, nil, 0, false, err
@@ -2,9 +2,18 @@ package builder
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
 	"go.uber.org/mock/gomock"
 )
 
@@ -157,3 +166,1153 @@ func TestMongoBuilderFilterNilDefault(t *testing.T) {
 		t.Error("expected filter to be non-nil after SetFilter")
 	}
 }
+
+// TestMongoBuilder_BatchSize 测试 batchSize 应用到 Find 选项
+func TestMongoBuilder_BatchSize(t *testing.T) {
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+
+	findOpt := options.Find()
+	if err := mongoBuilder.applyBatchSize(findOpt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var unset options.FindOptions
+	for _, set := range findOpt.Opts {
+		_ = set(&unset)
+	}
+	if unset.BatchSize != nil {
+		t.Errorf("expected driver default batch size, got %d", *unset.BatchSize)
+	}
+
+	mongoBuilder.SetBatchSize(500)
+	findOpt = options.Find()
+	if err := mongoBuilder.applyBatchSize(findOpt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var applied options.FindOptions
+	for _, set := range findOpt.Opts {
+		_ = set(&applied)
+	}
+	if applied.BatchSize == nil || *applied.BatchSize != 500 {
+		t.Errorf("expected batch size 500, got %v", applied.BatchSize)
+	}
+
+	// Clone 后保留 batchSize
+	if cloned := mongoBuilder.Clone(); cloned.batchSize != 500 || !cloned.batchSizeSet {
+		t.Errorf("expected cloned batch size 500, got %d", cloned.batchSize)
+	}
+
+	explain, err := mongoBuilder.Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, `"batch_size": 500`) {
+		t.Errorf("expected batch_size in explain output, got %s", explain)
+	}
+}
+
+// TestMongoBuilder_InvalidBatchSize 测试非正数 batchSize 返回 ErrInvalidBatchSize
+func TestMongoBuilder_InvalidBatchSize(t *testing.T) {
+	for _, batchSize := range []int32{0, -1} {
+		mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+		mongoBuilder.SetBatchSize(batchSize)
+
+		if _, err := mongoBuilder.QueryList(context.Background()); !errors.Is(err, ErrInvalidBatchSize) {
+			t.Errorf("batch size %d: expected ErrInvalidBatchSize, got %v", batchSize, err)
+		}
+	}
+}
+
+// TestListQuery_WithBatchSizeOption 测试 List 通过 WithBatchSize 配置 MongoDB 批次大小
+func TestListQuery_WithBatchSizeOption(t *testing.T) {
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+
+	_, err := list.Query(context.Background(),
+		WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithNeedTotal(false),
+		WithBatchSize(-10),
+	)
+	if !errors.Is(err, ErrInvalidBatchSize) {
+		t.Errorf("expected ErrInvalidBatchSize, got %v", err)
+	}
+}
+
+// TestMongoArrayFilterHelpers 测试数组过滤辅助函数
+func TestMongoArrayFilterHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   MongoFilter
+		expected MongoFilter
+	}{
+		{
+			name:     "ArrayContains",
+			filter:   MongoArrayContains("tags", "go", "mongo"),
+			expected: MongoFilter{{Key: "tags", Value: bson.D{{Key: "$in", Value: bson.A{"go", "mongo"}}}}},
+		},
+		{
+			name:     "ArrayContainsAll",
+			filter:   MongoArrayContainsAll("tags", "go"),
+			expected: MongoFilter{{Key: "tags", Value: bson.D{{Key: "$all", Value: bson.A{"go"}}}}},
+		},
+		{
+			name:   "ElemMatch",
+			filter: MongoElemMatch("items", MongoFilter{{Key: "sku", Value: "A1"}, {Key: "qty", Value: bson.D{{Key: "$gt", Value: 2}}}}),
+			expected: MongoFilter{{Key: "items", Value: bson.D{{Key: "$elemMatch", Value: MongoFilter{
+				{Key: "sku", Value: "A1"},
+				{Key: "qty", Value: bson.D{{Key: "$gt", Value: 2}}},
+			}}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.filter, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, tt.filter)
+			}
+		})
+	}
+}
+
+// TestMongoBuilder_GeoNear 测试 $geoNear 聚合管道、等价的总数统计条件与游标查询限制
+func TestMongoBuilder_GeoNear(t *testing.T) {
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	b.SetGeoNear("location", 121.47, 31.23, 5000, "").
+		SetFilter(MongoFilter{{Key: "status", Value: "open"}}).
+		SetSort(MongoSort{{Key: "rating", Value: -1}})
+	b.SetStart(20).SetLimit(10).SetNeedPagination(true).SetFields("name")
+
+	pipeline, err := b.buildGeoNearPipeline(b.buildFilter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: geoPoint(121.47, 31.23)},
+			{Key: "distanceField", Value: "distance"},
+			{Key: "key", Value: "location"},
+			{Key: "spherical", Value: true},
+			{Key: "maxDistance", Value: 5000.0},
+			{Key: "query", Value: MongoFilter{{Key: "status", Value: "open"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "distance", Value: 1}, {Key: "rating", Value: -1}}}},
+		{{Key: "$skip", Value: int64(20)}},
+		{{Key: "$limit", Value: int64(10)}},
+		{{Key: "$project", Value: bson.D{{Key: "name", Value: 1}, {Key: "distance", Value: 1}}}},
+	}
+	if !reflect.DeepEqual(pipeline, expected) {
+		t.Errorf("expected pipeline %v, got %v", expected, pipeline)
+	}
+
+	expectedCount := MongoFilter{{Key: "$and", Value: bson.A{
+		MongoFilter{{Key: "status", Value: "open"}},
+		MongoGeoWithinRadius("location", 121.47, 31.23, 5000),
+	}}}
+	if got := b.listCountFilter(b.buildFilter()); !reflect.DeepEqual(got, expectedCount) {
+		t.Errorf("expected count filter %v, got %v", expectedCount, got)
+	}
+
+	explain, err := b.Explain(context.Background())
+	if err != nil || !strings.Contains(explain, "$geoNear") {
+		t.Errorf("expected explain to show $geoNear pipeline, got %q, %v", explain, err)
+	}
+
+	cloned := b.Clone()
+	cloned.SetGeoNear("area", 0, 0, 0, "dist")
+	if b.geoNear.field != "location" {
+		t.Error("expected clone to isolate geoNear config")
+	}
+
+	b.SetCursorField("id")
+	if _, err := b.QueryPage(context.Background()); !errors.Is(err, ErrGeoNearCursorUnsupported) {
+		t.Errorf("expected ErrGeoNearCursorUnsupported, got %v", err)
+	}
+}
+
+// TestParseMongoFilter 测试 JSON 过滤条件的解析、Extended JSON 与操作符白名单
+func TestParseMongoFilter(t *testing.T) {
+	filter, err := ParseMongoFilter(`{"status": "active", "age": {"$gte": 18}, "$or": [{"vip": true}, {"score": {"$gt": 90}}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filter) != 3 || filter[0].Key != "status" || filter[2].Key != "$or" {
+		t.Errorf("expected key order to be preserved, got %v", filter)
+	}
+
+	filter, err = ParseMongoFilter(`{"created_at": {"$gt": {"$date": "2024-01-01T00:00:00Z"}}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := filter[0].Value.(bson.D)[0].Value.(bson.DateTime); !ok {
+		t.Errorf("expected extended JSON date to decode as bson.DateTime, got %T", filter[0].Value.(bson.D)[0].Value)
+	}
+
+	if _, err := ParseMongoFilter(`{"status": `); !errors.Is(err, ErrInvalidMongoFilter) {
+		t.Errorf("expected ErrInvalidMongoFilter, got %v", err)
+	}
+	for _, jsonStr := range []string{
+		`{"$where": "this.a > 1"}`,
+		`{"$and": [{"a": {"$function": {"body": "f", "args": [], "lang": "js"}}}]}`,
+	} {
+		if _, err := ParseMongoFilter(jsonStr); !errors.Is(err, ErrMongoOperatorNotAllowed) {
+			t.Errorf("expected ErrMongoOperatorNotAllowed for %s, got %v", jsonStr, err)
+		}
+	}
+
+	if _, err := ParseMongoFilter(`{"a": {"$gt": 1}}`, "$eq"); !errors.Is(err, ErrMongoOperatorNotAllowed) {
+		t.Errorf("expected custom allowlist to reject $gt, got %v", err)
+	}
+	if _, err := ParseMongoFilter(`{"$text": {"$search": "fox"}}`, "$text", "$search"); err != nil {
+		t.Errorf("expected custom allowlist to accept $text, got %v", err)
+	}
+}
+
+// TestMongoBuilder_ElemMatchProjection 测试 $elemMatch 投影与 SetFields 组合，并在游标模式下生效
+func TestMongoBuilder_ElemMatchProjection(t *testing.T) {
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	mongoBuilder.SetElemMatchProjection("items", MongoFilter{{Key: "sku", Value: "A1"}}).
+		AddFilter(MongoArrayContains("tags", "go"))
+	mongoBuilder.SetFields("name")
+
+	expected := bson.D{
+		{Key: "name", Value: 1},
+		{Key: "items", Value: bson.D{{Key: "$elemMatch", Value: MongoFilter{{Key: "sku", Value: "A1"}}}}},
+	}
+	if got, _ := mongoBuilder.buildProjection(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected projection %v, got %v", expected, got)
+	}
+
+	// 重复设置同一字段时覆盖
+	mongoBuilder.SetElemMatchProjection("items", MongoFilter{{Key: "sku", Value: "B2"}})
+	if got, _ := mongoBuilder.buildProjection(); len(got) != 2 {
+		t.Errorf("expected projection to be replaced, got %v", got)
+	}
+
+	cloned := mongoBuilder.Clone()
+	cloned.SetElemMatchProjection("other", MongoFilter{})
+	if len(mongoBuilder.arrayProjection) != 1 {
+		t.Error("expected clone to isolate elemMatch projection")
+	}
+
+	mongoBuilder.SetCursorField("id")
+	explain, err := mongoBuilder.Explain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, "$elemMatch") || !strings.Contains(explain, "$in") {
+		t.Errorf("expected $elemMatch projection and $in filter, got %s", explain)
+	}
+}
+
+// TestMongoBuilder_BuildExplainCommand 测试 explain 命令与实际查询参数保持一致
+func TestMongoBuilder_BuildExplainCommand(t *testing.T) {
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	mongoBuilder.SetFilter(MongoFilter{{Key: "status", Value: 1}}).
+		SetSort(MongoSort{{Key: "created_at", Value: -1}})
+	mongoBuilder.SetNeedPagination(true)
+	mongoBuilder.SetStart(20)
+	mongoBuilder.SetFields("name")
+
+	command, err := mongoBuilder.buildExplainCommand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := bson.D{
+		{Key: "explain", Value: bson.D{
+			{Key: "find", Value: ""},
+			{Key: "filter", Value: MongoFilter{{Key: "status", Value: 1}}},
+			{Key: "sort", Value: MongoSort{{Key: "created_at", Value: -1}}},
+			{Key: "skip", Value: int64(20)},
+			{Key: "limit", Value: int64(defaultLimit)},
+			{Key: "projection", Value: bson.D{{Key: "name", Value: 1}}},
+		}},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}
+	if !reflect.DeepEqual(command, expected) {
+		t.Errorf("expected %v, got %v", expected, command)
+	}
+
+	mongoBuilder.SetBatchSize(0)
+	if _, err := mongoBuilder.buildExplainCommand(); !errors.Is(err, ErrInvalidBatchSize) {
+		t.Errorf("expected ErrInvalidBatchSize, got %v", err)
+	}
+}
+
+// TestMongoBuilder_Registry 测试自定义 BSON 注册表通过集合副本生效，且不影响原集合
+func TestMongoBuilder_Registry(t *testing.T) {
+	// Connect 不会立即建立连接，仅用于构造可 Clone 的集合
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	coll := client.Database("test").Collection("users")
+
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, coll, nil))
+	if mongoBuilder.collection() != coll {
+		t.Error("expected original collection without custom registry")
+	}
+
+	registry := bson.NewRegistry()
+	mongoBuilder.SetRegistry(registry)
+	scoped := mongoBuilder.collection()
+	if scoped == coll || scoped.Name() != "users" {
+		t.Errorf("expected a registry-scoped copy of the collection, got %v", scoped)
+	}
+
+	if cloned := mongoBuilder.Clone(); cloned.registry != registry {
+		t.Error("expected clone to keep the custom registry")
+	}
+}
+
+// TestMongoBuilder_CountProxySession 测试绑定会话时总数统计不使用独立统计实例的集合，会话不能跨客户端使用
+func TestMongoBuilder_CountProxySession(t *testing.T) {
+	// Connect 不会立即建立连接，仅用于构造集合与会话
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	coll := client.Database("test").Collection("users")
+	replica := client.Database("test").Collection("users_replica")
+
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, coll, nil))
+	mongoBuilder.SetCountProxy(NewDBProxy(nil, replica, nil))
+	if mongoBuilder.countCollection() != replica {
+		t.Error("expected count on the count proxy collection without a session")
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		t.Fatalf("start session failed: %v", err)
+	}
+	defer session.EndSession(context.Background())
+	mongoBuilder.SetSession(session)
+	if mongoBuilder.countCollection() != coll {
+		t.Error("expected count on the data collection with a bound session")
+	}
+}
+
+// TestMongoBuilder_ReadConcern 测试读关注通过集合副本生效，并可由 QueryOption 传入
+func TestMongoBuilder_ReadConcern(t *testing.T) {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	coll := client.Database("test").Collection("users")
+	// 集合未导出读关注，通过反射读取其级别
+	levelOf := func(c *mongo.Collection) string {
+		field := reflect.ValueOf(c).Elem().FieldByName("readConcern")
+		if field.IsNil() {
+			return ""
+		}
+		return field.Elem().FieldByName("Level").String()
+	}
+
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, coll, nil))
+	if mongoBuilder.collection() != coll {
+		t.Error("expected original collection without read concern")
+	}
+
+	mongoBuilder.SetReadConcern(readconcern.Majority()).SetRegistry(bson.NewRegistry())
+	if scoped := mongoBuilder.collection(); scoped == coll || levelOf(scoped) != "majority" {
+		t.Errorf("expected a majority read concern copy, got %q", levelOf(scoped))
+	}
+	if levelOf(coll) == "majority" {
+		t.Error("expected original collection to keep its read concern")
+	}
+	if cloned := mongoBuilder.Clone(); levelOf(cloned.collection()) != "majority" {
+		t.Error("expected clone to keep the read concern")
+	}
+
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	options := LoadQueryOptions(WithData(NewDBProxy(nil, coll, nil)), WithReadConcern(readconcern.Linearizable()))
+	querier := list.buildQuerier(options).(*MongoBuilder[MongoTestEntity])
+	if levelOf(querier.collection()) != "linearizable" {
+		t.Errorf("expected WithReadConcern to apply, got %q", levelOf(querier.collection()))
+	}
+}
+
+// TestMongoBuilder_Session 测试会话绑定到查询 ctx，且配置会话后分支顺序执行
+func TestMongoBuilder_Session(t *testing.T) {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	session, err := client.StartSession()
+	if err != nil {
+		t.Fatalf("start session failed: %v", err)
+	}
+	defer session.EndSession(context.Background())
+
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, client.Database("test").Collection("users"), nil))
+	ctx := context.Background()
+	if mongo.SessionFromContext(mongoBuilder.withSession(ctx)) != nil {
+		t.Error("expected no session without SetSession")
+	}
+
+	mongoBuilder.SetSession(session)
+	if mongo.SessionFromContext(mongoBuilder.withSession(ctx)) != session {
+		t.Error("expected query ctx to carry the session")
+	}
+	if cloned := mongoBuilder.Clone(); cloned.session != session {
+		t.Error("expected clone to keep the session")
+	}
+
+	var order []int
+	if err := mongoBuilder.runBranches(func() error {
+		order = append(order, 1)
+		return nil
+	}, func() error {
+		order = append(order, 2)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("expected sequential branches, got %v", order)
+	}
+}
+
+// TestMongoGeoFilters 测试地理位置过滤条件的生成，以及 $near 在总数统计中改写为 $geoWithin
+func TestMongoGeoFilters(t *testing.T) {
+	near := MongoNear("location", 121.47, 31.23, 5000)
+	expectedNear := MongoFilter{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{
+		{Key: "$geometry", Value: bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{121.47, 31.23}}}},
+		{Key: "$maxDistance", Value: 5000.0},
+	}}}}}
+	if !reflect.DeepEqual(near, expectedNear) {
+		t.Errorf("expected %v, got %v", expectedNear, near)
+	}
+
+	within := MongoGeoWithinRadius("location", 121.47, 31.23, 5000)
+	expectedWithin := MongoFilter{{Key: "location", Value: bson.D{{Key: "$geoWithin", Value: bson.D{
+		{Key: "$centerSphere", Value: bson.A{bson.A{121.47, 31.23}, 5000 / earthRadiusMeters}},
+	}}}}}
+	if !reflect.DeepEqual(within, expectedWithin) {
+		t.Errorf("expected %v, got %v", expectedWithin, within)
+	}
+
+	// 与其他条件以 $and 组合时同样改写，其余条件保持不变
+	mongoBuilder := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	mongoBuilder.SetFilter(near).AddFilter(MongoFilter{{Key: "status", Value: "open"}})
+	counted, err := geoCountFilter(mongoBuilder.buildFilter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedCount := MongoFilter{{Key: "$and", Value: bson.A{
+		expectedWithin,
+		MongoFilter{{Key: "status", Value: "open"}},
+	}}}
+	if !reflect.DeepEqual(counted, expectedCount) {
+		t.Errorf("expected %v, got %v", expectedCount, counted)
+	}
+
+	center := bson.A{121.47, 31.23}
+	tests := []struct {
+		name     string
+		filter   MongoFilter
+		expected MongoFilter
+	}{
+		{
+			name:     "无距离上限",
+			filter:   MongoNear("location", 1, 2, 0),
+			expected: MongoFilter{{Key: "location", Value: bson.D{{Key: "$exists", Value: true}}}},
+		},
+		{
+			name: "$minDistance 排除内圈",
+			filter: MongoFilter{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{
+				{Key: "$geometry", Value: geoPoint(121.47, 31.23)},
+				{Key: "$maxDistance", Value: 5000},
+				{Key: "$minDistance", Value: 1000},
+			}}}}},
+			expected: MongoFilter{{Key: "location", Value: bson.D{
+				{Key: "$geoWithin", Value: bson.D{{Key: "$centerSphere", Value: bson.A{center, 5000 / earthRadiusMeters}}}},
+				{Key: "$not", Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$centerSphere", Value: bson.A{center, 1000 / earthRadiusMeters}}}}}},
+			}}},
+		},
+		{
+			name: "旧版坐标对 $nearSphere 以弧度计",
+			filter: MongoFilter{{Key: "location", Value: bson.D{
+				{Key: "$nearSphere", Value: bson.A{1, 2}},
+				{Key: "$maxDistance", Value: 0.1},
+			}}},
+			expected: MongoFilter{{Key: "location", Value: bson.D{
+				{Key: "$geoWithin", Value: bson.D{{Key: "$centerSphere", Value: bson.A{bson.A{1, 2}, 0.1}}}},
+			}}},
+		},
+		{
+			name: "旧版坐标对 $near 以坐标单位计",
+			filter: MongoFilter{{Key: "location", Value: bson.D{
+				{Key: "$near", Value: bson.A{1, 2}},
+				{Key: "$maxDistance", Value: 3},
+				{Key: "$minDistance", Value: 1},
+			}}},
+			expected: MongoFilter{{Key: "location", Value: bson.D{
+				{Key: "$geoWithin", Value: bson.D{{Key: "$center", Value: bson.A{bson.A{1, 2}, 3.0}}}},
+				{Key: "$not", Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$center", Value: bson.A{bson.A{1, 2}, 1.0}}}}}},
+			}}},
+		},
+		{
+			name: "bson.M 条件",
+			filter: MongoFilter{{Key: "$or", Value: bson.A{bson.M{"location": bson.M{"$near": bson.M{
+				"$geometry":    bson.M{"type": "Point", "coordinates": center},
+				"$maxDistance": 5000,
+			}}}}}},
+			expected: MongoFilter{{Key: "$or", Value: bson.A{bson.M{"location": bson.M{
+				"$geoWithin": bson.D{{Key: "$centerSphere", Value: bson.A{center, 5000 / earthRadiusMeters}}},
+			}}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geoCountFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// 无法等价改写时返回错误，而不是统计出偏大的总数
+	invalid := MongoFilter{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{{Key: "$maxDistance", Value: 5000}}}}}}
+	if _, err := geoCountFilter(invalid); !errors.Is(err, ErrNearCountUnsupported) {
+		t.Errorf("expected ErrNearCountUnsupported, got %v", err)
+	}
+}
+
+// TestMongoBuilder_StableSort 测试稳定排序字段仅在 sort 未包含时追加
+func TestMongoBuilder_StableSort(t *testing.T) {
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, nil, nil))
+	b.SetSort(MongoSort{{Key: "score", Value: -1}})
+	b.SetStableSort("_id")
+	expected := MongoSort{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}
+	if sort := b.listSort(); !reflect.DeepEqual(sort, expected) {
+		t.Errorf("expected %v, got %v", expected, sort)
+	}
+	if len(b.sort) != 1 {
+		t.Errorf("expected configured sort to stay unchanged, got %v", b.sort)
+	}
+
+	b.SetSort(MongoSort{{Key: "_id", Value: -1}})
+	if sort := b.listSort(); len(sort) != 1 {
+		t.Errorf("expected no tiebreaker when sort already has the key, got %v", sort)
+	}
+}
+
+// TestMongoBuilder_ArraySlice 测试 $slice 投影与 $elemMatch 共用数组字段投影，且同一字段后设置的覆盖之前的
+func TestMongoBuilder_ArraySlice(t *testing.T) {
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	explain, err := list.Explain(context.Background(),
+		WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithArraySlice("comments", -5),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, `"$slice": -5`) {
+		t.Errorf("expected $slice projection in explain, got %s", explain)
+	}
+
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, nil, nil))
+	b.SetFields("name")
+	b.SetElemMatchProjection("items", MongoFilter{{Key: "sku", Value: "A1"}})
+	b.SetArraySlice("items", 3)
+	expected := bson.D{
+		{Key: "name", Value: 1},
+		{Key: "items", Value: bson.D{{Key: "$slice", Value: 3}}},
+	}
+	if got, _ := b.buildProjection(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected projection %v, got %v", expected, got)
+	}
+}
+
+// TestMongoBuilder_ProjectExclude 测试排除投影生成 {field: 0}，与包含投影混用时报错（_id 除外）
+func TestMongoBuilder_ProjectExclude(t *testing.T) {
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	explain, err := list.Explain(context.Background(),
+		WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithProjectExclude("content", "attachments"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, `"content": 0`) || !strings.Contains(explain, `"attachments": 0`) {
+		t.Errorf("expected exclusion projection in explain, got %s", explain)
+	}
+
+	_, err = list.Explain(context.Background(),
+		WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithFields("name"),
+		WithProjectExclude("content"),
+	)
+	if !errors.Is(err, ErrMixedProjection) {
+		t.Errorf("expected ErrMixedProjection, got %v", err)
+	}
+
+	testCases := []struct {
+		fields   []string
+		exclude  []string
+		expected bson.D
+	}{
+		{fields: []string{"name"}, exclude: []string{"_id"}, expected: bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 0}}},
+		{fields: []string{"_id"}, exclude: []string{"content"}, expected: bson.D{{Key: "_id", Value: 1}, {Key: "content", Value: 0}}},
+	}
+	for _, tc := range testCases {
+		b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, nil, nil))
+		b.SetFields(tc.fields...)
+		b.SetProjectExclude(tc.exclude...)
+		got, err := b.buildProjection()
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected projection %v, got %v, %v", tc.expected, got, err)
+		}
+	}
+}
+
+// TestMongoBuilder_EphemeralSession 测试未配置会话时，一致性读开启快照会话、记录 operationTime 开启普通会话
+func TestMongoBuilder_EphemeralSession(t *testing.T) {
+	var ts bson.Timestamp
+	testCases := []struct {
+		name      string
+		configure func(b *MongoBuilder[MongoTestEntity])
+		session   bool
+		snapshot  bool
+	}{
+		{name: "默认不开启会话", configure: func(*MongoBuilder[MongoTestEntity]) {}},
+		{name: "一致性读", configure: func(b *MongoBuilder[MongoTestEntity]) { b.SetConsistentRead(true) }, session: true, snapshot: true},
+		{name: "记录 operationTime", configure: func(b *MongoBuilder[MongoTestEntity]) { b.SetOperationTimeSink(&ts) }, session: true},
+		{name: "沿用已配置的会话", configure: func(b *MongoBuilder[MongoTestEntity]) {
+			b.SetOperationTimeSink(&ts).SetSession(&mongo.Session{})
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+			tc.configure(b)
+			opts := b.ephemeralSessionOptions()
+			if (opts != nil) != tc.session {
+				t.Fatalf("expected session %v, got %v", tc.session, opts)
+			}
+			if opts == nil {
+				return
+			}
+			var so options.SessionOptions
+			for _, set := range opts.List() {
+				_ = set(&so)
+			}
+			if snapshot := so.Snapshot != nil && *so.Snapshot; snapshot != tc.snapshot {
+				t.Errorf("expected snapshot %v, got %v", tc.snapshot, snapshot)
+			}
+		})
+	}
+}
+
+// TestMongoBuilder_TimeSeries 测试时序集合的 metaField 前缀补全、时间区间条件与按 timeField 的默认排序
+func TestMongoBuilder_TimeSeries(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	b.SetTimeRange(from, to).
+		AddMetaFilter(bson.D{{Key: "sensorId", Value: 7}, {Key: "$or", Value: bson.A{}}}).
+		SetTimeSeries("ts", "metadata")
+
+	expected := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "metadata.sensorId", Value: 7}, {Key: "$or", Value: bson.A{}}},
+		bson.D{{Key: "ts", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}}},
+	}}}
+	if got := b.buildFilter(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := b.listSort(); !reflect.DeepEqual(got, MongoSort{{Key: "ts", Value: -1}}) {
+		t.Errorf("expected default sort by ts desc, got %v", got)
+	}
+	b.SetSort(bson.D{{Key: "value", Value: 1}})
+	if got := b.listSort(); !reflect.DeepEqual(got, MongoSort{{Key: "value", Value: 1}}) {
+		t.Errorf("expected explicit sort to win, got %v", got)
+	}
+
+	cloned := b.Clone().AddMetaFilter(bson.D{{Key: "", Value: "room-1"}})
+	if len(b.timeSeries.metaFilters) != 1 || len(cloned.buildFilter()[0].Value.(bson.A)) != 3 {
+		t.Error("expected clone to isolate meta filters")
+	}
+
+	// 逻辑运算符中的子条件同样补全前缀
+	nested := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil)).
+		AddMetaFilter(bson.D{
+			{Key: "$or", Value: bson.A{bson.D{{Key: "sensorId", Value: 7}}, bson.M{"room": "a"}}},
+			{Key: "$nor", Value: []bson.D{{{Key: "$and", Value: bson.A{bson.D{{Key: "type", Value: "x"}}}}}}},
+		}).
+		SetTimeSeries("ts", "metadata")
+	expectedNested := bson.D{{Key: "$and", Value: bson.A{bson.D{
+		{Key: "$or", Value: bson.A{bson.D{{Key: "metadata.sensorId", Value: 7}}, bson.M{"metadata.room": "a"}}},
+		{Key: "$nor", Value: bson.A{bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "metadata.type", Value: "x"}}}}}}},
+	}}}}
+	if got := nested.buildFilter(); !reflect.DeepEqual(got, expectedNested) {
+		t.Errorf("expected nested meta filters to be prefixed, got %v", got)
+	}
+
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	explain, err := list.Explain(context.Background(),
+		WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithTimeSeries("ts", "metadata"),
+		WithTimeRange(from, time.Time{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, `"$gte"`) || strings.Contains(explain, `"$lt"`) || !strings.Contains(explain, `"ts": -1`) {
+		t.Errorf("expected open-ended time range and ts sort in explain, got %s", explain)
+	}
+}
+
+// TestMongoDecodeAll_SkipDecodeErrors 测试逐条解码时跳过解码失败的文档并记录其位置与 _id，未配置时整体解码失败
+func TestMongoDecodeAll_SkipDecodeErrors(t *testing.T) {
+	ctx := context.Background()
+	docs := []any{
+		bson.D{{Key: "_id", Value: "a"}, {Key: "id", Value: 1}, {Key: "name", Value: "alice"}},
+		bson.D{{Key: "_id", Value: "b"}, {Key: "id", Value: "two"}, {Key: "name", Value: "bob"}},
+		bson.D{{Key: "_id", Value: "c"}, {Key: "id", Value: 3}, {Key: "name", Value: "carol"}},
+	}
+
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	var list []*MongoTestEntity
+	if err := decodeAll(ctx, cursor, &list, nil, nil); err == nil {
+		t.Error("expected decode error without skipping")
+	}
+
+	cursor, err = mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	list = nil
+	var decodeErrs MongoDecodeErrors
+	if err := decodeAll(ctx, cursor, &list, &decodeErrs, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 3 {
+		t.Errorf("expected documents 1 and 3 decoded, got %+v", list)
+	}
+	if len(decodeErrs) != 1 || decodeErrs[0].Index != 1 || decodeErrs[0].ID.StringValue() != "b" {
+		t.Fatalf("expected document 1 (_id b) recorded, got %+v", decodeErrs)
+	}
+	if !strings.Contains(decodeErrs[0].Error(), `decode document 1 (_id "b")`) || errors.Unwrap(decodeErrs[0]) == nil {
+		t.Errorf("expected wrapped decode error, got %v", decodeErrs[0])
+	}
+}
+
+// TestMongoBuilder_Tailable 测试可追加游标的 Find 选项：TailableAwait 游标类型与等待时间，不含排序与分页
+func TestMongoBuilder_Tailable(t *testing.T) {
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	opts := LoadQueryOptions(WithTailable(), WithMaxAwaitTime(2*time.Second), WithBatchSize(50))
+	querier := list.buildQuerier(opts)
+	list.passQueryOption(querier, opts, true, true)
+	b := querier.(*MongoBuilder[MongoTestEntity])
+	b.SetSort(MongoSort{{Key: "age", Value: -1}})
+
+	if !b.tailable.enabled || b.Clone().tailable != b.tailable {
+		t.Fatalf("expected tailable config applied and cloned, got %+v", b.tailable)
+	}
+	findOpt, err := b.tailFindOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var applied options.FindOptions
+	for _, set := range findOpt.Opts {
+		_ = set(&applied)
+	}
+	if applied.CursorType == nil || *applied.CursorType != options.TailableAwait {
+		t.Errorf("expected tailable await cursor, got %v", applied.CursorType)
+	}
+	if applied.MaxAwaitTime == nil || *applied.MaxAwaitTime != 2*time.Second {
+		t.Errorf("expected max await time 2s, got %v", applied.MaxAwaitTime)
+	}
+	if applied.BatchSize == nil || *applied.BatchSize != 50 {
+		t.Errorf("expected batch size 50, got %v", applied.BatchSize)
+	}
+	if applied.Sort != nil || applied.Skip != nil || applied.Limit != nil {
+		t.Errorf("expected no sort or pagination on tailable cursor, got %+v", applied)
+	}
+}
+
+// TestMongoBuilder_TailableValidator 测试可追加游标逐条执行结果校验与批量处理，校验失败时迭代以 ErrResultRejected 结束
+func TestMongoBuilder_TailableValidator(t *testing.T) {
+	ctx := context.Background()
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	opts := LoadQueryOptions(WithTailable())
+	querier := list.buildQuerier(opts)
+	list.passQueryOption(querier, opts, true, true)
+	b := querier.(*MongoBuilder[MongoTestEntity])
+	b.SetResultValidator(func(_ context.Context, item *MongoTestEntity) error {
+		if item.ID == 2 {
+			return errors.New("tenant mismatch")
+		}
+		return nil
+	})
+	b.SetResultEnricher(func(_ context.Context, items []*MongoTestEntity) error {
+		for _, item := range items {
+			item.Name += "!"
+		}
+		return nil
+	})
+
+	cursor, err := mongo.NewCursorFromDocuments([]any{
+		bson.D{{Key: "id", Value: 1}, {Key: "name", Value: "alice"}},
+		bson.D{{Key: "id", Value: 2}, {Key: "name", Value: "bob"}},
+		bson.D{{Key: "id", Value: 3}, {Key: "name", Value: "carol"}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	var (
+		items   []*MongoTestEntity
+		lastErr error
+	)
+	b.tailCursor(ctx, newMiddlewareContext[MongoTestEntity](&b.builder), cursor, func(item *MongoTestEntity, err error) bool {
+		if err != nil {
+			lastErr = err
+			return false
+		}
+		items = append(items, item)
+		return true
+	})
+	if len(items) != 1 || items[0].Name != "alice!" {
+		t.Errorf("expected only the first document enriched, got %+v", items)
+	}
+	if !errors.Is(lastErr, ErrResultRejected) {
+		t.Errorf("expected ErrResultRejected, got %v", lastErr)
+	}
+}
+
+// TestMongoBuilder_Let 测试 let 变量应用到 Find、Aggregate 选项与 explain 命令
+func TestMongoBuilder_Let(t *testing.T) {
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	opts := LoadQueryOptions(WithData(NewDBProxy(nil, &mongo.Collection{}, nil)), WithMongoLet(bson.M{"minAge": 18}))
+	querier := list.buildQuerier(opts)
+	list.passQueryOption(querier, opts, true, true)
+	b := querier.(*MongoBuilder[MongoTestEntity])
+	b.SetFilter(MongoFilter{{Key: "$expr", Value: bson.D{{Key: "$gte", Value: bson.A{"$age", "$$minAge"}}}}})
+
+	findOpt, err := b.listFindOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var applied options.FindOptions
+	for _, set := range findOpt.Opts {
+		_ = set(&applied)
+	}
+	if !reflect.DeepEqual(applied.Let, bson.M{"minAge": 18}) {
+		t.Errorf("expected let on find options, got %v", applied.Let)
+	}
+	var aggregate options.AggregateOptions
+	for _, set := range b.aggregateOptions().Opts {
+		_ = set(&aggregate)
+	}
+	if !reflect.DeepEqual(aggregate.Let, bson.M{"minAge": 18}) {
+		t.Errorf("expected let on aggregate options, got %v", aggregate.Let)
+	}
+
+	command, err := b.buildExplainCommand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	find := command[0].Value.(bson.D)
+	if let := find[len(find)-1]; let.Key != "let" || !reflect.DeepEqual(let.Value, bson.M{"minAge": 18}) {
+		t.Errorf("expected let in explain command, got %v", find)
+	}
+
+	// Clone 深拷贝 let，SetLet(nil) 清除
+	cloned := b.Clone()
+	cloned.let["minAge"] = 21
+	if b.let["minAge"] != 18 {
+		t.Errorf("expected cloned let isolated, got %v", b.let)
+	}
+	var cleared options.FindOptions
+	for _, set := range b.SetLet(nil).findOptions().Opts {
+		_ = set(&cleared)
+	}
+	if cleared.Let != nil {
+		t.Errorf("expected no let after SetLet(nil), got %v", cleared.Let)
+	}
+}
+
+// TestMongoBuilder_FindOneAndUpdate 测试查找并更新的选项构建与错误处理
+func TestMongoBuilder_FindOneAndUpdate(t *testing.T) {
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	b.SetSort(MongoSort{{Key: "priority", Value: -1}}).SetLet(bson.M{"worker": "w1"})
+	b.SetFields("name")
+
+	updateOpt, err := b.findOneAndUpdateOptions(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var applied options.FindOneAndUpdateOptions
+	for _, set := range updateOpt.Opts {
+		_ = set(&applied)
+	}
+	if applied.ReturnDocument == nil || *applied.ReturnDocument != options.After {
+		t.Errorf("expected return document after, got %v", applied.ReturnDocument)
+	}
+	if !reflect.DeepEqual(applied.Sort, MongoSort{{Key: "priority", Value: -1}}) {
+		t.Errorf("expected list sort, got %v", applied.Sort)
+	}
+	if applied.Projection == nil || applied.Let == nil {
+		t.Errorf("expected projection and let applied, got %+v", applied)
+	}
+
+	updateOpt, _ = b.findOneAndUpdateOptions(false)
+	var before options.FindOneAndUpdateOptions
+	for _, set := range updateOpt.Opts {
+		_ = set(&before)
+	}
+	if before.ReturnDocument == nil || *before.ReturnDocument != options.Before {
+		t.Errorf("expected return document before, got %v", before.ReturnDocument)
+	}
+
+	if _, err := b.FindOneAndUpdate(context.Background(), bson.M{}, false); !errors.Is(err, ErrEmptyUpdate) {
+		t.Errorf("expected ErrEmptyUpdate, got %v", err)
+	}
+
+	proxy, _ := newDryRunGormProxy(t)
+	gormList := NewList[GormTestEntity]()
+	gormList.SetDataSource(Gorm)
+	if _, err := gormList.QueryOneAndUpdate(context.Background(), bson.M{"$set": bson.M{"name": "x"}},
+		WithData(proxy)); !errors.Is(err, ErrFindOneAndUpdateUnsupported) {
+		t.Errorf("expected ErrFindOneAndUpdateUnsupported, got %v", err)
+	}
+}
+
+// TestListQueryOneAndUpdate_Guards 测试查找并更新拒绝结果校验与批量处理，并受 WithTimeout 约束
+func TestListQueryOneAndUpdate_Guards(t *testing.T) {
+	ctx := context.Background()
+	// Connect 不会立即建立连接，服务端不可达时查询在超时后失败
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		_ = client.Disconnect(ctx)
+	}()
+	data := WithData(NewDBProxy(nil, client.Database("test").Collection("jobs"), nil))
+	update := bson.M{"$set": bson.M{"status": "running"}}
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+
+	validator := WithResultValidator(func(context.Context, *MongoTestEntity) error { return nil })
+	if _, err := list.QueryOneAndUpdate(ctx, update, data, validator); !errors.Is(err, ErrUpdateResultChecksUnsupported) {
+		t.Errorf("expected ErrUpdateResultChecksUnsupported for validator, got %v", err)
+	}
+	enricher := WithAfterAll(func(context.Context, []*MongoTestEntity) error { return nil })
+	if _, err := list.QueryOneAndUpdate(ctx, update, data, enricher); !errors.Is(err, ErrUpdateResultChecksUnsupported) {
+		t.Errorf("expected ErrUpdateResultChecksUnsupported for enricher, got %v", err)
+	}
+
+	begin := time.Now()
+	if _, err := list.QueryOneAndUpdate(ctx, update, data, WithTimeout(50*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 5*time.Second {
+		t.Errorf("expected the builder timeout to bound the update, took %v", elapsed)
+	}
+}
+
+// TestMongoBuilder_ArraySortValidation 测试排序字段的位置操作符校验及与 $elemMatch 过滤条件的冲突检查
+func TestMongoBuilder_ArraySortValidation(t *testing.T) {
+	ctx := context.Background()
+	newBuilder := func() *MongoBuilder[MongoTestEntity] {
+		return NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	}
+
+	for _, key := range []string{"items.$.price", "items.$[].price", "items.$[i].price", "items..price", ""} {
+		b := newBuilder().SetSort(MongoSort{{Key: key, Value: 1}})
+		if _, err := b.Explain(ctx); !errors.Is(err, ErrInvalidMongoSortKey) {
+			t.Errorf("sort key %q: expected ErrInvalidMongoSortKey, got %v", key, err)
+		}
+	}
+	if _, err := newBuilder().SetSort(MongoSort{{Key: "$natural", Value: -1}}).Explain(ctx); err != nil {
+		t.Errorf("expected $natural sort to be allowed, got %v", err)
+	}
+
+	// $elemMatch 过滤的数组子字段排序默认冲突，$and 内的条件同样检测
+	elemMatch := MongoElemMatch("items", MongoFilter{{Key: "sku", Value: "A1"}})
+	b := newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "items.price", Value: -1}})
+	if _, err := b.QueryList(ctx); !errors.Is(err, ErrArraySortConflict) {
+		t.Errorf("expected ErrArraySortConflict from QueryList, got %v", err)
+	}
+	b = newBuilder().AddFilter(elemMatch).SetSort(MongoSort{{Key: "items.price", Value: -1}})
+	if _, err := b.Explain(ctx); !errors.Is(err, ErrArraySortConflict) {
+		t.Errorf("expected ErrArraySortConflict for $and filter, got %v", err)
+	}
+	raw, err := ParseMongoFilter(`{"$or": [{"items": {"$elemMatch": {"sku": "A1"}}}, {"vip": true}]}`, "$or", "$elemMatch")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := newBuilder().SetFilter(raw).SetSort(MongoSort{{Key: "items.price", Value: 1}}).Explain(ctx); !errors.Is(err, ErrArraySortConflict) {
+		t.Errorf("expected ErrArraySortConflict for parsed $or filter, got %v", err)
+	}
+
+	// 逻辑运算符的值为 []bson.M、[]bson.D 或 []any 时同样检测
+	for name, filter := range map[string]MongoFilter{
+		"[]bson.M": {{Key: "$or", Value: []bson.M{{"items": bson.M{"$elemMatch": bson.M{"sku": "A1"}}}, {"vip": true}}}},
+		"[]bson.D": {{Key: "$and", Value: []bson.D{elemMatch}}},
+		"[]any":    {{Key: "$nor", Value: []any{bson.M{"vip": true}, elemMatch}}},
+	} {
+		if _, err := newBuilder().SetFilter(filter).SetSort(MongoSort{{Key: "items.price", Value: 1}}).Explain(ctx); !errors.Is(err, ErrArraySortConflict) {
+			t.Errorf("%s: expected ErrArraySortConflict, got %v", name, err)
+		}
+	}
+
+	// 未过滤的数组、同名前缀字段与显式允许时不报错
+	for name, b := range map[string]*MongoBuilder[MongoTestEntity]{
+		"other array":   newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "tags.rank", Value: 1}}),
+		"prefix":        newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "itemsCount", Value: 1}}),
+		"array itself":  newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "items", Value: 1}}),
+		"any element":   newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "items.price", Value: 1}}).SetArraySortAnyElement(true),
+		"cloned option": newBuilder().SetFilter(elemMatch).SetSort(MongoSort{{Key: "items.price", Value: 1}}).SetArraySortAnyElement(true).Clone(),
+	} {
+		if _, err := b.Explain(ctx); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+
+	list := NewList[MongoTestEntity]()
+	list.SetDataSource(MongoDB)
+	list.SetScope(NewMongoScope[MongoTestEntity](elemMatch, MongoSort{{Key: "items.price", Value: 1}}))
+	if _, err := list.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)), WithArraySortAnyElement()); err != nil {
+		t.Errorf("expected WithArraySortAnyElement to allow the sort, got %v", err)
+	}
+}
+
+// mongoFlatEntity 以带点 bson 标签映射嵌套文档的扁平结果结构体
+type mongoFlatEntity struct {
+	ID   string `bson:"_id"`
+	Name string `bson:"name"`
+	City string `bson:"profile.address.city"`
+	Age  int    `bson:"profile.age,omitempty"`
+}
+
+// mongoFlatAudit 以 inline 嵌入结果结构体的公共字段
+type mongoFlatAudit struct {
+	Creator string `bson:"creator"`
+	Region  string `bson:"meta.region"`
+}
+
+// mongoFlatInlineEntity 包含 inline 嵌入结构体的扁平结果结构体
+type mongoFlatInlineEntity struct {
+	ID    string         `bson:"_id"`
+	Audit mongoFlatAudit `bson:",inline"`
+}
+
+// TestMongoBuilder_FlattenInline 测试 inline 嵌入结构体的字段参与投影与带点标签解码，inline map 时不生成投影
+func TestMongoBuilder_FlattenInline(t *testing.T) {
+	b := NewMongoBuilder[mongoFlatInlineEntity](NewDBProxy(nil, &mongo.Collection{}, nil)).SetFlattenProjection()
+	if got, want := b.projectionFields(), []string{"_id", "creator", "meta.region"}; !slices.Equal(got, want) {
+		t.Errorf("expected projection %v, got %v", want, got)
+	}
+
+	cursor, err := mongo.NewCursorFromDocuments([]any{bson.D{
+		{Key: "_id", Value: "a"}, {Key: "creator", Value: "alice"}, {Key: "meta", Value: bson.D{{Key: "region", Value: "cn"}}},
+	}}, nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	var list []*mongoFlatInlineEntity
+	if err := decodeAll(context.Background(), cursor, &list, nil, b.unflattenFunc()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mongoFlatInlineEntity{ID: "a", Audit: mongoFlatAudit{Creator: "alice", Region: "cn"}}
+	if len(list) != 1 || *list[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, list)
+	}
+
+	type withExtra struct {
+		ID    string         `bson:"_id"`
+		Extra map[string]any `bson:",inline"`
+	}
+	if got := NewMongoBuilder[withExtra](nil).SetFlattenProjection().projectionFields(); got != nil {
+		t.Errorf("expected no projection with an inline map, got %v", got)
+	}
+}
+
+// TestMongoBuilder_FlattenDecode 测试带点 bson 标签按嵌套路径解码，以及 SetFlattenProjection 生成的投影
+func TestMongoBuilder_FlattenDecode(t *testing.T) {
+	ctx := context.Background()
+	b := NewMongoBuilder[mongoFlatEntity](NewDBProxy(nil, &mongo.Collection{}, nil))
+	docs := []any{
+		bson.D{{Key: "_id", Value: "a"}, {Key: "name", Value: "alice"}, {Key: "profile", Value: bson.D{
+			{Key: "address", Value: bson.D{{Key: "city", Value: "Shanghai"}}}, {Key: "age", Value: 30},
+		}}},
+		bson.D{{Key: "_id", Value: "b"}, {Key: "name", Value: "bob"}, {Key: "profile", Value: nil}},
+		bson.D{{Key: "_id", Value: "c"}, {Key: "profile", Value: bson.D{{Key: "address", Value: "unknown"}}}},
+	}
+
+	cursor, err := mongo.NewCursorFromDocuments(docs[:2], nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	var list []*mongoFlatEntity
+	if err := decodeAll(ctx, cursor, &list, nil, b.unflattenFunc()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []mongoFlatEntity{{ID: "a", Name: "alice", City: "Shanghai", Age: 30}, {ID: "b", Name: "bob"}}
+	if len(list) != len(expected) || *list[0] != expected[0] || *list[1] != expected[1] {
+		t.Errorf("expected %+v, got %+v", expected, list)
+	}
+
+	// 中间层不是文档时返回错误，而不是静默保持零值
+	cursor, err = mongo.NewCursorFromDocuments(docs[2:], nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	var values []mongoFlatEntity
+	if err := decodeAll(ctx, cursor, &values, nil, b.unflattenFunc()); err == nil || !strings.Contains(err.Error(), "profile.address.city") {
+		t.Errorf("expected path mismatch error, got %v", err)
+	}
+	if NewMongoBuilder[MongoTestEntity](nil).unflattenFunc() != nil {
+		t.Error("expected no unflatten for structs without dotted tags")
+	}
+
+	projection, err := b.buildProjection()
+	if err != nil || projection != nil {
+		t.Fatalf("expected no projection before SetFlattenProjection, got %v, %v", projection, err)
+	}
+	b.SetFlattenProjection()
+	projection, err = b.buildProjection()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := make([]string, 0, len(projection))
+	for _, e := range projection {
+		keys = append(keys, e.Key)
+	}
+	if want := []string{"_id", "name", "profile.address.city", "profile.age"}; !slices.Equal(keys, want) {
+		t.Errorf("expected projection %v, got %v", want, keys)
+	}
+	b.SetFields("name")
+	if projection, _ = b.buildProjection(); len(projection) != 1 || projection[0].Key != "name" {
+		t.Errorf("expected explicit fields to take precedence, got %v", projection)
+	}
+}
@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MongoDecodeError 单个文档解码失败的信息，由 SetSkipDecodeErrors 收集
+type MongoDecodeError struct {
+	Index int           // 文档在本次查询返回结果中的位置（从 0 开始，含解码失败的文档）
+	ID    bson.RawValue // 文档的 _id，投影排除 _id 时为空值
+	Err   error         // 解码错误
+}
+
+// Error 实现 error 接口
+func (e *MongoDecodeError) Error() string {
+	return fmt.Sprintf("decode document %d (_id %s): %v", e.Index, e.ID, e.Err)
+}
+
+// Unwrap 返回原始解码错误
+func (e *MongoDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// MongoDecodeErrors SetSkipDecodeErrors 收集的解码失败文档列表
+type MongoDecodeErrors []*MongoDecodeError
+
+// SetSkipDecodeErrors 设置列表查询逐条解码并跳过解码失败的文档，失败信息依次追加到 sink，
+// 适用于字段类型不一致或结构演进中的集合，避免单个文档导致整个查询失败；传入 nil 表示恢复整体解码。
+// 作用于 QueryList、QueryValues 与 $geoNear 查询，游标查询遇到解码错误仍会失败；
+// 跳过的文档不影响总数统计，sink 需在每次查询前由调用方准备
+func (m *MongoBuilder[R]) SetSkipDecodeErrors(sink *MongoDecodeErrors) *MongoBuilder[R] {
+	m.decodeErrSink = sink
+	return m
+}
+
+// decodeAll 将 cursor 中的全部文档解码到 dest；sink 非 nil 时逐条解码，跳过失败的文档并记录到 sink；
+// unflatten 非 nil 时同样逐条解码，并以原始文档补充带点标签字段
+func decodeAll[T any](ctx context.Context, cursor *mongo.Cursor, dest *[]T, sink *MongoDecodeErrors, unflatten func(raw bson.Raw, item any) error) error {
+	if sink == nil && unflatten == nil {
+		return cursor.All(ctx, dest)
+	}
+	for i := 0; cursor.Next(ctx); i++ {
+		var item T
+		err := cursor.Decode(&item)
+		if err == nil && unflatten != nil {
+			err = unflatten(cursor.Current, &item)
+		}
+		if err != nil {
+			if sink == nil {
+				return err
+			}
+			*sink = append(*sink, &MongoDecodeError{Index: i, ID: cursor.Current.Lookup("_id"), Err: err})
+			continue
+		}
+		*dest = append(*dest, item)
+	}
+	return cursor.Err()
+}
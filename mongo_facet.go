@@ -0,0 +1,166 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrInvalidFacet 分组统计维度名称或字段为空，或名称与 data/total 重复
+var ErrInvalidFacet = errors.New("invalid mongo facet")
+
+const (
+	facetDataKey  = "data"  // $facet 中分页数据子管道的名称
+	facetTotalKey = "total" // $facet 中总数统计子管道的名称
+)
+
+// MongoFacet 单个分组统计维度，编译为 $facet 中的 {$group: {_id: "$Field", count: {$sum: 1}}} 子管道
+type MongoFacet struct {
+	Name  string // 结果中的分组名称，不能为 data 或 total
+	Field string // 分组字段，支持 "a.b" 嵌套路径
+}
+
+// MongoFacetBucket 分组统计的单个桶，按 Count 降序排列
+type MongoFacetBucket struct {
+	Value any   `bson:"_id"`   // 分组字段取值
+	Count int64 `bson:"count"` // 该取值的文档数
+}
+
+// MongoFacetResult QueryFacet 的查询结果
+type MongoFacetResult[R any] struct {
+	Items  []*R                          // 当前页数据
+	Total  int64                         // 总数（needTotal 为 false 时为 0）
+	Groups map[string][]MongoFacetBucket // 各分组维度的统计结果，键为 MongoFacet.Name
+}
+
+// QueryFacet 通过单个 $facet 聚合在一次往返中返回分页数据、总数与多个维度的分组统计
+// 数据子管道沿用 sort、字段投影与分页配置，总数子管道遵循 needTotal 与 totalLimit；
+// 聚合的 $match 不支持 $near，过滤条件中的 $near 会改写为等价范围的 $geoWithin（结果不再按距离排序）。
+// 该方法不经过中间件与钩子
+func (m *MongoBuilder[R]) QueryFacet(ctx context.Context, facets ...MongoFacet) (*MongoFacetResult[R], error) {
+	if err := m.builder.prepareAndValidate(); err != nil {
+		return nil, err
+	}
+	pipeline, err := m.buildFacetPipeline(facets)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = m.withSession(ctx)
+	defer m.builder.observeDBCall(DBCallAggregate)()
+	cursor, err := m.collection().Aggregate(ctx, pipeline, m.aggregateOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		_ = cursor.Close(ctx)
+	}(cursor, ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, err
+		}
+		return &MongoFacetResult[R]{Items: []*R{}, Groups: map[string][]MongoFacetBucket{}}, nil
+	}
+	return m.decodeFacetResult(cursor.Current, facets)
+}
+
+// buildFacetPipeline 构建 [$match, $facet] 聚合管道
+func (m *MongoBuilder[R]) buildFacetPipeline(facets []MongoFacet) (mongo.Pipeline, error) {
+	data := mongo.Pipeline{}
+	if sort := m.listSort(); len(sort) > 0 {
+		data = append(data, bson.D{{Key: "$sort", Value: sort}})
+	}
+	if m.builder.needPagination {
+		if m.builder.limit == 0 {
+			m.builder.limit = defaultLimit
+		}
+		data = append(data,
+			bson.D{{Key: "$skip", Value: int64(m.builder.start)}},
+			bson.D{{Key: "$limit", Value: int64(m.builder.limit)}},
+		)
+	}
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		data = append(data, bson.D{{Key: "$project", Value: projection}})
+	}
+
+	facetStage := bson.D{{Key: facetDataKey, Value: data}}
+	if m.builder.needTotal {
+		total := mongo.Pipeline{}
+		if m.builder.totalLimit > 0 {
+			total = append(total, bson.D{{Key: "$limit", Value: m.builder.countProbeLimit()}})
+		}
+		total = append(total, bson.D{{Key: "$count", Value: "count"}})
+		facetStage = append(facetStage, bson.E{Key: facetTotalKey, Value: total})
+	}
+
+	seen := make(map[string]struct{}, len(facets))
+	for _, facet := range facets {
+		if facet.Name == "" || facet.Field == "" || facet.Name == facetDataKey || facet.Name == facetTotalKey {
+			return nil, fmt.Errorf("%w: name %q, field %q", ErrInvalidFacet, facet.Name, facet.Field)
+		}
+		if _, ok := seen[facet.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrInvalidFacet, facet.Name)
+		}
+		seen[facet.Name] = struct{}{}
+		facetStage = append(facetStage, bson.E{Key: facet.Name, Value: mongo.Pipeline{
+			{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$" + facet.Field},
+				{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			}}},
+			{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		}})
+	}
+
+	match, err := geoCountFilter(m.buildFilter())
+	if err != nil {
+		return nil, err
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: facetStage}},
+	}, nil
+}
+
+// decodeFacetResult 将 $facet 返回的单个文档拆分为分页数据、总数与各维度分组结果
+func (m *MongoBuilder[R]) decodeFacetResult(doc bson.Raw, facets []MongoFacet) (*MongoFacetResult[R], error) {
+	result := &MongoFacetResult[R]{
+		Items:  make([]*R, 0, m.builder.resultCapacityHint()),
+		Groups: make(map[string][]MongoFacetBucket, len(facets)),
+	}
+	if err := m.decodeValue(doc.Lookup(facetDataKey), &result.Items); err != nil {
+		return nil, fmt.Errorf("decode facet %s: %w", facetDataKey, err)
+	}
+	if err := m.unflattenArray(doc.Lookup(facetDataKey), result.Items); err != nil {
+		return nil, fmt.Errorf("decode facet %s: %w", facetDataKey, err)
+	}
+
+	if m.builder.needTotal {
+		var counts []struct {
+			Count int64 `bson:"count"`
+		}
+		if err := m.decodeValue(doc.Lookup(facetTotalKey), &counts); err != nil {
+			return nil, fmt.Errorf("decode facet %s: %w", facetTotalKey, err)
+		}
+		// 无匹配文档时 $count 不输出任何文档
+		if len(counts) > 0 {
+			result.Total = m.builder.capTotal(counts[0].Count)
+		}
+	}
+
+	for _, facet := range facets {
+		buckets := []MongoFacetBucket{}
+		if err := m.decodeValue(doc.Lookup(facet.Name), &buckets); err != nil {
+			return nil, fmt.Errorf("decode facet %s: %w", facet.Name, err)
+		}
+		result.Groups[facet.Name] = buckets
+	}
+	return result, nil
+}
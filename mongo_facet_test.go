@@ -0,0 +1,88 @@
+package builder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TestMongoBuilder_FacetPipeline 测试 $facet 管道包含分页数据、受限总数与分组子管道
+func TestMongoBuilder_FacetPipeline(t *testing.T) {
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, nil, nil))
+	b.SetFilter(MongoFilter{{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}}}})
+	b.SetSort(MongoSort{{Key: "age", Value: -1}})
+	b.SetStart(20).SetLimit(10).SetNeedTotal(true).SetTotalLimit(1000).SetNeedPagination(true)
+
+	pipeline, err := b.buildFacetPipeline([]MongoFacet{{Name: "byName", Field: "name"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mongo.Pipeline{
+		{{Key: "$match", Value: MongoFilter{{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}}}}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "data", Value: mongo.Pipeline{
+				{{Key: "$sort", Value: MongoSort{{Key: "age", Value: -1}}}},
+				{{Key: "$skip", Value: int64(20)}},
+				{{Key: "$limit", Value: int64(10)}},
+			}},
+			{Key: "total", Value: mongo.Pipeline{
+				{{Key: "$limit", Value: int64(1001)}},
+				{{Key: "$count", Value: "count"}},
+			}},
+			{Key: "byName", Value: mongo.Pipeline{
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$name"},
+					{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			}},
+		}}},
+	}
+	if !reflect.DeepEqual(pipeline, expected) {
+		t.Errorf("unexpected pipeline:\n got %v\nwant %v", pipeline, expected)
+	}
+
+	for _, facets := range [][]MongoFacet{
+		{{Name: "", Field: "name"}},
+		{{Name: "total", Field: "name"}},
+		{{Name: "a", Field: "name"}, {Name: "a", Field: "age"}},
+	} {
+		if _, err := b.buildFacetPipeline(facets); !errors.Is(err, ErrInvalidFacet) {
+			t.Errorf("facets %v: expected ErrInvalidFacet, got %v", facets, err)
+		}
+	}
+}
+
+// TestMongoBuilder_DecodeFacetResult 测试将 $facet 单个结果文档拆分为数据、总数与分组
+func TestMongoBuilder_DecodeFacetResult(t *testing.T) {
+	b := NewMongoBuilder[MongoTestEntity](NewDBProxy(nil, nil, nil))
+	b.SetNeedTotal(true)
+
+	doc, err := bson.Marshal(bson.D{
+		{Key: "data", Value: bson.A{bson.D{{Key: "id", Value: 1}, {Key: "name", Value: "alice"}}}},
+		{Key: "total", Value: bson.A{bson.D{{Key: "count", Value: 42}}}},
+		{Key: "byName", Value: bson.A{bson.D{{Key: "_id", Value: "alice"}, {Key: "count", Value: 3}}}},
+		{Key: "byAge", Value: bson.A{}},
+	})
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	result, err := b.decodeFacetResult(doc, []MongoFacet{{Name: "byName", Field: "name"}, {Name: "byAge", Field: "age"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Name != "alice" || result.Total != 42 {
+		t.Errorf("unexpected data: items=%v total=%d", result.Items, result.Total)
+	}
+	byName := result.Groups["byName"]
+	if len(byName) != 1 || byName[0].Value != "alice" || byName[0].Count != 3 {
+		t.Errorf("unexpected byName buckets: %v", byName)
+	}
+	if byAge, ok := result.Groups["byAge"]; !ok || len(byAge) != 0 {
+		t.Errorf("expected empty byAge buckets, got %v", byAge)
+	}
+}
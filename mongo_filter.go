@@ -0,0 +1,333 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	// ErrInvalidMongoFilter JSON 过滤条件无法解析为 MongoDB 文档
+	ErrInvalidMongoFilter = errors.New("invalid mongo filter")
+	// ErrMongoOperatorNotAllowed JSON 过滤条件使用了白名单之外的操作符（如 $where、$function）
+	ErrMongoOperatorNotAllowed = errors.New("mongo operator not allowed")
+	// ErrNearCountUnsupported $near / $nearSphere 条件无法改写为匹配范围等价的 $geoWithin，总数统计失败而不是返回偏大的总数
+	ErrNearCountUnsupported = errors.New("cannot rewrite $near for count")
+)
+
+// DefaultMongoFilterOperators ParseMongoFilter 未指定白名单时允许的查询操作符
+// 仅包含比较、逻辑、元素与数组类操作符，$where、$function、$expr 等可执行代码或表达式的操作符默认禁止
+var DefaultMongoFilterOperators = []string{
+	"$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$in", "$nin",
+	"$and", "$or", "$nor", "$not",
+	"$exists", "$type", "$regex", "$options",
+	"$all", "$elemMatch", "$size",
+}
+
+// ParseMongoFilter 将 JSON（支持 MongoDB Extended JSON，如 {"$oid": ...}、{"$date": ...}）解析为过滤条件
+// 用于回放保存的搜索条件等场景；JSON 非法时返回 ErrInvalidMongoFilter，
+// 出现 allowedOperators 之外的操作符时返回 ErrMongoOperatorNotAllowed，allowedOperators 为空时使用 DefaultMongoFilterOperators
+func ParseMongoFilter(jsonStr string, allowedOperators ...string) (MongoFilter, error) {
+	var filter bson.D
+	if err := bson.UnmarshalExtJSON([]byte(jsonStr), false, &filter); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMongoFilter, err)
+	}
+	if len(allowedOperators) == 0 {
+		allowedOperators = DefaultMongoFilterOperators
+	}
+	if err := checkMongoOperators(filter, allowedOperators); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// checkMongoOperators 递归检查文档与数组中以 $ 开头的键是否都在白名单内
+func checkMongoOperators(value any, allowed []string) error {
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if strings.HasPrefix(e.Key, "$") && !slices.Contains(allowed, e.Key) {
+				return fmt.Errorf("%w: %s", ErrMongoOperatorNotAllowed, e.Key)
+			}
+			if err := checkMongoOperators(e.Value, allowed); err != nil {
+				return err
+			}
+		}
+	case bson.M:
+		for key, elem := range v {
+			if strings.HasPrefix(key, "$") && !slices.Contains(allowed, key) {
+				return fmt.Errorf("%w: %s", ErrMongoOperatorNotAllowed, key)
+			}
+			if err := checkMongoOperators(elem, allowed); err != nil {
+				return err
+			}
+		}
+	case bson.A:
+		for _, elem := range v {
+			if err := checkMongoOperators(elem, allowed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MongoArrayContains 创建数组字段包含任一给定值的过滤条件：{field: {$in: values}}
+// 对数组字段，只要任一元素命中 values 即匹配；对标量字段等价于普通 $in
+func MongoArrayContains(field string, values ...any) MongoFilter {
+	return MongoFilter{{Key: field, Value: bson.D{{Key: "$in", Value: bson.A(values)}}}}
+}
+
+// MongoArrayContainsAll 创建数组字段同时包含全部给定值的过滤条件：{field: {$all: values}}
+func MongoArrayContainsAll(field string, values ...any) MongoFilter {
+	return MongoFilter{{Key: field, Value: bson.D{{Key: "$all", Value: bson.A(values)}}}}
+}
+
+// MongoElemMatch 创建数组元素匹配的过滤条件：{field: {$elemMatch: subfilter}}
+// 要求同一个数组元素同时满足 subfilter 中的全部条件
+func MongoElemMatch(field string, subfilter MongoFilter) MongoFilter {
+	return MongoFilter{{Key: field, Value: bson.D{{Key: "$elemMatch", Value: subfilter}}}}
+}
+
+// earthRadiusMeters 地球平均半径（米），用于将距离换算为 $centerSphere 所需的弧度
+const earthRadiusMeters = 6378100.0
+
+// MongoNear 创建按距离由近到远返回的地理位置过滤条件（要求字段建有 2dsphere 索引）：
+// {field: {$near: {$geometry: {type: "Point", coordinates: [lng, lat]}, $maxDistance: maxMeters}}}
+// maxMeters <= 0 表示不限制距离；未通过 SetSort 指定排序时结果即按距离排序
+func MongoNear(field string, lng, lat, maxMeters float64) MongoFilter {
+	near := bson.D{{Key: "$geometry", Value: geoPoint(lng, lat)}}
+	if maxMeters > 0 {
+		near = append(near, bson.E{Key: "$maxDistance", Value: maxMeters})
+	}
+	return MongoFilter{{Key: field, Value: bson.D{{Key: "$near", Value: near}}}}
+}
+
+// MongoGeoWithinRadius 创建圆形范围内的地理位置过滤条件，结果不按距离排序：
+// {field: {$geoWithin: {$centerSphere: [[lng, lat], radiusMeters / 地球半径]}}}
+// 与 $near 不同，$geoWithin 可用于 CountDocuments 与任意排序
+func MongoGeoWithinRadius(field string, lng, lat, radiusMeters float64) MongoFilter {
+	return MongoFilter{{Key: field, Value: geoWithinSphere(bson.A{lng, lat}, radiusMeters)}}
+}
+
+// geoPoint 创建 GeoJSON Point
+func geoPoint(lng, lat float64) bson.D {
+	return bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{lng, lat}}}
+}
+
+// geoWithinSphere 创建 {$geoWithin: {$centerSphere: [center, radiusMeters / 地球半径]}}
+func geoWithinSphere(center any, radiusMeters float64) bson.D {
+	return geoWithinShape("$centerSphere", center, radiusMeters/earthRadiusMeters)
+}
+
+// geoWithinShape 创建 {$geoWithin: {shape: [center, radius]}}，shape 为 $centerSphere（弧度）或 $center（坐标单位）
+func geoWithinShape(shape string, center any, radius float64) bson.D {
+	return bson.D{{Key: "$geoWithin", Value: bson.D{
+		{Key: shape, Value: bson.A{center, radius}},
+	}}}
+}
+
+// geoCountFilter 将过滤条件中的 $near / $nearSphere 改写为匹配范围等价的 $geoWithin，供 CountDocuments 使用
+// CountDocuments 基于聚合 $match 实现，不允许出现 $near。GeoJSON 写法按米换算为 $centerSphere；
+// 旧版坐标对写法中 $nearSphere 的距离以弧度计，改写为 $centerSphere，$near 按 2d 索引的坐标单位计，改写为 $center；
+// $minDistance 以 $not 排除内圈，无距离上限时外圈改写为 {$exists: true}；无法等价改写时返回 ErrNearCountUnsupported
+func geoCountFilter(filter MongoFilter) (MongoFilter, error) {
+	rewritten, err := rewriteNear(filter)
+	if err != nil {
+		return nil, err
+	}
+	out, _ := rewritten.(bson.D)
+	return out, nil
+}
+
+// rewriteNear 递归改写 bson.D、bson.M 与数组中的 $near 条件
+func rewriteNear(value any) (any, error) {
+	switch v := value.(type) {
+	case bson.D:
+		return rewriteNearEntries(v)
+	case bson.M:
+		out, err := rewriteNearMap(v)
+		return bson.M(out), err
+	case map[string]any:
+		return rewriteNearMap(v)
+	case bson.A:
+		return rewriteNearSlice(v)
+	case []any:
+		return rewriteNearSlice(v)
+	case []bson.D:
+		return rewriteNearSlice(v)
+	case []bson.M:
+		return rewriteNearSlice(v)
+	default:
+		return value, nil
+	}
+}
+
+// rewriteNearSlice 逐个改写数组元素（如 $and、$or 的子条件），统一返回 bson.A
+func rewriteNearSlice[T any](items []T) (bson.A, error) {
+	out := make(bson.A, 0, len(items))
+	for _, item := range items {
+		rewritten, err := rewriteNear(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rewritten)
+	}
+	return out, nil
+}
+
+// rewriteNearMap 以键值对形式改写 map 文档
+func rewriteNearMap(m map[string]any) (map[string]any, error) {
+	rewritten, err := rewriteNearEntries(documentEntries(m))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(rewritten))
+	for _, e := range rewritten {
+		out[e.Key] = e.Value
+	}
+	return out, nil
+}
+
+// rewriteNearEntries 改写单个文档：$near 就地替换为 $geoWithin 等操作符，其余键递归改写
+func rewriteNearEntries(d bson.D) (bson.D, error) {
+	isNear := func(e bson.E) bool {
+		return e.Key == "$near" || e.Key == "$nearSphere"
+	}
+	hasNear := slices.ContainsFunc(d, isNear)
+	out := make(bson.D, 0, len(d)+1)
+	for _, e := range d {
+		switch {
+		case isNear(e):
+			within, err := nearToGeoWithin(e.Key, e.Value, d)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, within...)
+		case hasNear && (e.Key == "$maxDistance" || e.Key == "$minDistance"):
+			// 旧版坐标对写法的距离参数与 $near 同级，已并入改写结果
+		default:
+			value, err := rewriteNear(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, bson.E{Key: e.Key, Value: value})
+		}
+	}
+	return out, nil
+}
+
+// nearToGeoWithin 将单个 $near / $nearSphere 操作数改写为 $geoWithin 操作符（以操作符列表返回，便于就地替换）
+// siblings 为 $near 所在的文档，旧版坐标对写法从中读取同级的 $maxDistance 与 $minDistance
+func nearToGeoWithin(op string, near any, siblings bson.D) (bson.D, error) {
+	switch v := near.(type) {
+	case bson.D, bson.M, map[string]any:
+		// GeoJSON 写法：{$geometry: point, $maxDistance: 米, $minDistance: 米}
+		params := documentEntries(v)
+		var center any
+		for _, e := range documentEntries(lookupEntry(params, "$geometry")) {
+			if e.Key == "coordinates" {
+				center = e.Value
+			}
+		}
+		if center == nil {
+			return nil, fmt.Errorf("%w: %s without $geometry coordinates", ErrNearCountUnsupported, op)
+		}
+		maxMeters, minMeters, err := nearDistances(op, params)
+		if err != nil {
+			return nil, err
+		}
+		return geoAnnulus("$centerSphere", center, maxMeters/earthRadiusMeters, minMeters/earthRadiusMeters), nil
+	case bson.A, []any, []float64, []int:
+		// 旧版坐标对写法：距离参数与 $near 同级
+		shape := "$center"
+		if op == "$nearSphere" {
+			shape = "$centerSphere"
+		}
+		maxDistance, minDistance, err := nearDistances(op, siblings)
+		if err != nil {
+			return nil, err
+		}
+		return geoAnnulus(shape, v, maxDistance, minDistance), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported %s operand %T", ErrNearCountUnsupported, op, near)
+	}
+}
+
+// nearDistances 读取 $maxDistance 与 $minDistance，未设置时为 0，非数值时返回 ErrNearCountUnsupported
+func nearDistances(op string, params bson.D) (maxDistance, minDistance float64, err error) {
+	for _, e := range params {
+		if e.Key != "$maxDistance" && e.Key != "$minDistance" {
+			continue
+		}
+		distance, ok := toFloat64(e.Value)
+		if !ok {
+			return 0, 0, fmt.Errorf("%w: %s %s is %T", ErrNearCountUnsupported, op, e.Key, e.Value)
+		}
+		if e.Key == "$maxDistance" {
+			maxDistance = distance
+		} else {
+			minDistance = distance
+		}
+	}
+	return maxDistance, minDistance, nil
+}
+
+// geoAnnulus 创建外圈 $geoWithin 与内圈 $not $geoWithin 组成的环形范围，maxRadius <= 0 时外圈改写为 {$exists: true}
+func geoAnnulus(shape string, center any, maxRadius, minRadius float64) bson.D {
+	out := bson.D{{Key: "$exists", Value: true}}
+	if maxRadius > 0 {
+		out = geoWithinShape(shape, center, maxRadius)
+	}
+	if minRadius > 0 {
+		out = append(out, bson.E{Key: "$not", Value: geoWithinShape(shape, center, minRadius)})
+	}
+	return out
+}
+
+// documentEntries 将 bson.D、bson.M 或 map 文档转换为键值对列表，其他类型返回 nil
+func documentEntries(doc any) bson.D {
+	switch v := doc.(type) {
+	case bson.D:
+		return v
+	case bson.M:
+		return documentEntries(map[string]any(v))
+	case map[string]any:
+		entries := make(bson.D, 0, len(v))
+		for k, value := range v {
+			entries = append(entries, bson.E{Key: k, Value: value})
+		}
+		return entries
+	}
+	return nil
+}
+
+// lookupEntry 返回键值对列表中 key 对应的值，不存在时返回 nil
+func lookupEntry(entries bson.D, key string) any {
+	for _, e := range entries {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// toFloat64 将常见数值类型转换为 float64
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
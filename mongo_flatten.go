@@ -0,0 +1,161 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+// mongoFlatField 结果结构体中以带点 bson 标签（如 bson:"profile.city"）映射嵌套路径的字段
+type mongoFlatField struct {
+	index []int    // 字段在结构体中的序号路径，inline 嵌入结构体中的字段包含多级序号
+	path  []string // 嵌套路径的各段
+}
+
+// mongoStructFields 结果结构体顶层字段的 bson 路径，以及其中需要按嵌套路径解码的带点字段
+type mongoStructFields struct {
+	paths     []string
+	flat      []mongoFlatField
+	inlineMap bool // 包含 inline map 字段，未映射的键都会解码到该 map 中，不能按字段生成投影
+}
+
+// mongoStructFieldsCache 按结构体类型缓存解析结果
+var mongoStructFieldsCache sync.Map // map[reflect.Type]*mongoStructFields
+
+// mongoFieldsOf 解析结构体类型的顶层 bson 字段：标签名为空时使用小写字段名，跳过未导出与 "-" 字段，
+// inline 结构体的字段按所在层级展开到顶层
+func mongoFieldsOf(t reflect.Type) *mongoStructFields {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if cached, ok := mongoStructFieldsCache.Load(t); ok {
+		return cached.(*mongoStructFields)
+	}
+
+	fields := &mongoStructFields{}
+	if t.Kind() == reflect.Struct {
+		fields.collect(t, nil)
+	}
+	actual, _ := mongoStructFieldsCache.LoadOrStore(t, fields)
+	return actual.(*mongoStructFields)
+}
+
+// collect 收集结构体 t 的 bson 字段，index 为 t 在结果结构体中的序号路径
+func (fields *mongoStructFields) collect(t reflect.Type, index []int) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("bson"), ",")
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(slices.Clone(index), i)
+		if strings.Contains(","+opts+",", ",inline,") {
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				fields.collect(f.Type, fieldIndex)
+			case reflect.Map:
+				fields.inlineMap = true
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields.paths = append(fields.paths, name)
+		if strings.Contains(name, ".") {
+			fields.flat = append(fields.flat, mongoFlatField{index: fieldIndex, path: strings.Split(name, ".")})
+		}
+	}
+}
+
+// SetFlattenProjection 设置未指定 SetFields 时按结果结构体的 bson 标签生成包含投影，
+// 带点标签（如 bson:"profile.city"）投影为对应的嵌套路径，使扁平结构体只拉取实际映射的字段；
+// 带点标签字段的解码不依赖该设置，总会按嵌套路径从文档中取值
+func (m *MongoBuilder[R]) SetFlattenProjection() *MongoBuilder[R] {
+	m.flattenProjection = true
+	return m
+}
+
+// projectionFields 返回包含投影字段：优先使用 SetFields，开启 SetFlattenProjection 时由结果结构体的 bson 标签生成
+func (m *MongoBuilder[R]) projectionFields() []string {
+	if len(m.builder.fields) > 0 || !m.flattenProjection {
+		return m.builder.fields
+	}
+	fields := mongoFieldsOf(reflect.TypeFor[R]())
+	if fields.inlineMap {
+		return nil
+	}
+	return fields.paths
+}
+
+// unflatten 按嵌套路径从原始文档中解码带点标签字段，item 为指向结果结构体的指针（可多级）
+// 驱动按键名原样匹配字段，带点标签无法直接命中嵌套文档，未经补充时会静默保持零值；
+// 文档中不存在该路径或中间层为 null 时保持零值，中间层不是文档或值类型不匹配时返回错误
+func (m *MongoBuilder[R]) unflatten(raw bson.Raw, item any) error {
+	flat := mongoFieldsOf(reflect.TypeFor[R]()).flat
+	if len(flat) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(item)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	for _, f := range flat {
+		value, err := raw.LookupErr(f.path...)
+		var depthErr bsoncore.InvalidDepthTraversalError
+		switch {
+		case errors.Is(err, bsoncore.ErrElementNotFound):
+			continue
+		case errors.As(err, &depthErr) && depthErr.Type == bsoncore.TypeNull:
+			continue
+		case err != nil:
+			return fmt.Errorf("decode %q: %w", strings.Join(f.path, "."), err)
+		}
+		if err := m.decodeValue(value, v.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return fmt.Errorf("decode %q: %w", strings.Join(f.path, "."), err)
+		}
+	}
+	return nil
+}
+
+// unflattenFunc 结果结构体包含带点标签字段时返回 unflatten，否则返回 nil 以便整体解码
+func (m *MongoBuilder[R]) unflattenFunc() func(raw bson.Raw, item any) error {
+	if len(mongoFieldsOf(reflect.TypeFor[R]()).flat) == 0 {
+		return nil
+	}
+	return m.unflatten
+}
+
+// unflattenArray 为由文档数组解码得到的结果补充带点标签字段，items 与数组元素一一对应
+func (m *MongoBuilder[R]) unflattenArray(array bson.RawValue, items []*R) error {
+	if m.unflattenFunc() == nil {
+		return nil
+	}
+	values, err := array.Array().Values()
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		doc, ok := value.DocumentOK()
+		if !ok || i >= len(items) {
+			break
+		}
+		if err := m.unflatten(doc, items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrGeoNearCursorUnsupported 配置 $geoNear 距离排序后不支持游标查询（距离无法作为游标续查条件）
+var ErrGeoNearCursorUnsupported = errors.New("geo near sorting does not support cursor queries")
+
+// defaultGeoDistanceField $geoNear 写入计算距离的默认字段名
+const defaultGeoDistanceField = "distance"
+
+// mongoGeoNear $geoNear 距离排序配置
+type mongoGeoNear struct {
+	field         string  // 建有 2dsphere 索引的地理位置字段
+	lng, lat      float64 // 参考点经纬度
+	maxMeters     float64 // 最大距离（米），<= 0 表示不限制
+	distanceField string  // 写入计算距离（米）的字段名
+}
+
+// SetGeoNear 列表查询改为以 $geoNear 为首阶段的聚合，结果按到参考点 (lng, lat) 的距离由近到远排列，
+// 计算出的距离（米）写入 distanceField（为空时使用 "distance"），R 中需有对应 bson 标签的字段接收，
+// 如 Distance float64 `bson:"distance,omitempty"`；过滤条件作为 $geoNear 的 query，分页在其后应用，
+// SetSort 设置的排序作为同距离时的次级排序。filter 中不能再包含 $near，游标查询返回 ErrGeoNearCursorUnsupported
+func (m *MongoBuilder[R]) SetGeoNear(field string, lng, lat, maxMeters float64, distanceField string) *MongoBuilder[R] {
+	if distanceField == "" {
+		distanceField = defaultGeoDistanceField
+	}
+	m.geoNear = &mongoGeoNear{field: field, lng: lng, lat: lat, maxMeters: maxMeters, distanceField: distanceField}
+	return m
+}
+
+// stage 构建 $geoNear 阶段
+func (g *mongoGeoNear) stage(filter MongoFilter) bson.D {
+	stage := bson.D{
+		{Key: "near", Value: geoPoint(g.lng, g.lat)},
+		{Key: "distanceField", Value: g.distanceField},
+		{Key: "key", Value: g.field},
+		{Key: "spherical", Value: true},
+	}
+	if g.maxMeters > 0 {
+		stage = append(stage, bson.E{Key: "maxDistance", Value: g.maxMeters})
+	}
+	if len(filter) > 0 {
+		stage = append(stage, bson.E{Key: "query", Value: filter})
+	}
+	return bson.D{{Key: "$geoNear", Value: stage}}
+}
+
+// countFilter 返回与 $geoNear 匹配范围等价的总数统计条件：有距离上限时追加 $geoWithin，否则要求地理位置字段存在
+func (g *mongoGeoNear) countFilter(filter MongoFilter) MongoFilter {
+	within := MongoFilter{{Key: g.field, Value: bson.D{{Key: "$exists", Value: true}}}}
+	if g.maxMeters > 0 {
+		within = MongoGeoWithinRadius(g.field, g.lng, g.lat, g.maxMeters)
+	}
+	if len(filter) == 0 {
+		return within
+	}
+	return MongoFilter{{Key: "$and", Value: bson.A{filter, within}}}
+}
+
+// buildGeoNearPipeline 构建 [$geoNear, $sort, $skip, $limit, $project] 聚合管道
+func (m *MongoBuilder[R]) buildGeoNearPipeline(filter MongoFilter) (mongo.Pipeline, error) {
+	g := m.geoNear
+	pipeline := mongo.Pipeline{g.stage(filter)}
+	// $geoNear 的输出已按距离排序，仅在需要次级排序时追加 $sort
+	if sort := m.listSort(); len(sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: slices.Concat(bson.D{{Key: g.distanceField, Value: 1}}, sort)}})
+	}
+	if m.builder.needPagination {
+		if m.builder.limit == 0 {
+			m.builder.limit = defaultLimit
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$skip", Value: int64(m.builder.start)}},
+			bson.D{{Key: "$limit", Value: int64(m.builder.pageFetchLimit())}},
+		)
+	}
+
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		// 包含式投影需显式保留距离字段
+		if slices.ContainsFunc(m.builder.fields, func(f string) bool { return f != "_id" }) {
+			projection = append(projection, bson.E{Key: g.distanceField, Value: 1})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: projection}})
+	}
+	return pipeline, nil
+}
+
+// aggregateGeoNear 执行 $geoNear 聚合并通过 decode 解码返回的文档
+func (m *MongoBuilder[R]) aggregateGeoNear(ctx context.Context, filter MongoFilter, decode func(*mongo.Cursor) error) error {
+	pipeline, err := m.buildGeoNearPipeline(filter)
+	if err != nil {
+		return err
+	}
+	defer m.builder.observeDBCall(DBCallAggregate)()
+	cursor, err := m.collection().Aggregate(ctx, pipeline, m.aggregateOptions())
+	if err != nil {
+		return err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		_ = cursor.Close(ctx)
+	}(cursor, ctx)
+
+	return decode(cursor)
+}
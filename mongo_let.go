@@ -0,0 +1,34 @@
+package builder
+
+import (
+	"maps"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SetLet 设置查询与聚合的 let 变量，过滤条件的 $expr、$lookup 子管道等可通过 "$$name" 引用，
+// 便于将外部参数绑定为变量而非拼入表达式。let 作用于列表、游标与分组统计等 Find / Aggregate 调用（需 MongoDB 5.0+），
+// 总数统计的 CountDocuments 不支持 let，引用变量的过滤条件需配合 SetNeedTotal(false) 使用；传入 nil 时清除
+func (m *MongoBuilder[R]) SetLet(let bson.M) *MongoBuilder[R] {
+	m.let = maps.Clone(let)
+	return m
+}
+
+// findOptions 返回带 let 变量的 Find 选项
+func (m *MongoBuilder[R]) findOptions() *options.FindOptionsBuilder {
+	findOpt := options.Find()
+	if m.let != nil {
+		findOpt.SetLet(m.let)
+	}
+	return findOpt
+}
+
+// aggregateOptions 返回带 let 变量的 Aggregate 选项
+func (m *MongoBuilder[R]) aggregateOptions() *options.AggregateOptionsBuilder {
+	aggregateOpt := options.Aggregate()
+	if m.let != nil {
+		aggregateOpt.SetLet(m.let)
+	}
+	return aggregateOpt
+}
@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	// ErrInvalidMongoSortKey 排序字段路径非法：为空、包含空段、以 $ 开头（$natural 除外）或包含位置操作符（$、$[]、$[id]）
+	ErrInvalidMongoSortKey = errors.New("invalid mongo sort key")
+	// ErrArraySortConflict 排序字段位于以 $elemMatch 过滤的数组内，MongoDB 会按数组全部元素而非匹配元素取排序值
+	ErrArraySortConflict = errors.New("sort key inside an $elemMatch-filtered array")
+)
+
+// SetArraySortAnyElement 允许按以 $elemMatch 过滤的数组子字段排序
+// MongoDB 3.6 起数组字段的排序值不再考虑查询条件：升序取全部元素中的最小值、降序取最大值，
+// 与 $elemMatch 匹配到的元素无关。默认遇到此类排序返回 ErrArraySortConflict，确认按任一元素排序符合预期时再开启
+func (m *MongoBuilder[R]) SetArraySortAnyElement(allow bool) *MongoBuilder[R] {
+	m.arraySortAnyElement = allow
+	return m
+}
+
+// validateQuery 实现 backendValidator，在查询前校验排序字段
+func (m *MongoBuilder[R]) validateQuery() error {
+	return m.validateSort()
+}
+
+// validateSort 校验排序字段路径，并检查排序字段是否位于以 $elemMatch 过滤的数组内
+func (m *MongoBuilder[R]) validateSort() error {
+	sort := m.listSort()
+	if len(sort) == 0 {
+		return nil
+	}
+	var elemMatchFields []string
+	if !m.arraySortAnyElement {
+		elemMatchFields = collectElemMatchFields(m.buildFilter(), nil)
+	}
+	for _, e := range sort {
+		if err := checkMongoSortKey(e.Key); err != nil {
+			return err
+		}
+		for _, field := range elemMatchFields {
+			if strings.HasPrefix(e.Key, field+".") {
+				return fmt.Errorf("%w: sorting by %q uses the min/max of all elements of %q, not the matched one; "+
+					"call SetArraySortAnyElement(true) if that is intended", ErrArraySortConflict, e.Key, field)
+			}
+		}
+	}
+	return nil
+}
+
+// checkMongoSortKey 校验单个排序字段路径
+func checkMongoSortKey(key string) error {
+	if key == "$natural" {
+		return nil
+	}
+	for segment := range strings.SplitSeq(key, ".") {
+		if segment == "" || strings.HasPrefix(segment, "$") {
+			return fmt.Errorf("%w: %q (positional operators are not allowed in sort)", ErrInvalidMongoSortKey, key)
+		}
+	}
+	return nil
+}
+
+// collectElemMatchFields 收集过滤条件中使用 $elemMatch 的字段路径，递归进入 $and / $or / $nor
+// 逻辑运算符的值可以是 bson.A、[]bson.M、[]bson.D 或 []any 等任意切片，按反射逐项展开
+func collectElemMatchFields(filter any, fields []string) []string {
+	each := func(key string, value any) {
+		switch key {
+		case "$and", "$or", "$nor":
+			items := reflect.ValueOf(value)
+			if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+				return
+			}
+			for i := range items.Len() {
+				fields = collectElemMatchFields(items.Index(i).Interface(), fields)
+			}
+		default:
+			if !strings.HasPrefix(key, "$") && hasOperator(value, "$elemMatch") {
+				fields = append(fields, key)
+			}
+		}
+	}
+	switch f := filter.(type) {
+	case bson.D:
+		for _, e := range f {
+			each(e.Key, e.Value)
+		}
+	case bson.M:
+		for key, value := range f {
+			each(key, value)
+		}
+	}
+	return fields
+}
+
+// hasOperator 判断字段条件中是否包含指定操作符
+func hasOperator(value any, operator string) bool {
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == operator {
+				return true
+			}
+		}
+	case bson.M:
+		_, ok := v[operator]
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongoTailable 可追加游标（tailable cursor）配置
+type mongoTailable struct {
+	enabled      bool          // QueryCursor 是否改为在固定集合上打开 TailableAwait 游标
+	maxAwaitTime time.Duration // 每次 getMore 在服务端等待新文档的最长时间，0 表示使用服务端默认值
+}
+
+// SetTailable 设置 QueryCursor 在固定集合（capped collection）上打开 TailableAwait 游标，
+// 读完现有文档后持续等待并产出新插入的文档，直到 ctx 取消或游标失效，适用于变更流式的实时消费场景。
+// 可追加游标按插入顺序返回，不应用 sort 与分页，也不经过中间件与钩子；ResultValidator 与 ResultEnricher 逐条执行，
+// 校验失败时迭代以错误结束；QueryList 与 QueryPage 不受影响
+func (m *MongoBuilder[R]) SetTailable(tailable bool) *MongoBuilder[R] {
+	m.tailable.enabled = tailable
+	return m
+}
+
+// SetMaxAwaitTime 设置可追加游标每次 getMore 在服务端等待新文档的最长时间，仅在 SetTailable 开启时生效
+func (m *MongoBuilder[R]) SetMaxAwaitTime(d time.Duration) *MongoBuilder[R] {
+	m.tailable.maxAwaitTime = d
+	return m
+}
+
+// tailFindOptions 构建可追加游标的 Find 选项：游标类型、等待时间、批次大小与字段投影
+func (m *MongoBuilder[R]) tailFindOptions() (*options.FindOptionsBuilder, error) {
+	findOpt := m.findOptions().SetCursorType(options.TailableAwait)
+	if m.tailable.maxAwaitTime > 0 {
+		findOpt.SetMaxAwaitTime(m.tailable.maxAwaitTime)
+	}
+	if err := m.applyBatchSize(findOpt); err != nil {
+		return nil, err
+	}
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		findOpt.SetProjection(projection)
+	}
+	return findOpt, nil
+}
+
+// queryTailable 打开可追加游标并逐条产出文档；ctx 取消或游标被服务端关闭时正常结束
+// 打开游标的 Find 受 SetTimeout 与 SetPriority 约束，持续等待新文档的 getMore 仅受 ctx 约束
+func (m *MongoBuilder[R]) queryTailable(ctx context.Context) iter.Seq2[*R, error] {
+	return func(yield func(*R, error) bool) {
+		if err := m.builder.prepareAndValidate(); err != nil {
+			yield(nil, err)
+			return
+		}
+		findOpt, err := m.tailFindOptions()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		ctx := m.withSession(ctx)
+		mc := newMiddlewareContext[R](&m.builder)
+		filter := m.buildFilter()
+		cursor, err := limitedQuery(mc, boundedQuery(mc, func(ctx context.Context) (*mongo.Cursor, error) {
+			return m.collection().Find(ctx, filter, findOpt)
+		}))(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer func(cursor *mongo.Cursor) {
+			_ = cursor.Close(context.WithoutCancel(ctx))
+		}(cursor)
+
+		m.tailCursor(ctx, mc, cursor, yield)
+	}
+}
+
+// tailCursor 逐条解码可追加游标的文档，并以单条文档为一批执行 ResultValidator 与 ResultEnricher
+func (m *MongoBuilder[R]) tailCursor(ctx context.Context, mc *middlewareContext[R], cursor *mongo.Cursor, yield func(*R, error) bool) {
+	for i := 0; cursor.Next(ctx); i++ {
+		item := new(R)
+		err := cursor.Decode(item)
+		if err == nil {
+			err = m.unflatten(cursor.Current, item)
+		}
+		if err == nil && mc.validator != nil {
+			if err = mc.validator(ctx, item); err != nil {
+				err = fmt.Errorf("%w: row %d: %w", ErrResultRejected, i, err)
+			}
+		}
+		if err == nil && mc.enricher != nil {
+			err = mc.enricher(ctx, []*R{item})
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !yield(item, nil) {
+			return
+		}
+	}
+	if err := cursor.Err(); err != nil && ctx.Err() == nil {
+		yield(nil, err)
+	}
+}
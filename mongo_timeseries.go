@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// mongoTimeSeries MongoDB 5.0+ 时序集合的查询配置
+type mongoTimeSeries struct {
+	timeField   string        // 时序集合的 timeField
+	metaField   string        // 时序集合的 metaField，为空表示集合未配置 metaField
+	metaFilters []MongoFilter // 作用于 metaField 子字段的过滤条件，构建时补全 metaField 前缀
+	from, to    time.Time     // timeField 的查询区间 [from, to)，零值表示不限
+}
+
+// SetTimeSeries 声明查询的是时序集合并指定其 timeField 与 metaField
+// 未通过 SetSort 设置排序时按 timeField 降序返回（最新的测量值在前），
+// 配合 AddMetaFilter 与 SetTimeRange 可命中时序集合按 metaField 与 timeField 组织的桶，避免解包无关的桶
+func (m *MongoBuilder[R]) SetTimeSeries(timeField, metaField string) *MongoBuilder[R] {
+	m.timeSeries.timeField = timeField
+	m.timeSeries.metaField = metaField
+	return m
+}
+
+// AddMetaFilter 追加作用于 metaField 的过滤条件，键为 metaField 下的子字段（如 sensorId 编译为 metadata.sensorId），
+// 键为空字符串时匹配 metaField 本身；以 $ 开头的操作符键（如 $or）原样保留。未配置 metaField 时键不加前缀
+func (m *MongoBuilder[R]) AddMetaFilter(filter MongoFilter) *MongoBuilder[R] {
+	if len(filter) > 0 {
+		m.timeSeries.metaFilters = append(m.timeSeries.metaFilters, filter)
+	}
+	return m
+}
+
+// SetTimeRange 设置 timeField 的查询区间 [from, to)，零值表示该侧不限；未通过 SetTimeSeries 指定 timeField 时不生效
+// 时序集合按桶记录时间范围，区间条件可直接跳过范围之外的桶
+func (m *MongoBuilder[R]) SetTimeRange(from, to time.Time) *MongoBuilder[R] {
+	m.timeSeries.from = from
+	m.timeSeries.to = to
+	return m
+}
+
+// clone 返回时序配置的深拷贝
+func (ts mongoTimeSeries) clone() mongoTimeSeries {
+	ts.metaFilters = append([]MongoFilter(nil), ts.metaFilters...)
+	return ts
+}
+
+// filters 返回时序配置生成的过滤条件：补全前缀的 metaField 条件与 timeField 区间条件
+func (ts mongoTimeSeries) filters() []MongoFilter {
+	var filters []MongoFilter
+	for _, filter := range ts.metaFilters {
+		filters = append(filters, ts.prefixMeta(filter))
+	}
+	if ts.timeField == "" || (ts.from.IsZero() && ts.to.IsZero()) {
+		return filters
+	}
+	bounds := bson.D{}
+	if !ts.from.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$gte", Value: ts.from})
+	}
+	if !ts.to.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$lt", Value: ts.to})
+	}
+	return append(filters, bson.D{{Key: ts.timeField, Value: bounds}})
+}
+
+// prefixMeta 为过滤条件中的字段键补全 metaField 前缀，$or、$and 与 $nor 中的子条件递归补全
+func (ts mongoTimeSeries) prefixMeta(filter MongoFilter) MongoFilter {
+	if ts.metaField == "" {
+		return filter
+	}
+	prefixed := make(MongoFilter, 0, len(filter))
+	for _, e := range filter {
+		switch {
+		case e.Key == "":
+			e.Key = ts.metaField
+		case e.Key == "$or" || e.Key == "$and" || e.Key == "$nor":
+			e.Value = ts.prefixMetaClauses(e.Value)
+		case !strings.HasPrefix(e.Key, "$"):
+			e.Key = ts.metaField + "." + e.Key
+		}
+		prefixed = append(prefixed, e)
+	}
+	return prefixed
+}
+
+// prefixMetaClauses 为逻辑运算符数组中的每个子条件补全 metaField 前缀，无法识别的值原样返回
+func (ts mongoTimeSeries) prefixMetaClauses(value any) any {
+	var clauses []any
+	switch v := value.(type) {
+	case bson.A:
+		clauses = v
+	case []any:
+		clauses = v
+	case []bson.D:
+		for _, d := range v {
+			clauses = append(clauses, d)
+		}
+	case []bson.M:
+		for _, m := range v {
+			clauses = append(clauses, m)
+		}
+	default:
+		return value
+	}
+	prefixed := make(bson.A, 0, len(clauses))
+	for _, clause := range clauses {
+		switch c := clause.(type) {
+		case bson.D:
+			prefixed = append(prefixed, ts.prefixMeta(c))
+		case bson.M:
+			m := make(bson.M, len(c))
+			for key, v := range c {
+				e := ts.prefixMeta(MongoFilter{{Key: key, Value: v}})[0]
+				m[e.Key] = e.Value
+			}
+			prefixed = append(prefixed, m)
+		default:
+			prefixed = append(prefixed, clause)
+		}
+	}
+	return prefixed
+}
+
+// defaultSort 未设置排序时的默认排序：按 timeField 降序
+func (ts mongoTimeSeries) defaultSort() MongoSort {
+	if ts.timeField == "" {
+		return nil
+	}
+	return MongoSort{{Key: ts.timeField, Value: -1}}
+}
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	// ErrEmptyUpdate FindOneAndUpdate 的更新文档为空
+	ErrEmptyUpdate = errors.New("update document is empty")
+	// ErrUpdateResultChecksUnsupported FindOneAndUpdate 在同一原子操作中查找并更新，无法在更新前执行
+	// ResultValidator 与 ResultEnricher，配置时拒绝执行，避免更新校验本应拒绝的文档
+	ErrUpdateResultChecksUnsupported = errors.New("find one and update does not support result validators or enrichers")
+)
+
+// FindOneAndUpdate 按当前 filter（含追加与强制过滤条件）与 sort 原子地查找一条文档并应用 update，
+// 适用于"领取下一个任务"等查找并标记的队列场景；returnAfter 为 true 时返回更新后的文档，否则返回更新前的文档。
+// 字段投影与 let 变量生效，分页与总数配置被忽略；无匹配文档时返回 ErrNotFound。该方法不经过中间件与钩子，
+// 但受 SetTimeout 与 SetPriority 约束；配置 ResultValidator 或 ResultEnricher 时返回 ErrUpdateResultChecksUnsupported
+func (m *MongoBuilder[R]) FindOneAndUpdate(ctx context.Context, update bson.M, returnAfter bool) (*R, error) {
+	if len(update) == 0 {
+		return nil, ErrEmptyUpdate
+	}
+	if m.builder.validator != nil || m.builder.enricher != nil {
+		return nil, ErrUpdateResultChecksUnsupported
+	}
+	if err := m.builder.prepareAndValidate(); err != nil {
+		return nil, err
+	}
+	updateOpt, err := m.findOneAndUpdateOptions(returnAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	mc := newMiddlewareContext[R](&m.builder)
+	return limitedQuery(mc, boundedQuery(mc, func(ctx context.Context) (*R, error) {
+		ctx = m.withSession(ctx)
+		defer m.builder.observeDBCall(DBCallModify)()
+		item := new(R)
+		result := m.collection().FindOneAndUpdate(ctx, m.buildFilter(), update, updateOpt)
+		err := result.Decode(item)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err := result.Raw()
+		if err != nil {
+			return nil, err
+		}
+		if err := m.unflatten(raw, item); err != nil {
+			return nil, err
+		}
+		return item, nil
+	}))(ctx)
+}
+
+// findOneAndUpdateOptions 构建 FindOneAndUpdate 选项：排序、字段投影、返回文档版本与 let 变量
+func (m *MongoBuilder[R]) findOneAndUpdateOptions(returnAfter bool) (*options.FindOneAndUpdateOptionsBuilder, error) {
+	updateOpt := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	if returnAfter {
+		updateOpt.SetReturnDocument(options.After)
+	}
+	if sort := m.listSort(); len(sort) > 0 {
+		updateOpt.SetSort(sort)
+	}
+	projection, err := m.buildProjection()
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		updateOpt.SetProjection(projection)
+	}
+	if m.let != nil {
+		updateOpt.SetLet(m.let)
+	}
+	return updateOpt, nil
+}
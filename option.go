@@ -1,15 +1,40 @@
 package builder
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 const (
 	defaultStart          = 0    // 默认从第0条开始
 	defaultLimit          = 10   // 默认每页10条
 	defaultNeedTotal      = true // 默认需要总数
 	defaultNeedPagination = true // 默认需要分页
+	defaultNeedData       = true // 默认需要查询数据
 	maxLimit              = 5000 // limit 允许的最大值
+	// maxStart 允许的最大起始位置，更大的值只可能来自无符号运算下溢（2^32 - size，size 不超过 maxLimit）
+	maxStart = math.MaxUint32 - maxLimit
 )
 
+// skipTotalByDefault 进程级默认不统计总数的开关，零值即 defaultNeedTotal 的行为
+var skipTotalByDefault atomic.Bool
+
+// SetDefaultNeedTotal 设置进程级的默认总数统计开关，影响此后所有 LoadQueryOptions 的初始值
+// 多数查询不需要总数的服务可在启动时设置为 false，改由调用方通过 WithNeedTotal(true) 显式开启，避免无意中的昂贵 Count；
+// 单次查询的 WithNeedTotal 始终优先，该设置并发安全，但通常只应在初始化阶段调用一次
+func SetDefaultNeedTotal(needTotal bool) {
+	skipTotalByDefault.Store(!needTotal)
+}
+
 // QueryListOptions 定义了查询列表的通用选项接口
 type QueryListOptions interface {
 	GetData() *DBProxy
@@ -26,18 +51,94 @@ type QueryListOptions interface {
 // BaseQueryListOptions 实现了QueryListOptions接口的基础结构体
 // 包含查询列表所需的所有基本选项
 type BaseQueryListOptions struct {
-	data           *DBProxy      // 数据实例
-	start          uint32        // 分页起始位置
-	limit          uint32        // 每页数据条数
-	needTotal      bool          // 是否需要查询总数
-	totalLimit     uint32        // 总数统计上限，0 表示精确统计
-	needPagination bool          // 是否需要分页
-	fields         []string      // 查询字段投影
-	cursorFields   []string      // 游标分页排序字段
-	cursorValues   []any         // 游标初始值（用于断点续查/App分页场景）
-	esIndex        string        // Elasticsearch 索引名
-	pitID          string        // Elasticsearch PIT ID（跨请求分页）
-	pitKeepAlive   time.Duration // Elasticsearch Point-in-Time 保持时间
+	data               *DBProxy                 // 数据实例
+	start              uint32                   // 分页起始位置
+	limit              uint32                   // 每页数据条数
+	needTotal          bool                     // 是否需要查询总数
+	totalLimit         uint32                   // 总数统计上限，0 表示精确统计
+	needPagination     bool                     // 是否需要分页
+	fields             []string                 // 查询字段投影
+	needData           bool                     // 是否需要查询数据
+	resultCapacity     int                      // 结果切片预分配容量提示
+	queryName          string                   // 逻辑查询名称
+	ignoreNotFound     bool                     // QueryOne 未查到记录时返回 (nil, nil)
+	returnAfterUpdate  bool                     // QueryOneAndUpdate 返回更新后的文档
+	errOnEmpty         error                    // Query 结果为空时返回的错误
+	cursorFields       []string                 // 游标分页排序字段
+	cursorValues       []any                    // 游标初始值（用于断点续查/App分页场景）
+	cursorSigningKey   []byte                   // 游标 token 签名密钥
+	cursorToken        string                   // 签名游标 token
+	pageToken          []byte                   // 键值分页后端的不透明分页 token
+	timingSink         *Timings                 // 查询耗时累加器
+	dbCallHook         DBCallHook               // 数据库调用钩子
+	resultValidator    any                      // 结果行校验函数（ResultValidator[R]）
+	resultEnricher     any                      // 结果集批量处理函数（ResultEnricher[R]）
+	dedupKey           any                      // 结果去重键函数（DedupKey[R]）
+	counter            any                      // 可替换的总数统计实现（Counter[R]）
+	mergeLess          any                      // 分片结果合并后的排序函数（func(a, b *R) bool，a 排在 b 之前时返回 true）
+	limitCap           LimitCap                 // 每页条数上限
+	conditions         []Condition              // 调用处逐个传入的结构化过滤条件
+	countCtx           context.Context          // 总数统计专用 ctx
+	countData          *DBProxy                 // 总数统计使用的数据实例
+	reusePointers      bool                     // 流式查询是否复用结果指针
+	peekNext           bool                     // 列表查询多取一条探测下一页
+	consistentRead     bool                     // 数据查询与总数统计读取同一快照
+	timeout            time.Duration            // 单次数据源访问的超时时间
+	countTimeout       time.Duration            // 总数统计分支的超时时间
+	stableSortKey      string                   // 偏移分页追加的稳定排序字段
+	parallelism        int                      // 单次查询内并发访问数据源的最大数量
+	limiter            *PriorityLimiter         // 按优先级调度的共享并发限制器
+	priority           QueryPriority            // 获取执行名额时使用的优先级
+	requireFilter      bool                     // 查询必须带有过滤条件
+	totalOverride      *int64                   // 调用方已知的总数，跳过总数统计
+	gormFilters        []GormScope              // GORM 追加过滤条件
+	namedScopes        []string                 // 引用 List 中已注册的 GORM 作用域名称
+	defaultFilter      MandatoryFilter          // 未设置过滤条件时使用的默认过滤条件
+	sortMapping        SortMapping              // 排序字段白名单与映射
+	sortFields         []SortField              // 请求指定的排序字段
+	sortSnakeCase      bool                     // 排序字段映射值为空时自动转换为 snake_case
+	sortAliases        []string                 // 允许排序的计算列或聚合结果别名
+	gormJoins          []gormJoin               // GORM 关联查询
+	gormClauses        []clause.Expression      // GORM 透传子句
+	gormCountModifiers []GormScope              // GORM 总数统计专属修饰
+	countPartitions    []string                 // GORM 按分区并行统计总数的分区表
+	deadlockRetries    int                      // GORM 遇到数据库死锁时的最大重试次数
+	softDeleteColumn   string                   // GORM 非标准软删除列名
+	softDeleteValue    any                      // GORM 软删除列的"已删除"值
+	fromSubquery       *gorm.DB                 // GORM 作为数据源的子查询
+	fromAlias          string                   // GORM 子查询或表值函数别名
+	fromFunction       string                   // GORM 作为数据源的表值函数表达式
+	fromFunctionArgs   []any                    // GORM 表值函数表达式的参数
+	rawQuery           string                   // GORM 作为数据源的原生 SQL
+	rawQueryArgs       []any                    // GORM 原生 SQL 的参数
+	fullTextColumn     string                   // GORM PostgreSQL 全文检索的 tsvector 列
+	fullTextQuery      string                   // GORM PostgreSQL 全文检索的检索词
+	fullTextRank       bool                     // GORM 是否按全文检索相关度排序
+	rawTable           string                   // GORM 直接查询并扫描的表名
+	connTag            string                   // GORM 选用的标签连接
+	mongoBatchSize     *int32                   // MongoDB 游标批次大小
+	bsonRegistry       *bson.Registry           // MongoDB 自定义 BSON 注册表
+	readConcern        *readconcern.ReadConcern // MongoDB 读关注
+	mongoSession       *mongo.Session           // MongoDB 查询所属会话
+	arraySlices        []mongoArraySlice        // MongoDB 数组字段 $slice 投影
+	opTimeSink         *bson.Timestamp          // MongoDB 列表查询 operationTime 的写入位置
+	decodeErrSink      *MongoDecodeErrors       // MongoDB 逐条解码时解码失败文档的收集位置
+	tailable           bool                     // MongoDB 游标查询是否使用可追加游标
+	mongoLet           bson.M                   // MongoDB Find / Aggregate 的 let 变量
+	arraySortAnyElem   bool                     // MongoDB 允许按以 $elemMatch 过滤的数组子字段排序
+	flattenProjection  bool                     // MongoDB 按结果结构体的 bson 标签生成投影
+	maxAwaitTime       time.Duration            // MongoDB 可追加游标每次 getMore 的最长等待时间
+	excludeFields      []string                 // MongoDB 排除投影字段
+	mongoRawFilter     *string                  // MongoDB JSON 过滤条件，替换 filter
+	mongoRawOperators  []string                 // MongoDB JSON 过滤条件允许的操作符
+	tsTimeField        string                   // MongoDB 时序集合的 timeField
+	tsMetaField        string                   // MongoDB 时序集合的 metaField
+	tsFrom, tsTo       time.Time                // MongoDB 时序集合 timeField 的查询区间
+	geoNear            *mongoGeoNear            // MongoDB $geoNear 距离排序配置
+	esIndex            string                   // Elasticsearch 索引名
+	pitID              string                   // Elasticsearch PIT ID（跨请求分页）
+	pitKeepAlive       time.Duration            // Elasticsearch Point-in-Time 保持时间
+	err                error                    // 选项校验错误，由 List 查询方法在执行前返回
 }
 
 func (opts *BaseQueryListOptions) GetData() *DBProxy {
@@ -74,6 +175,17 @@ func (opts *BaseQueryListOptions) GetCursorValues() []any {
 	return opts.cursorValues
 }
 
+// HasResultChecks 返回是否配置了 WithResultValidator、WithAfterAll 或 WithDedupBy，
+// 供不逐行解码结果的扩展（如 Arrow 列式读取）拒绝无法执行的结果处理
+func (opts *BaseQueryListOptions) HasResultChecks() bool {
+	return opts.resultValidator != nil || opts.resultEnricher != nil || opts.dedupKey != nil
+}
+
+// Err 返回选项校验错误（如 ErrStartOverflow），出错的起始位置已被归零
+func (opts *BaseQueryListOptions) Err() error {
+	return opts.err
+}
+
 // QueryOption 定义用于配置查询选项的函数类型
 type QueryOption func(options *BaseQueryListOptions)
 
@@ -90,8 +202,9 @@ func LoadQueryOptions(opts ...QueryOption) BaseQueryListOptions {
 	options := BaseQueryListOptions{
 		start:          defaultStart,
 		limit:          defaultLimit,
-		needTotal:      defaultNeedTotal,
+		needTotal:      defaultNeedTotal && !skipTotalByDefault.Load(),
 		needPagination: defaultNeedPagination,
+		needData:       defaultNeedData,
 	}
 
 	// 应用所有选项函数
@@ -99,6 +212,12 @@ func LoadQueryOptions(opts ...QueryOption) BaseQueryListOptions {
 		opt(&options)
 	}
 
+	// 起始位置疑似由无符号运算下溢回绕得到，归零并记录错误，避免以巨大偏移量扫描
+	if options.start > maxStart {
+		options.err = fmt.Errorf("%w: start %d", ErrStartOverflow, options.start)
+		options.start = 0
+	}
+
 	return options
 }
 
@@ -114,12 +233,34 @@ func WithStart(start uint32) QueryOption {
 	}
 }
 
+func WithPage(page, size uint32) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.limit = size
+		o.start = 0
+		if page <= 1 {
+			return
+		}
+		start := uint64(page-1) * uint64(size)
+		if start > maxStart {
+			o.err = fmt.Errorf("%w: page %d, size %d", ErrStartOverflow, page, size)
+			return
+		}
+		o.start = uint32(start)
+	}
+}
+
 func WithLimit(limit uint32) QueryOption {
 	return func(o *BaseQueryListOptions) {
 		o.limit = limit
 	}
 }
 
+func WithLimitCap(limitCap LimitCap) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.limitCap = limitCap
+	}
+}
+
 func WithNeedTotal(needTotal bool) QueryOption {
 	return func(o *BaseQueryListOptions) {
 		o.needTotal = needTotal
@@ -138,6 +279,106 @@ func WithNeedPagination(needPagination bool) QueryOption {
 	}
 }
 
+func WithNeedData(needData bool) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.needData = needData
+	}
+}
+
+func WithResultCapacity(capacity int) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.resultCapacity = capacity
+	}
+}
+
+func WithQueryName(name string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.queryName = name
+	}
+}
+
+func WithIgnoreNotFound() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.ignoreNotFound = true
+	}
+}
+
+func WithErrorOnEmpty(err error) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.errOnEmpty = err
+	}
+}
+
+func WithReturnAfterUpdate() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.returnAfterUpdate = true
+	}
+}
+
+func WithCountContext(ctx context.Context) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.countCtx = ctx
+	}
+}
+
+func WithCountProxy(data *DBProxy) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.countData = data
+	}
+}
+
+func WithResultPointerReuse() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.reusePointers = true
+	}
+}
+
+func WithPeekNext() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.peekNext = true
+	}
+}
+
+func WithTimeout(timeout time.Duration) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.timeout = timeout
+	}
+}
+
+func WithBranchTimeouts(find, count time.Duration) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		if find > 0 {
+			o.timeout = find
+		}
+		o.countTimeout = count
+	}
+}
+
+func WithQueryPriority(limiter *PriorityLimiter, priority QueryPriority) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.limiter = limiter
+		o.priority = priority
+	}
+}
+
+func WithRequireFilter() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.requireFilter = true
+	}
+}
+
+func WithTotalOverride(total int64) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.totalOverride = &total
+	}
+}
+
+func WithStableSort(key string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.stableSortKey = key
+	}
+}
+
 func WithFields(fields ...string) QueryOption {
 	return func(o *BaseQueryListOptions) {
 		o.fields = fields
@@ -156,6 +397,317 @@ func WithCursorValue(values ...any) QueryOption {
 	}
 }
 
+func WithCursorSigningKey(key []byte) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.cursorSigningKey = key
+	}
+}
+
+func WithCursorToken(token string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.cursorToken = token
+	}
+}
+
+func WithPageToken(token []byte) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.pageToken = token
+	}
+}
+
+func WithJSONFilter(column, path string, value any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.gormFilters = append(o.gormFilters, GormJSONFilter(column, path, value))
+	}
+}
+
+func WithNamedScope(names ...string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.namedScopes = append(o.namedScopes, names...)
+	}
+}
+
+func WithTimingSink(sink *Timings) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.timingSink = sink
+	}
+}
+
+func WithDBCallHook(hook DBCallHook) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.dbCallHook = hook
+	}
+}
+
+func WithCondition(field string, op FilterOp, value any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.conditions = append(o.conditions, Condition{Field: field, Op: op, Value: value})
+	}
+}
+
+func WithResultValidator[R any](validator func(ctx context.Context, item *R) error) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.resultValidator = ResultValidator[R](validator)
+	}
+}
+
+func WithAfterAll[R any](enricher func(ctx context.Context, items []*R) error) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.resultEnricher = ResultEnricher[R](enricher)
+	}
+}
+
+func WithDedupBy[R any](keyFn func(item *R) any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.dedupKey = DedupKey[R](keyFn)
+	}
+}
+
+func WithCounter[R any](counter Counter[R]) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.counter = counter
+	}
+}
+
+func WithMergeSort[R any](less func(a, b *R) bool) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.mergeLess = less
+	}
+}
+
+func WithOptional[V any](field string, op FilterOp, value *V) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		if value != nil {
+			o.conditions = append(o.conditions, Condition{Field: field, Op: op, Value: *value})
+		}
+	}
+}
+
+func WithOptionalEq[V any](field string, value *V) QueryOption {
+	return WithOptional(field, OpEq, value)
+}
+
+func WithJoin(query string, args ...any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.gormJoins = append(o.gormJoins, gormJoin{query: query, args: args})
+	}
+}
+
+func WithClauses(clauses ...clause.Expression) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.gormClauses = append(o.gormClauses, clauses...)
+	}
+}
+
+func WithCountModifier(modifier GormScope) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.gormCountModifiers = append(o.gormCountModifiers, modifier)
+	}
+}
+
+func WithPartitionedCount(partitions []string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.countPartitions = partitions
+	}
+}
+
+func WithDeadlockRetry(maxRetries int) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.deadlockRetries = maxRetries
+	}
+}
+
+func WithDefaultFilter(filter MandatoryFilter) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.defaultFilter = filter
+	}
+}
+
+func WithSortFields(mapping SortMapping, fields ...SortField) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.sortMapping = mapping
+		o.sortFields = fields
+	}
+}
+
+func WithSortAliases(aliases ...string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.sortAliases = append(o.sortAliases, aliases...)
+	}
+}
+
+func WithAutoSnakeCase() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.sortSnakeCase = true
+	}
+}
+
+func WithSoftDelete(column string, deletedValue any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.softDeleteColumn = column
+		o.softDeleteValue = deletedValue
+	}
+}
+
+func WithFromSubquery(sub *gorm.DB, alias string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.fromSubquery = sub
+		o.fromAlias = alias
+	}
+}
+
+func WithFromFunction(expr, alias string, args ...any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.fromFunction = expr
+		o.fromFunctionArgs = args
+		o.fromAlias = alias
+	}
+}
+
+func WithRawQuery(sql string, args ...any) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.rawQuery = sql
+		o.rawQueryArgs = args
+	}
+}
+
+func WithFullText(tsvectorColumn, query string, rank bool) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.fullTextColumn = tsvectorColumn
+		o.fullTextQuery = query
+		o.fullTextRank = rank
+	}
+}
+
+func WithConnTag(tag string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.connTag = tag
+	}
+}
+
+func WithRawScan(table string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.rawTable = table
+	}
+}
+
+func WithArraySlice(field string, n int) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.arraySlices = append(o.arraySlices, mongoArraySlice{field: field, n: n})
+	}
+}
+
+func WithParallelism(n int) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.parallelism = n
+	}
+}
+
+func WithConsistentRead() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.consistentRead = true
+	}
+}
+
+func WithProjectExclude(fields ...string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.excludeFields = fields
+	}
+}
+
+func WithTimeSeries(timeField, metaField string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.tsTimeField = timeField
+		o.tsMetaField = metaField
+	}
+}
+
+func WithTimeRange(from, to time.Time) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.tsFrom = from
+		o.tsTo = to
+	}
+}
+
+func WithOperationTimeSink(sink *bson.Timestamp) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.opTimeSink = sink
+	}
+}
+
+func WithSkipDecodeErrors(sink *MongoDecodeErrors) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.decodeErrSink = sink
+	}
+}
+
+func WithTailable() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.tailable = true
+	}
+}
+
+func WithMaxAwaitTime(d time.Duration) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.maxAwaitTime = d
+	}
+}
+
+func WithBatchSize(batchSize int32) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.mongoBatchSize = &batchSize
+	}
+}
+
+func WithBSONRegistry(registry *bson.Registry) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.bsonRegistry = registry
+	}
+}
+
+func WithReadConcern(readConcern *readconcern.ReadConcern) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.readConcern = readConcern
+	}
+}
+
+func WithMongoRawFilter(jsonStr string, allowedOperators ...string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.mongoRawFilter = &jsonStr
+		o.mongoRawOperators = allowedOperators
+	}
+}
+
+func WithMongoLet(let bson.M) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.mongoLet = let
+	}
+}
+
+func WithArraySortAnyElement() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.arraySortAnyElem = true
+	}
+}
+
+func WithFlattenProjection() QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.flattenProjection = true
+	}
+}
+
+func WithGeoNear(field string, lng, lat, maxMeters float64, distanceField string) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.geoNear = &mongoGeoNear{field: field, lng: lng, lat: lat, maxMeters: maxMeters, distanceField: distanceField}
+	}
+}
+
+func WithMongoSession(session *mongo.Session) QueryOption {
+	return func(o *BaseQueryListOptions) {
+		o.mongoSession = session
+	}
+}
+
 func WithESIndex(index string) QueryOption {
 	return func(o *BaseQueryListOptions) {
 		o.esIndex = index
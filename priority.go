@@ -0,0 +1,154 @@
+package builder
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// QueryPriority 查询优先级，数值越大越先获得 PriorityLimiter 的执行名额
+type QueryPriority int
+
+// 预定义的查询优先级，也可使用任意整数表示更细的等级
+const (
+	PriorityLow    QueryPriority = -1 // 后台报表、导出等可延后的查询
+	PriorityNormal QueryPriority = 0  // 默认优先级
+	PriorityHigh   QueryPriority = 1  // 面向用户的交互式查询
+)
+
+// PriorityLimiter 按优先级调度的并发限制器，可在多个构建器之间共享以约束同一连接池上的并发查询数
+// 名额已满时请求按优先级排队，释放的名额总是交给等待中优先级最高的请求（同优先级先到先得），
+// 使交互式查询越过排队中的后台查询；已在执行的查询不会被中断
+type PriorityLimiter struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	seq      uint64
+	waiters  priorityWaiters
+}
+
+// NewPriorityLimiter 创建最多允许 capacity 个查询同时执行的限制器，capacity <= 0 时按 1 处理
+func NewPriorityLimiter(capacity int) *PriorityLimiter {
+	return &PriorityLimiter{capacity: max(capacity, 1)}
+}
+
+// Acquire 按 priority 获取一个执行名额，成功时返回释放函数（可重复调用）；
+// 排队期间 ctx 结束时放弃排队并返回 ctx.Err()
+func (l *PriorityLimiter) Acquire(ctx context.Context, priority QueryPriority) (release func(), err error) {
+	l.mu.Lock()
+	if l.inUse < l.capacity && l.waiters.Len() == 0 {
+		l.inUse++
+		l.mu.Unlock()
+		return l.releaseFunc(), nil
+	}
+	l.seq++
+	w := &priorityWaiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaseFunc(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&l.waiters, w.index)
+		}
+		l.mu.Unlock()
+		// 取消与获得名额同时发生时，将名额转交给下一个等待者
+		if granted {
+			l.release()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// InUse 返回当前正在执行的查询数
+func (l *PriorityLimiter) InUse() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inUse
+}
+
+// Waiting 返回当前排队等待的查询数
+func (l *PriorityLimiter) Waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiters.Len()
+}
+
+// releaseFunc 返回只生效一次的释放函数
+func (l *PriorityLimiter) releaseFunc() func() {
+	var once sync.Once
+	return func() { once.Do(l.release) }
+}
+
+// release 释放一个名额：有等待者时直接转交给优先级最高的等待者，否则归还名额
+func (l *PriorityLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiters.Len() > 0 {
+		close(heap.Pop(&l.waiters).(*priorityWaiter).ready)
+		return
+	}
+	l.inUse--
+}
+
+// priorityWaiter 排队中的名额请求
+type priorityWaiter struct {
+	priority QueryPriority
+	seq      uint64        // 入队序号，同优先级按先后顺序获得名额
+	ready    chan struct{} // 获得名额时关闭
+	index    int           // 在堆中的位置，出堆后为 -1
+}
+
+// priorityWaiters 按优先级降序、入队序号升序排列的等待队列，实现 heap.Interface
+type priorityWaiters []*priorityWaiter
+
+func (q priorityWaiters) Len() int { return len(q) }
+
+func (q priorityWaiters) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityWaiters) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *priorityWaiters) Push(x any) {
+	w := x.(*priorityWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *priorityWaiters) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// limitedQuery 包装最终查询函数，在配置了 PriorityLimiter 时按查询优先级获取执行名额，查询结束后释放
+// 排队等待只受请求 ctx 约束，不计入 SetTimeout 的超时与 Timings 记录的耗时
+func limitedQuery[R any, T any](mc *middlewareContext[R], queryFn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	if mc.limiter == nil {
+		return queryFn
+	}
+	return func(ctx context.Context) (T, error) {
+		release, err := mc.limiter.Acquire(ctx, mc.priority)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		defer release()
+		return queryFn(ctx)
+	}
+}
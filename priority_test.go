@@ -0,0 +1,124 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForWaiters 等待限制器的排队数达到 n
+func waitForWaiters(t *testing.T, limiter *PriorityLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for limiter.Waiting() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d waiters, got %d", n, limiter.Waiting())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestPriorityLimiter_Order 测试释放的名额按优先级降序、同优先级按先后顺序分配
+func TestPriorityLimiter_Order(t *testing.T) {
+	limiter := NewPriorityLimiter(1)
+	release, err := limiter.Acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	enqueue := func(name string, priority QueryPriority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background(), priority)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			release()
+		}()
+	}
+	enqueue("report", PriorityLow)
+	waitForWaiters(t, limiter, 1)
+	enqueue("list-1", PriorityHigh)
+	waitForWaiters(t, limiter, 2)
+	enqueue("list-2", PriorityHigh)
+	waitForWaiters(t, limiter, 3)
+
+	release()
+	release() // 重复释放不生效
+	wg.Wait()
+
+	if expected := []string{"list-1", "list-2", "report"}; !slices.Equal(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+	if limiter.InUse() != 0 || limiter.Waiting() != 0 {
+		t.Errorf("expected limiter drained, got inUse=%d waiting=%d", limiter.InUse(), limiter.Waiting())
+	}
+}
+
+// TestPriorityLimiter_Cancel 测试排队期间 ctx 结束时放弃排队
+func TestPriorityLimiter_Cancel(t *testing.T) {
+	limiter := NewPriorityLimiter(0)
+	release, err := limiter.Acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, PriorityHigh); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if limiter.Waiting() != 0 {
+		t.Errorf("expected canceled waiter removed, got %d", limiter.Waiting())
+	}
+
+	release()
+	if limiter.InUse() != 0 {
+		t.Errorf("expected slot returned, got %d in use", limiter.InUse())
+	}
+}
+
+// TestListQuery_Priority 测试 WithQueryPriority 使查询在访问数据源前获取名额
+func TestListQuery_Priority(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	limiter := NewPriorityLimiter(1)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	release, err := limiter.Acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := list.Query(ctx, WithData(proxy), WithQueryPriority(limiter, PriorityHigh)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected query to wait for a slot, got %v", err)
+	}
+	if len(recorder.all()) != 0 {
+		t.Errorf("expected no statements while waiting, got %v", recorder.all())
+	}
+
+	release()
+	if _, err := list.Query(context.Background(), WithData(proxy), WithQueryPriority(limiter, PriorityHigh)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.all()) == 0 {
+		t.Error("expected query to run after the slot was released")
+	}
+	if limiter.InUse() != 0 {
+		t.Errorf("expected slot released after query, got %d in use", limiter.InUse())
+	}
+}
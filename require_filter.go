@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"errors"
+
+	"github.com/olivere/elastic/v7"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMissingFilter 开启 SetRequireFilter 后查询未设置任何过滤条件，拒绝执行以避免全表（全集合、全索引）扫描
+var ErrMissingFilter = errors.New("query has no filter condition")
+
+// filterInspector 专属构建器判断当前过滤条件是否会限定查询范围，供 SetRequireFilter 校验
+type filterInspector interface {
+	hasFilterCondition() bool
+}
+
+// hasFilterCondition 在空白语句上应用 filter 与追加的过滤条件，检查是否产生了 WHERE 条件
+// 软删除条件不计入；过滤作用域内部再调用 db.Scopes 注册的嵌套作用域不会被展开
+func (g *GormBuilder[R]) hasFilterCondition() bool {
+	if !g.hasFilter() {
+		return false
+	}
+	tx := g.builder.data.DB.Session(&gorm.Session{NewDB: true, DryRun: true})
+	if g.filter != nil {
+		tx = g.filter(tx)
+	}
+	for _, filter := range g.extraFilters {
+		tx = filter(tx)
+	}
+	c, ok := tx.Statement.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	return ok && len(where.Exprs) > 0
+}
+
+// hasFilterCondition 检查合并后的过滤文档是否为空
+func (m *MongoBuilder[R]) hasFilterCondition() bool {
+	return len(m.buildFilter()) > 0
+}
+
+// hasFilterCondition 检查是否设置了过滤条件，match_all 查询视为未设置
+func (e *ElasticSearchBuilder[R]) hasFilterCondition() bool {
+	if len(e.extraFilters) > 0 {
+		return true
+	}
+	if e.filter == nil {
+		return false
+	}
+	_, matchAll := e.filter.(*elastic.MatchAllQuery)
+	return !matchAll
+}
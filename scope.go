@@ -18,15 +18,18 @@ type ScopeConfigurer[R any] func(querier Querier[R])
 // 参数:
 //
 //	filter - GORM 过滤条件（GormScope 类型），可为 nil
-//	sort   - GORM 排序条件（GormScope 类型），可为 nil
-func NewGormScope[R any](filter func(*gorm.DB) *gorm.DB, sort func(*gorm.DB) *gorm.DB) ScopeConfigurer[R] {
+//	sort   - GORM 排序条件（GormScope 类型），可传入多个按顺序应用，nil 会被忽略
+func NewGormScope[R any](filter func(*gorm.DB) *gorm.DB, sort ...func(*gorm.DB) *gorm.DB) ScopeConfigurer[R] {
 	return func(querier Querier[R]) {
 		if gb, ok := querier.(*GormBuilder[R]); ok {
 			if filter != nil {
 				gb.SetFilter(filter)
 			}
-			if sort != nil {
-				gb.SetSort(sort)
+			for _, s := range sort {
+				if s != nil {
+					gb.SetSort(sort...)
+					break
+				}
 			}
 		}
 	}
@@ -69,3 +72,24 @@ func NewElasticSearchScope[R any](filter elastic.Query, sort ...elastic.Sorter)
 		}
 	}
 }
+
+// NewStructFilterScope 创建一个按数据源自动应用结构化过滤条件的 ScopeConfigurer
+// 用于 List.SetScope，条件通过 AddFilter 追加，不会覆盖构建器已有的 filter
+// 参数:
+//
+//	filter - FilterFromStruct 生成的结构化过滤条件，可为 nil
+func NewStructFilterScope[R any](filter *StructFilter) ScopeConfigurer[R] {
+	return func(querier Querier[R]) {
+		if filter == nil {
+			return
+		}
+		switch q := querier.(type) {
+		case *GormBuilder[R]:
+			q.AddFilter(filter.Gorm())
+		case *MongoBuilder[R]:
+			q.AddFilter(filter.Mongo())
+		case *ElasticSearchBuilder[R]:
+			q.AddFilter(filter.ElasticSearch())
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fantasticbin/QueryBuilder/v2/util"
+	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidSortField 排序字段不在白名单内，可通过 errors.Is 判断，
+// 通过 errors.As 获取 *InvalidSortError 以拿到非法字段与允许的字段列表
+var ErrInvalidSortField = errors.New("invalid sort field")
+
+// InvalidSortError 排序字段校验失败的结构化错误，便于 API 层返回包含可选字段的 400 响应
+type InvalidSortError struct {
+	Field   string   // 请求中的非法排序字段
+	Allowed []string // 允许的排序字段（已排序）
+}
+
+// Error 实现 error 接口
+func (e *InvalidSortError) Error() string {
+	return fmt.Sprintf("%s: %q, allowed: [%s]", ErrInvalidSortField, e.Field, strings.Join(e.Allowed, ", "))
+}
+
+// Is 支持 errors.Is(err, ErrInvalidSortField)
+func (e *InvalidSortError) Is(target error) bool {
+	return target == ErrInvalidSortField
+}
+
+// SortField 请求中的单个排序字段
+type SortField struct {
+	Field string // API 层字段名
+	Desc  bool   // 是否降序
+}
+
+// ParseSortFields 解析逗号分隔的排序表达式，字段前缀 "-" 表示降序、"+" 或无前缀表示升序
+// 例如 "-created_at,name" 解析为 created_at 降序、name 升序；空白与空字段会被忽略
+func ParseSortFields(expr string) []SortField {
+	var fields []SortField
+	for part := range strings.SplitSeq(expr, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		part = strings.TrimLeft(part, "+-")
+		if part == "" {
+			continue
+		}
+		fields = append(fields, SortField{Field: part, Desc: desc})
+	}
+	return fields
+}
+
+// SortMapping 排序字段白名单与名称映射：键为 API 层允许的字段名，值为数据源中的字段名
+// 值为空字符串时表示与键同名
+type SortMapping map[string]string
+
+// SnakeCase 返回新的映射，值为空的字段改为映射到键的 snake_case 形式（如 createdAt → created_at）
+// 适用于 API 层使用 camelCase、数据源使用 snake_case 的常见约定，无需逐一维护映射值
+func (m SortMapping) SnakeCase() SortMapping {
+	mapped := make(SortMapping, len(m))
+	for field, column := range m {
+		if column == "" {
+			column = util.ToSnakeCase(field)
+		}
+		mapped[field] = column
+	}
+	return mapped
+}
+
+// Resolve 校验并映射排序字段，遇到不在白名单内的字段时返回 *InvalidSortError
+func (m SortMapping) Resolve(fields ...SortField) ([]SortField, error) {
+	resolved := make([]SortField, 0, len(fields))
+	for _, f := range fields {
+		column, ok := m[f.Field]
+		if !ok {
+			return nil, &InvalidSortError{Field: f.Field, Allowed: m.allowed()}
+		}
+		if column == "" {
+			column = f.Field
+		}
+		resolved = append(resolved, SortField{Field: column, Desc: f.Desc})
+	}
+	return resolved, nil
+}
+
+// sqlIdentifierPattern 可直接拼入 SQL 的标识符（排序别名、数据源别名）：仅由字母、数字与下划线组成且不以数字开头
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithAliases 返回新的映射，追加计算列或聚合结果的别名（如 SELECT COUNT(*) AS cnt 中的 cnt），
+// 别名映射到自身并在 ORDER BY 中按原名引用，不受 SnakeCase 转换影响；已存在的键保持不变，
+// 不是合法标识符的别名会被忽略，避免将表达式拼入排序
+func (m SortMapping) WithAliases(aliases ...string) SortMapping {
+	mapped := make(SortMapping, len(m)+len(aliases))
+	for field, column := range m {
+		mapped[field] = column
+	}
+	for _, alias := range aliases {
+		if _, ok := mapped[alias]; !ok && sqlIdentifierPattern.MatchString(alias) {
+			mapped[alias] = alias
+		}
+	}
+	return mapped
+}
+
+// selectAliases 提取字段投影中以 "expr AS alias" 形式声明的别名（AS 不区分大小写）
+func selectAliases(fields []string) []string {
+	var aliases []string
+	for _, field := range fields {
+		parts := strings.Fields(field)
+		if n := len(parts); n >= 3 && strings.EqualFold(parts[n-2], "AS") {
+			aliases = append(aliases, strings.Trim(parts[n-1], "`\""))
+		}
+	}
+	return aliases
+}
+
+// allowed 返回排序后的白名单字段列表
+func (m SortMapping) allowed() []string {
+	allowed := make([]string, 0, len(m))
+	for field := range m {
+		allowed = append(allowed, field)
+	}
+	slices.Sort(allowed)
+	return allowed
+}
+
+// Gorm 校验并生成 GORM 排序作用域，列名会被转义
+func (m SortMapping) Gorm(fields ...SortField) (GormScope, error) {
+	resolved, err := m.Resolve(fields...)
+	if err != nil {
+		return nil, err
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		for _, f := range resolved {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Field}, Desc: f.Desc})
+		}
+		return db
+	}, nil
+}
+
+// Mongo 校验并生成 MongoDB 排序条件
+func (m SortMapping) Mongo(fields ...SortField) (MongoSort, error) {
+	resolved, err := m.Resolve(fields...)
+	if err != nil {
+		return nil, err
+	}
+	sort := make(MongoSort, 0, len(resolved))
+	for _, f := range resolved {
+		direction := 1
+		if f.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: f.Field, Value: direction})
+	}
+	return sort, nil
+}
+
+// ElasticSearch 校验并生成 ElasticSearch 排序条件
+func (m SortMapping) ElasticSearch(fields ...SortField) ([]elastic.Sorter, error) {
+	resolved, err := m.Resolve(fields...)
+	if err != nil {
+		return nil, err
+	}
+	sorters := make([]elastic.Sorter, 0, len(resolved))
+	for _, f := range resolved {
+		sorters = append(sorters, elastic.NewFieldSort(f.Field).Order(!f.Desc))
+	}
+	return sorters, nil
+}
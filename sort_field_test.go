@@ -0,0 +1,229 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"gorm.io/gorm"
+)
+
+// TestParseSortFields 测试排序表达式解析
+func TestParseSortFields(t *testing.T) {
+	got := ParseSortFields(" -created_at, name ,,+age,-")
+	expected := []SortField{
+		{Field: "created_at", Desc: true},
+		{Field: "name"},
+		{Field: "age"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if fields := ParseSortFields(""); len(fields) != 0 {
+		t.Errorf("expected no fields for empty expression, got %v", fields)
+	}
+}
+
+// TestSortMapping_Resolve 测试白名单校验与字段映射
+func TestSortMapping_Resolve(t *testing.T) {
+	mapping := SortMapping{"createdAt": "created_at", "name": ""}
+
+	resolved, err := mapping.Resolve(ParseSortFields("-createdAt,name")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []SortField{{Field: "created_at", Desc: true}, {Field: "name"}}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("expected %v, got %v", expected, resolved)
+	}
+
+	_, err = mapping.Resolve(SortField{Field: "password"})
+	if !errors.Is(err, ErrInvalidSortField) {
+		t.Fatalf("expected ErrInvalidSortField, got %v", err)
+	}
+	var sortErr *InvalidSortError
+	if !errors.As(err, &sortErr) {
+		t.Fatalf("expected *InvalidSortError, got %T", err)
+	}
+	if sortErr.Field != "password" || !reflect.DeepEqual(sortErr.Allowed, []string{"createdAt", "name"}) {
+		t.Errorf("unexpected error detail: %+v", sortErr)
+	}
+}
+
+// TestSortMapping_Backends 测试各数据源排序条件的生成
+func TestSortMapping_Backends(t *testing.T) {
+	mapping := SortMapping{"createdAt": "created_at", "name": ""}
+	fields := ParseSortFields("-createdAt,name")
+
+	sql, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		scope, err := mapping.Gorm(fields...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b.SetSort(scope)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "ORDER BY `created_at` DESC,`name`") {
+		t.Errorf("expected mapped ORDER BY, got %s", sql)
+	}
+
+	sort, err := mapping.Mongo(fields...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MongoSort{{Key: "created_at", Value: -1}, {Key: "name", Value: 1}}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("expected %v, got %v", expected, sort)
+	}
+
+	sorters, err := mapping.ElasticSearch(fields...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sorters) != 2 {
+		t.Fatalf("expected 2 sorters, got %d", len(sorters))
+	}
+	source, _ := sorters[0].Source()
+	if !reflect.DeepEqual(source, map[string]any{"created_at": map[string]any{"order": "desc"}}) {
+		t.Errorf("unexpected sorter source: %v", source)
+	}
+
+	for _, fn := range []func() error{
+		func() error { _, err := mapping.Gorm(SortField{Field: "secret"}); return err },
+		func() error { _, err := mapping.Mongo(SortField{Field: "secret"}); return err },
+		func() error { _, err := mapping.ElasticSearch(SortField{Field: "secret"}); return err },
+	} {
+		if err := fn(); !errors.Is(err, ErrInvalidSortField) {
+			t.Errorf("expected ErrInvalidSortField, got %v", err)
+		}
+	}
+}
+
+// TestListSortFields 测试 WithSortFields 覆盖 Scope 排序并在非法字段时直接返回错误
+func TestListSortFields(t *testing.T) {
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	mapping := SortMapping{"createdAt": "created_at"}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.SetScope(NewGormScope[GormTestEntity](nil, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}))
+
+	if _, err := list.Query(ctx, WithData(proxy), WithSortFields(mapping, ParseSortFields("-createdAt")...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var found bool
+	for _, sql := range recorder.all() {
+		if strings.HasPrefix(sql, "SELECT * ") {
+			found = strings.Contains(sql, "ORDER BY `created_at` DESC") && !strings.Contains(sql, "ORDER BY id")
+		}
+	}
+	if !found {
+		t.Errorf("expected whitelisted sort to replace scope sort, got %v", recorder.all())
+	}
+
+	_, err := list.Query(ctx, WithData(proxy), WithSortFields(mapping, ParseSortFields("password")...))
+	var sortErr *InvalidSortError
+	if !errors.As(err, &sortErr) || sortErr.Field != "password" {
+		t.Errorf("expected *InvalidSortError, got %v", err)
+	}
+
+	mongoList := NewList[TestEntity]()
+	mongoList.SetDataSource(MongoDB)
+	mongoList.SetScope(NewMongoScope[TestEntity](bson.D{}, nil))
+	if _, err := mongoList.Explain(ctx, WithData(NewDBProxy(nil, &mongo.Collection{}, nil)),
+		WithSortFields(mapping, SortField{Field: "id"})); !errors.Is(err, ErrInvalidSortField) {
+		t.Errorf("expected ErrInvalidSortField from mongo list, got %v", err)
+	}
+}
+
+// TestSortMapping_SnakeCase 测试映射值为空的字段自动转换为 snake_case
+func TestSortMapping_SnakeCase(t *testing.T) {
+	mapping := SortMapping{
+		"createdAt":  "",
+		"ID":         "",
+		"userID":     "",
+		"HTTPStatus": "",
+		"score_v2":   "",
+		"name":       "display_name",
+	}.SnakeCase()
+	expected := SortMapping{
+		"createdAt":  "created_at",
+		"ID":         "id",
+		"userID":     "user_id",
+		"HTTPStatus": "http_status",
+		"score_v2":   "score_v2",
+		"name":       "display_name",
+	}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("expected %v, got %v", expected, mapping)
+	}
+
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(proxy), WithAutoSnakeCase(),
+		WithSortFields(SortMapping{"createdAt": ""}, ParseSortFields("-createdAt")...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var found bool
+	for _, sql := range recorder.all() {
+		found = found || strings.Contains(sql, "ORDER BY `created_at` DESC")
+	}
+	if !found {
+		t.Errorf("expected snake_case ORDER BY, got %v", recorder.all())
+	}
+}
+
+// TestSortMapping_WithAliases 测试计算列别名可加入排序白名单，非法标识符被忽略
+func TestSortMapping_WithAliases(t *testing.T) {
+	aliases := selectAliases([]string{"name", "COUNT(*) AS cnt", "SUM(amount) as `total`", "price"})
+	if !reflect.DeepEqual(aliases, []string{"cnt", "total"}) {
+		t.Errorf("expected [cnt total], got %v", aliases)
+	}
+
+	base := SortMapping{"name": "display_name"}
+	mapping := base.WithAliases("cnt", "name", "cnt; DROP TABLE users")
+	expected := SortMapping{"name": "display_name", "cnt": "cnt"}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("expected %v, got %v", expected, mapping)
+	}
+	if len(base) != 1 {
+		t.Errorf("expected original mapping unchanged, got %v", base)
+	}
+
+	proxy, recorder := newDryRunGormProxy(t)
+	ctx := context.Background()
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(ctx, WithData(proxy), WithFields("name", "COUNT(*) AS cnt"),
+		WithSortFields(SortMapping{"name": ""}, SortField{Field: "cnt", Desc: true})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := list.Query(ctx, WithData(proxy), WithSortAliases("score"),
+		WithSortFields(SortMapping{}, SortField{Field: "score"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var aliasSort, declaredSort bool
+	for _, sql := range recorder.all() {
+		aliasSort = aliasSort || strings.Contains(sql, "ORDER BY `cnt` DESC")
+		declaredSort = declaredSort || strings.Contains(sql, "ORDER BY `score`")
+	}
+	if !aliasSort || !declaredSort {
+		t.Errorf("expected alias ORDER BY, got %v", recorder.all())
+	}
+
+	_, err := list.Query(ctx, WithData(proxy), WithFields("name", "COUNT(*) AS cnt"),
+		WithSortFields(SortMapping{"name": ""}, SortField{Field: "password"}))
+	if !errors.Is(err, ErrInvalidSortField) {
+		t.Errorf("expected ErrInvalidSortField, got %v", err)
+	}
+}
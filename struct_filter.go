@@ -0,0 +1,346 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// structFilterTag 结构体过滤条件使用的标签名
+const structFilterTag = "query"
+
+// ErrInvalidFilterStruct FilterFromStruct 的入参不是结构体或结构体指针，或标签配置非法
+var ErrInvalidFilterStruct = errors.New("invalid filter struct")
+
+// ErrInvalidCondition 结构化过滤条件的字段为空、运算符未知，或 in 运算符的值不是切片或数组
+var ErrInvalidCondition = errors.New("invalid filter condition")
+
+// FilterOp 结构化过滤条件的比较运算符
+type FilterOp string
+
+const (
+	OpEq    FilterOp = "eq"    // 等于
+	OpNe    FilterOp = "ne"    // 不等于
+	OpGt    FilterOp = "gt"    // 大于
+	OpGte   FilterOp = "gte"   // 大于等于
+	OpLt    FilterOp = "lt"    // 小于
+	OpLte   FilterOp = "lte"   // 小于等于
+	OpLike  FilterOp = "like"  // 包含匹配（搜索词中的通配符会被转义）
+	OpILike FilterOp = "ilike" // 不区分大小写的包含匹配，不受列排序规则影响
+	OpIn    FilterOp = "in"    // 属于集合（值需为切片或数组）
+)
+
+// Condition 单个结构化过滤条件
+type Condition struct {
+	Field string   // 数据源字段名（列名 / 文档字段）
+	Op    FilterOp // 比较运算符
+	Value any      // 比较值
+}
+
+// ZeroValueMode 结构体过滤中非指针零值字段的处理方式
+type ZeroValueMode int
+
+const (
+	// SkipZero 跳过零值字段（默认），如 Age: 0 视为"不过滤"
+	SkipZero ZeroValueMode = iota
+	// IncludeZero 零值字段同样参与过滤，如 Age: 0 生成 age = 0
+	IncludeZero
+)
+
+// structFilterConfig FilterFromStruct 的可选配置
+type structFilterConfig struct {
+	zeroValueMode ZeroValueMode
+	transforms    map[string]func(any) any // 按数据源字段名注册的值转换函数
+}
+
+// StructFilterOption FilterFromStruct 的可选配置函数
+type StructFilterOption func(*structFilterConfig)
+
+// WithZeroValueMode 设置非指针零值字段的处理方式
+// 无论何种模式，nil 指针始终跳过、非 nil 指针始终参与过滤，空切片始终跳过；
+// 需要按字段区分"未传"与"零值"时，推荐使用指针字段（*int 为 nil 表示不过滤，指向 0 表示过滤 0）
+func WithZeroValueMode(mode ZeroValueMode) StructFilterOption {
+	return func(c *structFilterConfig) {
+		c.zeroValueMode = mode
+	}
+}
+
+// WithFieldTransform 为指定字段注册值转换函数，在生成条件前作用于比较值（op 为 in 时逐个作用于集合元素）
+// 适用于确定性加密等可搜索加密方案：例如传入加密函数，使 ssn = ? 的参数为密文；
+// 条件只生成一次，数据查询与总数统计使用同一份转换后的值。field 为数据源字段名（标签中的 name 或转换后的列名）
+func WithFieldTransform(field string, transform func(any) any) StructFilterOption {
+	return func(c *structFilterConfig) {
+		if c.transforms == nil {
+			c.transforms = make(map[string]func(any) any)
+		}
+		c.transforms[field] = transform
+	}
+}
+
+// StructFilter 由 FilterFromStruct 生成的结构化过滤条件集合
+// 同一组条件可编译为 GORM、MongoDB、ElasticSearch 三种数据源的过滤条件，条件之间为 AND 关系
+type StructFilter struct {
+	conditions []Condition
+}
+
+// FilterFromStruct 通过反射读取结构体字段上的 query 标签生成过滤条件
+// 标签格式为 `query:"name,op"`，op 缺省为 eq；name 缺省时按 GORM 命名策略转换字段名（如 UserName → user_name）；
+// `query:"-"` 与未打标签的字段会被忽略。默认跳过零值字段（可通过 WithZeroValueMode 调整），
+// nil 指针始终跳过，非 nil 指针按其指向的值参与过滤
+// 参数:
+//
+//	v: 结构体或结构体指针
+//	opts: 可选配置，如 WithZeroValueMode
+func FilterFromStruct(v any, opts ...StructFilterOption) (*StructFilter, error) {
+	var config structFilterConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return &StructFilter{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected struct, got %T", ErrInvalidFilterStruct, v)
+	}
+
+	naming := schema.NamingStrategy{}
+	rt := rv.Type()
+	filter := &StructFilter{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup(structFilterTag)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name, opName, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = naming.ColumnName("", sf.Name)
+		}
+		op := FilterOp(opName)
+		if op == "" {
+			op = OpEq
+		}
+		if !op.valid() {
+			return nil, fmt.Errorf("%w: field %s has unknown op %q", ErrInvalidFilterStruct, sf.Name, opName)
+		}
+
+		value, skip := resolveFilterValue(rv.Field(i), config.zeroValueMode)
+		if skip {
+			continue
+		}
+		if op == OpIn {
+			kind := reflect.ValueOf(value).Kind()
+			if kind != reflect.Slice && kind != reflect.Array {
+				return nil, fmt.Errorf("%w: field %s with op in must be a slice or array", ErrInvalidFilterStruct, sf.Name)
+			}
+		}
+		if transform := config.transforms[name]; transform != nil {
+			value = transformFilterValue(op, value, transform)
+		}
+		filter.conditions = append(filter.conditions, Condition{Field: name, Op: op, Value: value})
+	}
+	return filter, nil
+}
+
+// NewConditionFilter 由条件列表直接创建结构化过滤条件，适用于不经过结构体标签、在调用处逐个拼装条件的场景
+// 条件非法时返回 ErrInvalidCondition
+func NewConditionFilter(conditions ...Condition) (*StructFilter, error) {
+	for _, c := range conditions {
+		if c.Field == "" || !c.Op.valid() {
+			return nil, fmt.Errorf("%w: field %q, op %q", ErrInvalidCondition, c.Field, c.Op)
+		}
+		if c.Op == OpIn {
+			kind := reflect.ValueOf(c.Value).Kind()
+			if kind != reflect.Slice && kind != reflect.Array {
+				return nil, fmt.Errorf("%w: field %s with op in must be a slice or array", ErrInvalidCondition, c.Field)
+			}
+		}
+	}
+	return &StructFilter{conditions: append([]Condition(nil), conditions...)}, nil
+}
+
+// resolveFilterValue 解析字段值，返回是否跳过该字段
+func resolveFilterValue(field reflect.Value, mode ZeroValueMode) (any, bool) {
+	switch field.Kind() {
+	case reflect.Pointer:
+		if field.IsNil() {
+			return nil, true
+		}
+		return field.Elem().Interface(), false
+	case reflect.Slice, reflect.Map:
+		// 空集合无法构成有意义的条件（如 IN ()），始终跳过
+		if field.Len() == 0 {
+			return nil, true
+		}
+	default:
+		if mode == SkipZero && field.IsZero() {
+			return nil, true
+		}
+	}
+	return field.Interface(), false
+}
+
+// transformFilterValue 对比较值应用转换函数，in 运算符逐个转换集合元素
+func transformFilterValue(op FilterOp, value any, transform func(any) any) any {
+	if op != OpIn {
+		return transform(value)
+	}
+	values := sliceValues(value)
+	for i, v := range values {
+		values[i] = transform(v)
+	}
+	return values
+}
+
+// valid 判断运算符是否受支持
+func (op FilterOp) valid() bool {
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpLike, OpILike, OpIn:
+		return true
+	}
+	return false
+}
+
+// Conditions 返回条件列表的副本
+func (f *StructFilter) Conditions() []Condition {
+	return append([]Condition(nil), f.conditions...)
+}
+
+// Unsatisfiable 判断条件集合是否必然不匹配任何记录：条件之间为 AND 关系，任一 in 条件的集合为空即无法满足
+// FilterFromStruct 会跳过空集合字段，因此仅 NewConditionFilter / WithCondition 传入的空集合会触发
+func (f *StructFilter) Unsatisfiable() bool {
+	for _, c := range f.conditions {
+		if c.Op == OpIn && reflect.ValueOf(c.Value).Len() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Gorm 编译为 GORM 过滤条件，列名会被转义，like 使用 GormContains 的方言转义规则，ilike 使用 GormContainsFold
+func (f *StructFilter) Gorm() GormScope {
+	conditions := f.Conditions()
+	return func(db *gorm.DB) *gorm.DB {
+		for _, c := range conditions {
+			col := clause.Column{Name: c.Field}
+			switch c.Op {
+			case OpEq:
+				db = db.Where(clause.Eq{Column: col, Value: c.Value})
+			case OpNe:
+				db = db.Where(clause.Neq{Column: col, Value: c.Value})
+			case OpGt:
+				db = db.Where(clause.Gt{Column: col, Value: c.Value})
+			case OpGte:
+				db = db.Where(clause.Gte{Column: col, Value: c.Value})
+			case OpLt:
+				db = db.Where(clause.Lt{Column: col, Value: c.Value})
+			case OpLte:
+				db = db.Where(clause.Lte{Column: col, Value: c.Value})
+			case OpIn:
+				db = db.Where(clause.IN{Column: col, Values: sliceValues(c.Value)})
+			case OpLike:
+				db = GormContains(c.Field, fmt.Sprint(c.Value))(db)
+			case OpILike:
+				db = GormContainsFold(c.Field, fmt.Sprint(c.Value))(db)
+			}
+		}
+		return db
+	}
+}
+
+// mongoFilterOps 结构化运算符到 MongoDB 查询运算符的映射
+var mongoFilterOps = map[FilterOp]string{
+	OpEq:  "$eq",
+	OpNe:  "$ne",
+	OpGt:  "$gt",
+	OpGte: "$gte",
+	OpLt:  "$lt",
+	OpLte: "$lte",
+	OpIn:  "$in",
+}
+
+// Mongo 编译为 MongoDB 过滤条件，同一字段的多个条件合并到同一个子文档中（如 {age: {$gte: 18, $lte: 30}}）
+func (f *StructFilter) Mongo() MongoFilter {
+	filter := MongoFilter{}
+	index := make(map[string]int)
+	for _, c := range f.conditions {
+		var expr bson.E
+		switch c.Op {
+		case OpLike:
+			expr = bson.E{Key: "$regex", Value: regexp.QuoteMeta(fmt.Sprint(c.Value))}
+		case OpILike:
+			expr = bson.E{Key: "$regex", Value: bson.Regex{Pattern: regexp.QuoteMeta(fmt.Sprint(c.Value)), Options: "i"}}
+		default:
+			value := c.Value
+			if c.Op == OpIn {
+				value = sliceValues(c.Value)
+			}
+			expr = bson.E{Key: mongoFilterOps[c.Op], Value: value}
+		}
+
+		if i, ok := index[c.Field]; ok {
+			filter[i].Value = append(filter[i].Value.(bson.D), expr)
+			continue
+		}
+		index[c.Field] = len(filter)
+		filter = append(filter, bson.E{Key: c.Field, Value: bson.D{expr}})
+	}
+	return filter
+}
+
+// ElasticSearch 编译为 ElasticSearch bool 查询，所有条件置于 filter 子句（不参与评分）
+func (f *StructFilter) ElasticSearch() elastic.Query {
+	query := elastic.NewBoolQuery()
+	for _, c := range f.conditions {
+		switch c.Op {
+		case OpEq:
+			query = query.Filter(elastic.NewTermQuery(c.Field, c.Value))
+		case OpNe:
+			query = query.MustNot(elastic.NewTermQuery(c.Field, c.Value))
+		case OpGt:
+			query = query.Filter(elastic.NewRangeQuery(c.Field).Gt(c.Value))
+		case OpGte:
+			query = query.Filter(elastic.NewRangeQuery(c.Field).Gte(c.Value))
+		case OpLt:
+			query = query.Filter(elastic.NewRangeQuery(c.Field).Lt(c.Value))
+		case OpLte:
+			query = query.Filter(elastic.NewRangeQuery(c.Field).Lte(c.Value))
+		case OpIn:
+			query = query.Filter(elastic.NewTermsQuery(c.Field, sliceValues(c.Value)...))
+		case OpLike:
+			query = query.Filter(elastic.NewWildcardQuery(c.Field, "*"+escapeWildcard(fmt.Sprint(c.Value))+"*"))
+		case OpILike:
+			query = query.Filter(elastic.NewWildcardQuery(c.Field, "*"+escapeWildcard(fmt.Sprint(c.Value))+"*").CaseInsensitive(true))
+		}
+	}
+	return query
+}
+
+// sliceValues 将切片或数组展开为 []any
+func sliceValues(v any) []any {
+	rv := reflect.ValueOf(v)
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
+
+// escapeWildcard 转义 ElasticSearch wildcard 查询中的特殊字符
+func escapeWildcard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(s)
+}
@@ -0,0 +1,322 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type userSearchFilter struct {
+	Name     string   `query:"name,like"`
+	MinAge   int      `query:"age,gte"`
+	MaxAge   int      `query:"age,lte"`
+	Status   *int     `query:"status"`
+	IDs      []uint32 `query:"id,in"`
+	UserName string   `query:",ne"`
+	Internal string   `query:"-"`
+	Ignored  string
+}
+
+// TestFilterFromStruct_Conditions 测试标签解析、零值跳过与指针处理
+func TestFilterFromStruct_Conditions(t *testing.T) {
+	zero := 0
+	filter, err := FilterFromStruct(&userSearchFilter{
+		Name:     "50%",
+		MinAge:   18,
+		Status:   &zero,
+		UserName: "root",
+		Internal: "x",
+		Ignored:  "y",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Condition{
+		{Field: "name", Op: OpLike, Value: "50%"},
+		{Field: "age", Op: OpGte, Value: 18},
+		{Field: "status", Op: OpEq, Value: 0},
+		{Field: "user_name", Op: OpNe, Value: "root"},
+	}
+	if got := filter.Conditions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+// TestFilterFromStruct_Errors 测试非法入参与标签
+func TestFilterFromStruct_Errors(t *testing.T) {
+	if _, err := FilterFromStruct("name"); !errors.Is(err, ErrInvalidFilterStruct) {
+		t.Errorf("expected ErrInvalidFilterStruct for non-struct, got %v", err)
+	}
+
+	type badOp struct {
+		Name string `query:"name,regex"`
+	}
+	if _, err := FilterFromStruct(badOp{Name: "a"}); !errors.Is(err, ErrInvalidFilterStruct) {
+		t.Errorf("expected ErrInvalidFilterStruct for unknown op, got %v", err)
+	}
+
+	type badIn struct {
+		ID int `query:"id,in"`
+	}
+	if _, err := FilterFromStruct(badIn{ID: 1}); !errors.Is(err, ErrInvalidFilterStruct) {
+		t.Errorf("expected ErrInvalidFilterStruct for non-slice in, got %v", err)
+	}
+
+	filter, err := FilterFromStruct((*userSearchFilter)(nil))
+	if err != nil || len(filter.Conditions()) != 0 {
+		t.Errorf("expected empty filter for nil pointer, got %v, %v", filter, err)
+	}
+}
+
+// TestStructFilter_Gorm 测试编译为 GORM 条件
+func TestStructFilter_Gorm(t *testing.T) {
+	filter, err := FilterFromStruct(userSearchFilter{Name: "a_b", MinAge: 18, MaxAge: 30, IDs: []uint32{1, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sql, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		b.AddFilter(filter.Gorm())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "WHERE `name` LIKE ? ESCAPE '!' AND `age` >= ? AND `age` <= ? AND `id` IN (?,?)"
+	if !strings.Contains(sql, expected) || !strings.Contains(sql, `args: [%a!_b%, 18, 30, 1, 2]`) {
+		t.Errorf("expected %q, got %q", expected, sql)
+	}
+}
+
+// TestStructFilter_Mongo 测试编译为 MongoDB 条件，同一字段的条件合并
+func TestStructFilter_Mongo(t *testing.T) {
+	filter, err := FilterFromStruct(userSearchFilter{Name: "a.b", MinAge: 18, MaxAge: 30, IDs: []uint32{1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := MongoFilter{
+		{Key: "name", Value: bson.D{{Key: "$regex", Value: `a\.b`}}},
+		{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}, {Key: "$lte", Value: 30}}},
+		{Key: "id", Value: bson.D{{Key: "$in", Value: []any{uint32(1)}}}},
+	}
+	if got := filter.Mongo(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// TestStructFilter_ElasticSearch 测试编译为 ElasticSearch bool 查询
+func TestStructFilter_ElasticSearch(t *testing.T) {
+	filter, err := FilterFromStruct(userSearchFilter{Name: "a*", MinAge: 18, UserName: "root"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source, err := filter.ElasticSearch().Source()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(source)
+	got := string(data)
+	for _, part := range []string{`"wildcard":{"name":{"value":"*a\\**"}}`, `"range":{"age":{"from":18`, `"must_not":{"term":{"user_name":"root"}}`} {
+		if !strings.Contains(got, part) {
+			t.Errorf("expected %s in %s", part, got)
+		}
+	}
+}
+
+// TestStructFilter_ILike 测试 ilike 在 MongoDB 与 ElasticSearch 中编译为不区分大小写的匹配
+func TestStructFilter_ILike(t *testing.T) {
+	filter, err := NewConditionFilter(Condition{Field: "name", Op: OpILike, Value: "A.b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := MongoFilter{
+		{Key: "name", Value: bson.D{{Key: "$regex", Value: bson.Regex{Pattern: `A\.b`, Options: "i"}}}},
+	}
+	if got := filter.Mongo(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	source, err := filter.ElasticSearch().Source()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(source)
+	if want := `"wildcard":{"name":{"case_insensitive":true,"value":"*A.b*"}}`; !strings.Contains(string(data), want) {
+		t.Errorf("expected %s in %s", want, data)
+	}
+}
+
+// TestNewStructFilterScope 测试通过 List.SetScope 应用结构化过滤条件
+func TestNewStructFilterScope(t *testing.T) {
+	filter, err := FilterFromStruct(userSearchFilter{MinAge: 18})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list := NewList[TestEntity]()
+	list.SetDataSource(MongoDB)
+	list.SetScope(NewStructFilterScope[TestEntity](filter))
+	explain, err := list.Explain(context.Background(), WithData(NewDBProxy(nil, &mongo.Collection{}, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(explain, "$gte") {
+		t.Errorf("expected $gte in explain output, got %s", explain)
+	}
+}
+
+// TestFilterFromStruct_ZeroValueMode 测试零值处理模式与指针字段语义
+func TestFilterFromStruct_ZeroValueMode(t *testing.T) {
+	type ageFilter struct {
+		Age    int      `query:"age"`
+		Score  *int     `query:"score"`
+		Levels []string `query:"level,in"`
+	}
+
+	// 默认 SkipZero：Age: 0 视为不过滤
+	filter, err := FilterFromStruct(ageFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filter.Conditions()) != 0 {
+		t.Errorf("expected no conditions in SkipZero mode, got %+v", filter.Conditions())
+	}
+
+	// IncludeZero：Age: 0 生成 age = 0；nil 指针与空切片仍然跳过
+	filter, err = FilterFromStruct(ageFilter{}, WithZeroValueMode(IncludeZero))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Condition{{Field: "age", Op: OpEq, Value: 0}}
+	if got := filter.Conditions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	// 指针指向零值时始终参与过滤
+	zero := 0
+	filter, err = FilterFromStruct(ageFilter{Score: &zero})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []Condition{{Field: "score", Op: OpEq, Value: 0}}
+	if got := filter.Conditions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+// TestFilterFromStruct_FieldTransform 测试字段值转换作用于比较值与 in 集合元素，且只作用于指定字段
+func TestFilterFromStruct_FieldTransform(t *testing.T) {
+	encrypt := func(v any) any {
+		return "enc(" + v.(string) + ")"
+	}
+	type secretFilter struct {
+		SSN    string   `query:"ssn"`
+		Phones []string `query:"phone,in"`
+		Name   string   `query:"name"`
+	}
+
+	filter, err := FilterFromStruct(secretFilter{SSN: "123", Phones: []string{"a", "b"}, Name: "alice"},
+		WithFieldTransform("ssn", encrypt), WithFieldTransform("phone", encrypt))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Condition{
+		{Field: "ssn", Op: OpEq, Value: "enc(123)"},
+		{Field: "phone", Op: OpIn, Value: []any{"enc(a)", "enc(b)"}},
+		{Field: "name", Op: OpEq, Value: "alice"},
+	}
+	if got := filter.Conditions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	sql, err := explainWithDialect(t, "mysql", func(b *GormBuilder[GormTestEntity]) {
+		b.SetFilter(filter.Gorm())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "enc(123)") || !strings.Contains(sql, "enc(a)") {
+		t.Errorf("expected transformed values in SQL args, got %s", sql)
+	}
+}
+
+// TestListQuery_OptionalConditions 测试可选过滤条件仅在指针非 nil 时生效，非法条件返回 ErrInvalidCondition
+func TestListQuery_OptionalConditions(t *testing.T) {
+	ctx := context.Background()
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	name := "alice"
+	var minAge *int
+	if _, err := list.Query(ctx, WithData(proxy), WithNeedTotal(false),
+		WithOptionalEq("name", &name),
+		WithOptional("age", OpGte, minAge),
+		WithCondition("status", OpIn, []int{1, 2}),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "SELECT * FROM `gorm_test_entities` WHERE `name` = ? AND `status` IN (?,?) LIMIT ?"
+	if sqls := recorder.all(); len(sqls) != 1 || sqls[0] != expected {
+		t.Errorf("expected %q, got %v", expected, sqls)
+	}
+
+	if _, err := list.Query(ctx, WithData(proxy), WithCondition("status", OpIn, 1)); !errors.Is(err, ErrInvalidCondition) {
+		t.Errorf("expected ErrInvalidCondition, got %v", err)
+	}
+}
+
+// TestListQuery_UnsatisfiableConditions 测试空集合 in 条件跳过数据查询与总数统计，直接返回空结果
+func TestListQuery_UnsatisfiableConditions(t *testing.T) {
+	filter, err := NewConditionFilter(Condition{Field: "status", Op: OpEq, Value: 1}, Condition{Field: "id", Op: OpIn, Value: []int{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !filter.Unsatisfiable() {
+		t.Error("expected empty in condition to be unsatisfiable")
+	}
+	if filter, _ := NewConditionFilter(Condition{Field: "id", Op: OpIn, Value: []int{1}}); filter.Unsatisfiable() {
+		t.Error("expected non-empty in condition to be satisfiable")
+	}
+
+	ctx := context.Background()
+	proxy, recorder := newDryRunGormProxy(t)
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+
+	var hooked bool
+	list.SetAfterQueryHook(func(ctx context.Context, result core.Result[GormTestEntity], err error) {
+		hooked = true
+	})
+	result, err := list.Query(ctx, WithData(proxy), WithNeedTotal(true), WithCondition("id", OpIn, []uint32{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Items == nil || len(result.Items) != 0 || result.Total != 0 || !result.HasTotal {
+		t.Errorf("expected empty counted result, got %+v", result)
+	}
+	if sqls := recorder.all(); len(sqls) != 0 {
+		t.Errorf("expected no statements, got %v", sqls)
+	}
+	if !hooked {
+		t.Error("expected hooks to run on short-circuited query")
+	}
+
+	page, err := list.QueryPage(ctx, WithData(proxy), WithCondition("id", OpIn, []uint32{}))
+	if err != nil || len(page.Items) != 0 || page.HasMore {
+		t.Errorf("expected empty page, got %+v, %v", page, err)
+	}
+	if sqls := recorder.all(); len(sqls) != 0 {
+		t.Errorf("expected no statements for cursor page, got %v", sqls)
+	}
+}
@@ -0,0 +1,122 @@
+package builder
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Timing 单次数据源访问的耗时记录
+type Timing struct {
+	DataSource DataSource    // 数据源类型
+	Mode       string        // 查询模式（list / cursor / pit_cursor）
+	Duration   time.Duration // 数据源访问耗时（不含中间件与钩子，缓存命中时不记录）
+	Err        error         // 查询错误
+}
+
+// Timings 并发安全的查询耗时累加器
+// 可通过 SetTimingSink / WithTimingSink 显式指定，或通过 ContextWithTimings 挂载到 ctx 上，
+// 由上层处理器在请求结束时汇总数据库总耗时，无需引入完整的链路追踪
+type Timings struct {
+	mu      sync.Mutex
+	entries []Timing
+}
+
+// Add 追加一条耗时记录
+func (t *Timings) Add(timing Timing) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, timing)
+}
+
+// Entries 返回全部耗时记录的副本
+func (t *Timings) Entries() []Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Timing(nil), t.entries...)
+}
+
+// Count 返回记录条数
+func (t *Timings) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// Total 返回全部记录的耗时总和
+func (t *Timings) Total() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var total time.Duration
+	for _, entry := range t.entries {
+		total += entry.Duration
+	}
+	return total
+}
+
+// DBCallHook 数据库调用钩子，在每次实际访问数据源（数据查询、总数统计等）结束后回调
+// op 为 DBCallFind 等操作名，elapsed 为该次调用的耗时；数据查询与总数统计可能并行执行，钩子需并发安全
+type DBCallHook func(op string, elapsed time.Duration)
+
+// DBCallHook 回调的操作名
+const (
+	DBCallFind      = "find"      // 数据查询（MongoDB 含游标取数与解码）
+	DBCallCount     = "count"     // 总数统计
+	DBCallAggregate = "aggregate" // 聚合查询（如 MongoDB $facet）
+	DBCallModify    = "modify"    // 查找并修改单条文档（MongoDB findAndModify）
+	DBCallOpenPIT   = "open_pit"  // 打开 ElasticSearch PIT
+	DBCallRejected  = "rejected"  // 查询被 SetRequireFilter 拒绝，未访问数据源，耗时为 0
+)
+
+// timingsCtxKey ctx 中 Timings 的键类型
+type timingsCtxKey struct{}
+
+// ContextWithTimings 返回携带耗时累加器的 ctx，未显式设置 TimingSink 的查询会记录到该累加器
+func ContextWithTimings(ctx context.Context, timings *Timings) context.Context {
+	return context.WithValue(ctx, timingsCtxKey{}, timings)
+}
+
+// TimingsFromContext 从 ctx 中取出耗时累加器，不存在时返回 nil
+func TimingsFromContext(ctx context.Context) *Timings {
+	timings, _ := ctx.Value(timingsCtxKey{}).(*Timings)
+	return timings
+}
+
+// timedQuery 包装最终查询函数，在配置了累加器时记录数据源访问耗时
+func timedQuery[R any, T any](mc *middlewareContext[R], queryFn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		sink := mc.timingSink
+		if sink == nil {
+			sink = TimingsFromContext(ctx)
+		}
+		if sink == nil {
+			return queryFn(ctx)
+		}
+
+		begin := time.Now()
+		result, err := queryFn(ctx)
+		sink.Add(Timing{
+			DataSource: mc.dataSource,
+			Mode:       mc.queryMode,
+			Duration:   time.Since(begin),
+			Err:        err,
+		})
+		return result, err
+	}
+}
+
+// boundedQuery 为数据源访问附加 SetTimeout 配置的超时
+// ctx 自身的截止时间更早时直接沿用 ctx，否则以超时时间派生子 ctx，从而始终以较早的截止时间为准
+func boundedQuery[R any, T any](mc *middlewareContext[R], queryFn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	if mc.timeout <= 0 {
+		return queryFn
+	}
+	return func(ctx context.Context) (T, error) {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= mc.timeout {
+			return queryFn(ctx)
+		}
+		ctx, cancel := context.WithTimeout(ctx, mc.timeout)
+		defer cancel()
+		return queryFn(ctx)
+	}
+}
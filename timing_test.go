@@ -0,0 +1,179 @@
+package builder
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fantasticbin/QueryBuilder/v2/core"
+	"gorm.io/gorm"
+)
+
+// TestTimings_ConcurrentAdd 测试累加器并发写入
+func TestTimings_ConcurrentAdd(t *testing.T) {
+	timings := &Timings{}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timings.Add(Timing{Duration: time.Millisecond})
+		}()
+	}
+	wg.Wait()
+
+	if timings.Count() != 50 {
+		t.Errorf("expected 50 entries, got %d", timings.Count())
+	}
+	if timings.Total() != 50*time.Millisecond {
+		t.Errorf("expected total 50ms, got %s", timings.Total())
+	}
+	if len(timings.Entries()) != 50 {
+		t.Errorf("expected 50 entries snapshot, got %d", len(timings.Entries()))
+	}
+}
+
+// TestGormBuilder_TimingSink 测试显式设置的累加器记录 QueryList 耗时
+func TestGormBuilder_TimingSink(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	timings := &Timings{}
+
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetTimingSink(timings)
+	if _, err := b.QueryList(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := timings.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 timing entry, got %d", len(entries))
+	}
+	if entries[0].DataSource != Gorm || entries[0].Mode != "list" || entries[0].Err != nil {
+		t.Errorf("unexpected timing entry: %+v", entries[0])
+	}
+}
+
+// TestGormBuilder_TimingFromContext 测试未显式设置时使用 ctx 挂载的累加器，并在多次查询间累加
+func TestGormBuilder_TimingFromContext(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	timings := &Timings{}
+	ctx := ContextWithTimings(context.Background(), timings)
+
+	if _, err := NewGormBuilder[GormTestEntity](proxy).QueryList(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := NewGormBuilder[GormTestEntity](proxy)
+	b.SetCursorField("id")
+	if _, err := b.QueryPage(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := timings.Entries()
+	if len(entries) != 2 || entries[0].Mode != "list" || entries[1].Mode != "cursor" {
+		t.Errorf("expected list and cursor entries, got %+v", entries)
+	}
+	if TimingsFromContext(context.Background()) != nil {
+		t.Error("expected nil timings from empty context")
+	}
+}
+
+// TestTimingSink_SkippedOnShortCircuit 测试中间件短路（如缓存命中）时不记录数据源耗时
+func TestTimingSink_SkippedOnShortCircuit(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	timings := &Timings{}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	list.Use(func(ctx context.Context, builder Querier[GormTestEntity], next func(context.Context) (core.Result[GormTestEntity], error)) (core.Result[GormTestEntity], error) {
+		return &core.ListResult[GormTestEntity]{}, nil
+	})
+	if _, err := list.Query(context.Background(), WithData(proxy), WithTimingSink(timings)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timings.Count() != 0 {
+		t.Errorf("expected no timing entries, got %d", timings.Count())
+	}
+}
+
+// TestListQuery_WithTimingSinkOption 测试 List 通过 WithTimingSink 配置累加器
+func TestListQuery_WithTimingSinkOption(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	timings := &Timings{}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(proxy), WithTimingSink(timings)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timings.Count() != 1 {
+		t.Errorf("expected 1 timing entry, got %d", timings.Count())
+	}
+}
+
+// TestGormBuilder_TimeoutUsesEarlierDeadline 测试查询超时与 ctx 截止时间取较早者
+func TestGormBuilder_TimeoutUsesEarlierDeadline(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	var deadline time.Time
+	var hasDeadline bool
+	if err := proxy.DB.Callback().Query().Before("gorm:query").Register("test:deadline", func(db *gorm.DB) {
+		deadline, hasDeadline = db.Statement.Context.Deadline()
+	}); err != nil {
+		t.Fatalf("register callback failed: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		ctxLimit time.Duration
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{name: "timeout only", timeout: time.Minute, expected: time.Minute},
+		{name: "ctx earlier", ctxLimit: time.Second, timeout: time.Hour, expected: time.Second},
+		{name: "timeout earlier", ctxLimit: time.Hour, timeout: time.Second, expected: time.Second},
+		{name: "ctx only", ctxLimit: time.Minute, expected: time.Minute},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.ctxLimit > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.ctxLimit)
+				defer cancel()
+			}
+			b := NewGormBuilder[GormTestEntity](proxy)
+			b.SetTimeout(tc.timeout)
+			if _, err := b.QueryList(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !hasDeadline {
+				t.Fatal("expected query ctx to have a deadline")
+			}
+			if remaining := time.Until(deadline); remaining > tc.expected || remaining < tc.expected-5*time.Second {
+				t.Errorf("expected deadline about %s away, got %s", tc.expected, remaining)
+			}
+		})
+	}
+}
+
+// TestListQuery_DBCallHook 测试数据库调用钩子分别回调数据查询与总数统计
+func TestListQuery_DBCallHook(t *testing.T) {
+	proxy, _ := newDryRunGormProxy(t)
+	var mu sync.Mutex
+	ops := map[string]int{}
+	hook := func(op string, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		ops[op]++
+	}
+
+	list := NewList[GormTestEntity]()
+	list.SetDataSource(Gorm)
+	if _, err := list.Query(context.Background(), WithData(proxy), WithNeedTotal(true), WithDBCallHook(hook)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ops) != 2 || ops[DBCallFind] != 1 || ops[DBCallCount] != 1 {
+		t.Errorf("expected one find and one count call, got %v", ops)
+	}
+}
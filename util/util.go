@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"runtime/debug"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -14,7 +15,7 @@ func WaitAndGo(fn ...func() error) error {
 		g.Go(func() (err error) {
 			defer func() {
 				if r := recover(); r != nil {
-					err = fmt.Errorf("panic recovered: %+v\n%s", r, string(debug.Stack()))
+					err = recoveredPanic(r)
 				}
 			}()
 			return f()
@@ -22,3 +23,81 @@ func WaitAndGo(fn ...func() error) error {
 	}
 	return g.Wait()
 }
+
+// WaitAndGoN 以最多 limit 的并发度执行 n 次 fn（参数为执行序号），等待全部完成并返回首个错误
+// limit <= 0 时不限制并发度
+func WaitAndGoN(n, limit int, fn func(i int) error) error {
+	var g errgroup.Group
+	if limit > 0 {
+		g.SetLimit(limit)
+	}
+	for i := range n {
+		g.Go(func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = recoveredPanic(r)
+				}
+			}()
+			return fn(i)
+		})
+	}
+	return g.Wait()
+}
+
+// recoveredPanic 将 recover 得到的值连同堆栈转换为 error
+// panic 值本身为 error 时以 %w 包装，保留错误链供调用方 errors.Is/As 判断
+func recoveredPanic(r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("panic recovered: %w\n%s", err, debug.Stack())
+	}
+	return fmt.Errorf("panic recovered: %+v\n%s", r, debug.Stack())
+}
+
+// EscapeLike 转义 LIKE 模式中的通配符 %、_ 以及转义字符本身
+// 用户输入的搜索词需先转义再拼接通配符，否则 "%" 会匹配全部记录
+func EscapeLike(s string, escapeChar byte) string {
+	return escapeLike(s, escapeChar, "")
+}
+
+// EscapeLikeWith 在 EscapeLike 的基础上额外转义 extra 中的字符
+// 例如 SQL Server 的 LIKE 将 "[" 视为字符集起始符，需要一并转义
+func EscapeLikeWith(s string, escapeChar byte, extra string) string {
+	return escapeLike(s, escapeChar, extra)
+}
+
+func escapeLike(s string, escapeChar byte, extra string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' || c == '_' || c == escapeChar || strings.IndexByte(extra, c) >= 0 {
+			b.WriteByte(escapeChar)
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// ToSnakeCase 将 camelCase / PascalCase 名称转换为 snake_case
+// 连续大写的缩写词视为一个单词，例如 "ID" → "id"、"userID" → "user_id"、"HTTPServer" → "http_server"
+func ToSnakeCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isUpper(c) {
+			b.WriteByte(c)
+			continue
+		}
+		// 单词边界：前一个字符为小写或数字，或处于缩写词末尾（下一个字符为小写）
+		if i > 0 && s[i-1] != '_' &&
+			(!isUpper(s[i-1]) || (i+1 < len(s) && isLower(s[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteByte(c + 'a' - 'A')
+	}
+	return b.String()
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }